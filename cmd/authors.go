@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+// authorsCmd represents the authors command
+var authorsCmd = &cobra.Command{
+	Use:   "authors",
+	Short: "Ranks the authors by how much of the ecosystem depends on them",
+	Long: `Ranks the authors by the number of package versions that depend, directly or transitively, on one of the
+versions they authored. With --critical it lists the packages that have a single author and dependents instead: the
+packages whose whole downstream reach rests on one maintainer. The authors come from the author column of CSV input
+or the author field of JSON input, and are kept in snapshots, so the command also works on --stream input and on a
+--snapshot.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		if lg.granularity == g.PackageGranularity {
+			return errors.New("authors are listed per version, authors needs --granularity version")
+		}
+		named := false
+		for _, node := range lg.idToNodeInfo {
+			if len(g.SplitAuthors(node.Author)) > 0 {
+				named = true
+				break
+			}
+		}
+		if !named {
+			cmd.PrintErrln("The input doesn't name the authors of any version")
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		if critical, _ := cmd.Flags().GetBool("critical"); critical {
+			return writeRecords(cmd, export.CriticalPackageRecords(*g.GetCriticalPackages(lg.graph, lg.idToNodeInfo, limit)))
+		}
+		return writeRecords(cmd, export.AuthorRankRecords(*g.GetAuthorRanking(lg.graph, lg.idToNodeInfo, limit)))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(authorsCmd)
+	addGraphFlags(authorsCmd)
+	addOutputFlag(authorsCmd)
+	authorsCmd.Flags().IntP("limit", "n", 10, "Number of authors or packages to show, 0 shows all of them")
+	authorsCmd.Flags().Bool("critical", false, "List the packages with a single author and dependents")
+}
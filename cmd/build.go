@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+// buildCmd represents the build command
+var buildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Builds the graph and reports its size",
+	Long: `Builds the graph from the input file and reports how many nodes and edges it has and how long it took.
+This is useful to check an input file before running queries on it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		start := time.Now()
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		duration := time.Since(start)
+
+		if dot, _ := cmd.Flags().GetString("dot"); dot != "" {
+			g.VisualizationNodeInfo(&lg.stringIDToNodeInfo, lg.graph, dot)
+			cmd.PrintErrf("Wrote the graph to %s.dot\n", dot)
+		}
+		if snapshot, _ := cmd.Flags().GetString("save-snapshot"); snapshot != "" {
+			if lg.granularity != g.VersionGranularity {
+				return errors.New("snapshots hold the version-level graph, --save-snapshot can't be combined with --granularity package")
+			}
+			if err := g.SaveSnapshot(snapshot, lg.graph, lg.packagesList, lg.idToNodeInfo, lg.ecosystem); err != nil {
+				return err
+			}
+			cmd.PrintErrf("Wrote a snapshot of the graph to %s\n", snapshot)
+		}
+		return writeRecords(cmd, []export.Record{{
+			{Name: "nodes", Value: lg.graph.Nodes().Len()},
+			{Name: "edges", Value: lg.graph.Edges().Len()},
+			{Name: "duration_ms", Value: duration.Milliseconds()},
+		}})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(buildCmd)
+	addGraphFlags(buildCmd)
+	addOutputFlag(buildCmd)
+	buildCmd.Flags().String("dot", "", "Also write the graph to <name>.dot so it can be visualized with GraphViz")
+	buildCmd.Flags().String("save-snapshot", "", "Also write a snapshot of the graph that the other commands can load with --snapshot")
+}
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/AJMBrands/SoftwareThatMatters/ingest"
+	"github.com/AJMBrands/SoftwareThatMatters/query"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// pickLimit is the number of packages pickNode lets the user choose from
+const pickLimit = 20
+
+// loadedGraph holds everything g.CreateGraph returns so the subcommands can pass it around as one value
+type loadedGraph struct {
+	graph              *simple.DirectedGraph
+	packagesList       *[]g.PackageInfo
+	stringIDToNodeInfo map[string]g.NodeInfo
+	idToNodeInfo       map[int64]g.NodeInfo
+	nameToVersions     map[string][]string
+	ecosystem          g.Ecosystem
+	granularity        g.Granularity
+	// index is the package search index, it is built the first time searchIndex is called
+	index *g.SearchIndex
+}
+
+// addGraphFlags adds the flags every non-interactive command needs to build the graph
+func addGraphFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("input", "i", "", "JSON, NDJSON or CSV file with the packages and their dependencies, optionally compressed (.gz, .zst), or the directory of a local Maven repository, Go module cache or crates.io index")
+	cmd.Flags().StringP("ecosystem", "e", "npm", fmt.Sprintf("Ecosystem the packages come from %v", g.Ecosystems))
+	cmd.Flags().String("snapshot", "", "Load the graph from a snapshot written by build --save-snapshot instead of --input")
+	cmd.Flags().String("granularity", string(g.VersionGranularity), "Run the command on a node per package version (version) or per package (package)")
+	cmd.Flags().Bool("stream", false, "Build the graph in two passes over --input without keeping the packages in memory, for inputs that don't fit otherwise. Works for JSON files and CSV files sorted by name")
+}
+
+// addIntervalFlags adds the --from and --to flags. Commands that use them only apply a time filter when both are set.
+func addIntervalFlags(cmd *cobra.Command) {
+	cmd.Flags().String("from", "", "Beginning of the time interval (YYYY-MM-DD or DD-MM-YYYY)")
+	cmd.Flags().String("to", "", "End of the time interval (YYYY-MM-DD or DD-MM-YYYY)")
+}
+
+// addOutputFlag adds the --output flag that selects the format in which the results are printed
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", string(export.Table), fmt.Sprintf("Output format %v", export.Formats))
+}
+
+// writeRecords prints the records to standard output in the format selected with --output
+func writeRecords(cmd *cobra.Command, records []export.Record) error {
+	name, _ := cmd.Flags().GetString("output")
+	format, err := export.ParseFormat(name)
+	if err != nil {
+		return err
+	}
+	return export.Write(cmd.OutOrStdout(), format, records)
+}
+
+// loadGraph builds the graph from the file and ecosystem given on the command line, in two passes over the file with
+// --stream. With --granularity package it is collapsed into the package-level graph.
+func loadGraph(cmd *cobra.Command) (*loadedGraph, error) {
+	input, _ := cmd.Flags().GetString("input")
+	name, _ := cmd.Flags().GetString("ecosystem")
+	ecosystem, err := g.ParseEcosystem(name)
+	if err != nil {
+		return nil, err
+	}
+	name, _ = cmd.Flags().GetString("granularity")
+	granularity, err := g.ParseGranularity(name)
+	if err != nil {
+		return nil, err
+	}
+	// Check the output format before the graph is built, building it can take a while
+	if output := cmd.Flags().Lookup("output"); output != nil {
+		if _, err := export.ParseFormat(output.Value.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	var lg *loadedGraph
+	if snapshot, _ := cmd.Flags().GetString("snapshot"); snapshot != "" {
+		graph, packagesList, stringIDToNodeInfo, idToNodeInfo, nameToVersions, ecosystem, err := g.LoadSnapshot(snapshot)
+		if err != nil {
+			return nil, fmt.Errorf("could not load snapshot %s: %w", snapshot, err)
+		}
+		lg = &loadedGraph{
+			graph:              graph,
+			packagesList:       packagesList,
+			stringIDToNodeInfo: stringIDToNodeInfo,
+			idToNodeInfo:       idToNodeInfo,
+			nameToVersions:     nameToVersions,
+			ecosystem:          ecosystem,
+			granularity:        g.VersionGranularity,
+		}
+	} else if input == "" {
+		return nil, errors.New("either --input or --snapshot has to be set")
+	} else if stream, _ := cmd.Flags().GetBool("stream"); stream {
+		if lg, err = streamGraph(cmd, input, ecosystem); err != nil {
+			return nil, err
+		}
+	} else {
+		packages, skipped, err := ingest.LoadPackages(input)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", input, err)
+		}
+		printSkipped(cmd.ErrOrStderr(), input, skipped)
+		graph, packagesList, stringIDToNodeInfo, idToNodeInfo, nameToVersions := g.CreateGraphFromPackages(packages, ecosystem)
+		lg = &loadedGraph{
+			ecosystem:          ecosystem,
+			granularity:        g.VersionGranularity,
+			graph:              graph,
+			packagesList:       packagesList,
+			stringIDToNodeInfo: stringIDToNodeInfo,
+			idToNodeInfo:       idToNodeInfo,
+			nameToVersions:     nameToVersions,
+		}
+	}
+
+	if granularity == g.PackageGranularity {
+		lg = lg.collapse()
+	}
+	return lg, nil
+}
+
+// printSkipped reports the versions of an input directory that could not be read, ordered by string id
+func printSkipped(w io.Writer, input string, skipped map[string]string) {
+	if len(skipped) == 0 {
+		return
+	}
+	stringIDs := make([]string, 0, len(skipped))
+	for stringID := range skipped {
+		stringIDs = append(stringIDs, stringID)
+	}
+	sort.Strings(stringIDs)
+	fmt.Fprintf(w, "Skipped %d versions of %s that could not be read:\n", len(skipped), input)
+	for _, stringID := range stringIDs {
+		fmt.Fprintf(w, "  %s: %s\n", stringID, skipped[stringID])
+	}
+}
+
+// streamGraph builds the graph with g.CreateGraphStreaming and reports the progress of both passes on standard error.
+// The packages list stays empty.
+func streamGraph(cmd *cobra.Command, input string, ecosystem g.Ecosystem) (*loadedGraph, error) {
+	start := time.Now()
+	read := func(handle func(g.PackageInfo) error) error {
+		return ingest.StreamPackages(input, handle)
+	}
+	graph, stringIDToNodeInfo, idToNodeInfo, nameToVersions, err := g.CreateGraphStreaming(read, ecosystem, func(progress g.StreamProgress) {
+		step := "nodes"
+		if progress.Pass == 2 {
+			step = "edges"
+		}
+		state := "read"
+		if progress.Done {
+			state = "finished"
+		}
+		cmd.PrintErrf("Pass %d of 2 (%s): %s %d packages with %d versions in %s\n", progress.Pass, step, state, progress.Packages, progress.Versions, time.Since(start).Round(time.Second))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", input, err)
+	}
+	return &loadedGraph{
+		ecosystem:          ecosystem,
+		granularity:        g.VersionGranularity,
+		graph:              graph,
+		packagesList:       &[]g.PackageInfo{},
+		stringIDToNodeInfo: stringIDToNodeInfo,
+		idToNodeInfo:       idToNodeInfo,
+		nameToVersions:     nameToVersions,
+	}, nil
+}
+
+// requirePackages returns an error when the graph was built without keeping its packages, by --stream or from a
+// snapshot of such a graph. The command needs their constraints or other details.
+func (lg *loadedGraph) requirePackages(command string) error {
+	if len(*lg.packagesList) == 0 && len(lg.idToNodeInfo) > 0 {
+		return fmt.Errorf("%s needs the packages the graph was built from, which --stream doesn't keep", command)
+	}
+	return nil
+}
+
+// searchIndex returns the package search index of the graph
+func (lg *loadedGraph) searchIndex() *g.SearchIndex {
+	if lg.index == nil {
+		lg.index = g.NewSearchIndex(lg.stringIDToNodeInfo, lg.nameToVersions)
+	}
+	return lg.index
+}
+
+// collapse returns the package-level graph of a version-level graph. The packages list is kept, it still holds the
+// versions the packages were collapsed from.
+func (lg *loadedGraph) collapse() *loadedGraph {
+	graph, stringIDToNodeInfo, idToNodeInfo, nameToVersions := g.CollapseToPackages(lg.graph, lg.idToNodeInfo)
+	return &loadedGraph{
+		graph:              graph,
+		packagesList:       lg.packagesList,
+		stringIDToNodeInfo: stringIDToNodeInfo,
+		idToNodeInfo:       idToNodeInfo,
+		nameToVersions:     nameToVersions,
+		ecosystem:          lg.ecosystem,
+		granularity:        g.PackageGranularity,
+	}
+}
+
+// getInterval reads the --from and --to flags. The boolean is false when no interval was given.
+func getInterval(cmd *cobra.Command) (time.Time, time.Time, bool, error) {
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+	if from == "" && to == "" {
+		return time.Time{}, time.Time{}, false, nil
+	}
+	if from == "" || to == "" {
+		return time.Time{}, time.Time{}, false, errors.New("both --from and --to have to be set")
+	}
+	beginTime, err := query.ParseDate(from)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+	endTime, err := query.ParseDate(to)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+	return beginTime, endTime, true, nil
+}
+
+// pickNode returns the string id of the node a command argument refers to. The argument is a string id
+// (name@version) or a package URL. When it is not a node of the graph and the command runs in a terminal, the user
+// picks one in two steps: first the package out of the ones that match the argument best, then its version.
+// Otherwise it returns an error with the closest package names, so the commands fail instead of printing an empty
+// result.
+func pickNode(lg *loadedGraph, argument string) (string, error) {
+	node, err := g.LookupNode(lg.stringIDToNodeInfo, lg.ecosystem, argument)
+	if err == nil {
+		return g.StringID(node.Name, node.Version), nil
+	}
+	index := lg.searchIndex()
+	// Look for the package name without the version the user might have typed
+	query := argument
+	if strings.HasPrefix(strings.ToLower(argument), "pkg:") {
+		key, parseErr := g.ParsePurl(argument)
+		if parseErr != nil || key.Ecosystem != lg.ecosystem {
+			return "", err
+		}
+		query = key.PackageName()
+	} else if name, _, ok := g.SplitStringID(argument); ok {
+		query = name
+	}
+	names := index.Search(query, pickLimit)
+	if len(names) == 0 {
+		return "", fmt.Errorf("package %s was not found in the graph", argument)
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("package %s was not found in the graph, similar packages are %s", argument, strings.Join(names, ", "))
+	}
+
+	name := names[0]
+	if len(names) > 1 || names[0] != query {
+		prompt := &survey.Select{
+			Message:  fmt.Sprintf("%s was not found, which package did you mean?", argument),
+			Options:  names,
+			PageSize: 15,
+		}
+		if err := survey.AskOne(prompt, &name); err != nil {
+			return "", err
+		}
+	}
+	nodes := index.Versions(name, g.BySemver)
+	options := make([]string, len(nodes))
+	// Newest first, that is the version that is asked for most of the time
+	for i, node := range nodes {
+		options[len(nodes)-1-i] = node.Version
+	}
+	version := options[0]
+	if len(options) > 1 {
+		prompt := &survey.Select{
+			Message:  fmt.Sprintf("Which version of %s?", name),
+			Options:  options,
+			PageSize: 15,
+		}
+		if err := survey.AskOne(prompt, &version); err != nil {
+			return "", err
+		}
+	}
+	return g.StringID(name, version), nil
+}
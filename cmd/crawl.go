@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/AJMBrands/SoftwareThatMatters/ingest"
+	"github.com/spf13/cobra"
+)
+
+// crawlCmd represents the crawl command
+var crawlCmd = &cobra.Command{
+	Use:   "crawl <name>...",
+	Short: "Downloads packages from a registry mirror",
+	Long: `Requests the packages from a registry over HTTP, and with --follow the packages they depend on, and writes
+them to a JSON file that the other commands can read with --input. npm registries (like Verdaccio), the PyPI JSON API
+(like devpi) and Maven repositories (like Nexus) are supported, Maven packages are named groupId:artifactId. Requests
+that fail because the registry is unavailable are retried. With --checkpoint the progress is saved regularly and when
+the crawl is interrupted, running the same command again continues where it stopped. The crawled packages are
+appended to a .packages.ndjson file next to the checkpoint.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("ecosystem")
+		ecosystem, err := g.ParseEcosystem(name)
+		if err != nil {
+			return err
+		}
+		options := ingest.CrawlOptions{Ecosystem: ecosystem}
+		options.Registry, _ = cmd.Flags().GetString("registry")
+		options.Follow, _ = cmd.Flags().GetBool("follow")
+		options.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+		options.Retries, _ = cmd.Flags().GetInt("retries")
+		options.Checkpoint, _ = cmd.Flags().GetString("checkpoint")
+		options.Progress = func(done, queued int) {
+			cmd.PrintErrf("\rCrawled %d packages, %d queued ", done, queued)
+		}
+		crawler, err := ingest.NewCrawler(options)
+		if err != nil {
+			return err
+		}
+
+		// Stop on Ctrl+C, the checkpoint is written before the crawl returns
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		start := time.Now()
+		result, err := crawler.Crawl(ctx, args)
+		cmd.PrintErrln()
+		if err != nil {
+			return err
+		}
+		for name, reason := range result.Failed {
+			cmd.PrintErrf("Could not crawl %s: %s\n", name, reason)
+		}
+		path, _ := cmd.Flags().GetString("packages")
+		if err := ingest.WritePackages(path, result.Packages); err != nil {
+			return err
+		}
+		cmd.PrintErrf("Wrote %d packages to %s in %v, %d were not found and %d failed\n", len(result.Packages), path, time.Since(start).Round(time.Millisecond), len(result.Missing), len(result.Failed))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(crawlCmd)
+	crawlCmd.Flags().String("registry", "", "Base URL of the registry")
+	crawlCmd.Flags().StringP("ecosystem", "e", "npm", "Ecosystem of the registry (npm, pypi or maven)")
+	crawlCmd.Flags().String("packages", "", "JSON file the packages are written to")
+	crawlCmd.Flags().Bool("follow", true, "Also crawl the dependencies of the packages")
+	crawlCmd.Flags().Int("concurrency", 4, "Number of packages that are requested at the same time")
+	crawlCmd.Flags().Int("retries", 3, "Number of times a failed request is repeated")
+	crawlCmd.Flags().String("checkpoint", "", "File the progress is saved to, an existing checkpoint is continued")
+	_ = crawlCmd.MarkFlagRequired("registry")
+	_ = crawlCmd.MarkFlagRequired("packages")
+}
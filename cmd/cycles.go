@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+// cyclesCmd represents the cycles command
+var cyclesCmd = &cobra.Command{
+	Use:   "cycles",
+	Short: "Finds cyclic dependencies",
+	Long: `Finds the strongly connected components of the graph: groups of packages that all depend on each other,
+directly or transitively. Every cycle shows its members, the number of dependencies between them and one of the
+shortest cycles through its first member. With --order the cycles are condensed into single nodes and all components
+are listed in topological order, dependencies before their dependents. Use --granularity package to find cycles between
+packages instead of versions.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		if order, _ := cmd.Flags().GetBool("order"); order {
+			condensation := g.Condense(lg.graph, lg.idToNodeInfo)
+			return writeRecords(cmd, export.ComponentRecords(condensation.TopologicalOrder()))
+		}
+
+		cycles := *g.GetCycles(lg.graph, lg.idToNodeInfo)
+		members := 0
+		for _, cycle := range cycles {
+			members += len(cycle.Nodes)
+		}
+		cmd.PrintErrf("%d cycles with %d of %d nodes\n", len(cycles), members, lg.graph.Nodes().Len())
+		return writeRecords(cmd, export.CycleRecords(cycles))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cyclesCmd)
+	addGraphFlags(cyclesCmd)
+	addOutputFlag(cyclesCmd)
+	cyclesCmd.Flags().Bool("order", false, "List all components of the condensed graph in topological order")
+}
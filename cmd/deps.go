@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+// depsCmd represents the deps command
+var depsCmd = &cobra.Command{
+	Use:   "deps <name@version|purl>",
+	Short: "Finds all the possible dependencies of a package",
+	Long: `Finds all the possible dependencies of a package, directly or transitively. When --from and --to are given,
+only the dependencies that could have been used in that time interval are returned. With --mvs only the build list
+of a Go module is returned: the versions minimal version selection picks from its requirements.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		stringID, err := pickNode(lg, args[0])
+		if err != nil {
+			return err
+		}
+		if mvs, _ := cmd.Flags().GetBool("mvs"); mvs {
+			if lg.granularity == g.PackageGranularity {
+				return errors.New("the build list holds versions, --mvs needs --granularity version")
+			}
+			if err := lg.requirePackages("--mvs"); err != nil {
+				return err
+			}
+			nodes := g.MinimalVersionSelection(lg.graph, lg.idToNodeInfo, lg.stringIDToNodeInfo, lg.packagesList, lg.nameToVersions, lg.ecosystem, lg.stringIDToNodeInfo[stringID])
+			return writeRecords(cmd, export.NodeRecords(nodes))
+		}
+		beginTime, endTime, filter, err := getInterval(cmd)
+		if err != nil {
+			return err
+		}
+		if filter {
+			g.FilterNode(lg.graph, lg.idToNodeInfo, lg.stringIDToNodeInfo, stringID, beginTime, endTime)
+		}
+
+		nodes := *g.GetTransitiveDependenciesNode(lg.graph, lg.idToNodeInfo, lg.stringIDToNodeInfo, stringID)
+		g.SortNodes(nodes)
+		return writeRecords(cmd, export.NodeRecords(nodes))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(depsCmd)
+	addGraphFlags(depsCmd)
+	addOutputFlag(depsCmd)
+	addIntervalFlags(depsCmd)
+	depsCmd.Flags().Bool("mvs", false, "List the build list minimal version selection picks for a Go module")
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/AJMBrands/SoftwareThatMatters/query"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <name@version|purl> <name@version|purl>",
+	Short: "Compares the resolved dependencies of two versions",
+	Long: `Resolves the transitive dependencies of two versions, usually an old and a new version of the same package,
+and lists the packages that were added, removed, upgraded or downgraded between them. Upgrades and downgrades are
+classified as major, minor or patch changes. Every version is resolved at the moment it was published, or at the date
+given with --at.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		if lg.granularity == g.PackageGranularity {
+			return errors.New("dependencies are resolved to versions, diff needs --granularity version")
+		}
+		from, err := pickNode(lg, args[0])
+		if err != nil {
+			return err
+		}
+		to, err := pickNode(lg, args[1])
+		if err != nil {
+			return err
+		}
+		var at time.Time
+		if value, _ := cmd.Flags().GetString("at"); value != "" {
+			if at, err = query.ParseDate(value); err != nil {
+				return err
+			}
+		}
+		changes, err := g.DiffDependencies(lg.graph, lg.idToNodeInfo, lg.stringIDToNodeInfo, lg.ecosystem, from, to, at)
+		if err != nil {
+			return err
+		}
+		counts := make(map[g.ChangeKind]int)
+		for _, change := range *changes {
+			counts[change.Kind]++
+		}
+		cmd.PrintErrf("%s -> %s: %d added, %d removed, %d upgraded, %d downgraded\n", from, to, counts[g.Added], counts[g.Removed], counts[g.Upgraded], counts[g.Downgraded])
+		return writeRecords(cmd, export.DependencyChangeRecords(*changes))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	addGraphFlags(diffCmd)
+	addOutputFlag(diffCmd)
+	diffCmd.Flags().String("at", "", "Resolve both versions at this date instead of when they were published (YYYY-MM-DD or DD-MM-YYYY)")
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+// evolutionCmd represents the evolution command
+var evolutionCmd = &cobra.Command{
+	Use:   "evolution",
+	Short: "Shows how the structure of the graph evolved over time",
+	Long: `Slides a window of --window over the publish timestamps, moving it by --step, and computes the statistics of
+the graph of the versions published in every window: the numbers of nodes and edges, the mean size of the transitive
+closure, the dependency cycles and the most central versions. With --cumulative every window starts at the beginning,
+so it shows the graph as it grew. The series covers --from to --to, or the whole graph when they are not given. Use
+--output csv or json to process the series further.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		options := g.EvolutionOptions{}
+		window, _ := cmd.Flags().GetString("window")
+		if options.Window, err = g.ParsePeriod(window); err != nil {
+			return err
+		}
+		options.Step = options.Window
+		if step, _ := cmd.Flags().GetString("step"); step != "" {
+			if options.Step, err = g.ParsePeriod(step); err != nil {
+				return err
+			}
+		}
+		options.Cumulative, _ = cmd.Flags().GetBool("cumulative")
+		options.Top, _ = cmd.Flags().GetInt("top")
+		options.Samples, _ = cmd.Flags().GetInt("samples")
+
+		beginTime, endTime, filter, err := getInterval(cmd)
+		if err != nil {
+			return err
+		}
+		if !filter {
+			beginTime, endTime = g.PublishedSpan(lg.idToNodeInfo)
+			// The end of a window is exclusive, so the last version has to lie before it
+			endTime = endTime.AddDate(0, 0, 1)
+		}
+		return writeRecords(cmd, export.EvolutionRecords(g.GetEvolution(lg.graph, lg.idToNodeInfo, beginTime, endTime, options)))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(evolutionCmd)
+	addGraphFlags(evolutionCmd)
+	addOutputFlag(evolutionCmd)
+	addIntervalFlags(evolutionCmd)
+	evolutionCmd.Flags().String("window", "1y", "Length of every window (for example 1y, 6m, 2w or 30d)")
+	evolutionCmd.Flags().String("step", "", "Time between the beginnings of the windows, the length of the window by default")
+	evolutionCmd.Flags().Bool("cumulative", false, "Start every window at the beginning of the series")
+	evolutionCmd.Flags().Int("top", 3, "Number of most central versions to show per window")
+	evolutionCmd.Flags().Int("samples", 1000, "Number of versions per window whose transitive closure is computed, 0 uses all of them")
+}
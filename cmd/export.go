@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export <name> | --edges",
+	Short: "Writes the graph to a dot file",
+	Long: `Writes the graph to <name>.dot so it can be visualized with GraphViz. By default the nodes are labelled with
+their package information, --ids-only labels them with their IDs instead. When --from and --to are given, the graph
+is filtered to that time interval first.
+
+With --edges the edges are printed in the --output format instead. At --granularity package every edge also holds the
+number of versions that depend on each other and the time span in which they did.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if edges, _ := cmd.Flags().GetBool("edges"); edges {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		beginTime, endTime, filter, err := getInterval(cmd)
+		if err != nil {
+			return err
+		}
+		if filter {
+			g.FilterGraph(lg.graph, lg.idToNodeInfo, beginTime, endTime)
+		}
+
+		if edges, _ := cmd.Flags().GetBool("edges"); edges {
+			return writeRecords(cmd, export.EdgeRecords(lg.graph, lg.idToNodeInfo))
+		}
+		if idsOnly, _ := cmd.Flags().GetBool("ids-only"); idsOnly {
+			g.Visualization(lg.graph, args[0])
+		} else {
+			g.VisualizationNodeInfo(&lg.stringIDToNodeInfo, lg.graph, args[0])
+		}
+		return writeRecords(cmd, []export.Record{{
+			{Name: "file", Value: args[0] + ".dot"},
+			{Name: "nodes", Value: lg.graph.Nodes().Len()},
+			{Name: "edges", Value: lg.graph.Edges().Len()},
+		}})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	addGraphFlags(exportCmd)
+	addOutputFlag(exportCmd)
+	addIntervalFlags(exportCmd)
+	exportCmd.Flags().Bool("ids-only", false, "Label the nodes with their IDs only")
+	exportCmd.Flags().Bool("edges", false, "Print the edges instead of writing a dot file")
+}
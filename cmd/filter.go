@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+// filterCmd represents the filter command
+var filterCmd = &cobra.Command{
+	Use:   "filter --from <date> --to <date>",
+	Short: "Finds all packages published between two dates",
+	Long:  `Finds all the package versions that were published in the interval [from, to].`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		beginTime, endTime, filter, err := getInterval(cmd)
+		if err != nil {
+			return err
+		}
+		if !filter {
+			return errors.New("both --from and --to have to be set")
+		}
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+
+		nodes := *g.GetNodesInInterval(lg.idToNodeInfo, beginTime, endTime)
+		g.SortNodes(nodes)
+		return writeRecords(cmd, export.NodeRecords(nodes))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(filterCmd)
+	addGraphFlags(filterCmd)
+	addOutputFlag(filterCmd)
+	addIntervalFlags(filterCmd)
+	_ = filterCmd.MarkFlagRequired("from")
+	_ = filterCmd.MarkFlagRequired("to")
+}
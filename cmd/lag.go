@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+// lagCmd represents the lag command
+var lagCmd = &cobra.Command{
+	Use:   "lag [name@version|purl]",
+	Short: "Measures how far behind the dependencies of a package were",
+	Long: `Resolves the dependencies of a package version the way a package manager would have at the moment it was
+published, and compares every resolved version with the newest version that was available then: in days, in
+releases, in semantic version distance and in libyears. With --transitive the dependencies of the dependencies are
+included. Without a package the libyears of all releases are summarized per period of --every, within --from and --to
+when they are given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		if lg.granularity == g.PackageGranularity {
+			return errors.New("lag is measured between versions, lag needs --granularity version")
+		}
+		calculator := g.NewLagCalculator(lg.graph, lg.idToNodeInfo, lg.ecosystem)
+
+		if len(args) == 0 {
+			every, _ := cmd.Flags().GetString("every")
+			step, err := g.ParsePeriod(every)
+			if err != nil {
+				return err
+			}
+			beginTime, endTime, filter, err := getInterval(cmd)
+			if err != nil {
+				return err
+			}
+			if !filter {
+				beginTime, endTime = g.PublishedSpan(lg.idToNodeInfo)
+				// The end is exclusive, so the last release has to lie before it
+				endTime = endTime.AddDate(0, 0, 1)
+			}
+			return writeRecords(cmd, export.LibyearsPointRecords(calculator.LibyearsOverTime(beginTime, endTime, step)))
+		}
+
+		stringID, err := pickNode(lg, args[0])
+		if err != nil {
+			return err
+		}
+		node := lg.stringIDToNodeInfo[stringID]
+		libyears := calculator.Libyears(node)
+		cmd.PrintErrf("%s was %.2f libyears behind, %d of %d dependencies were outdated\n", stringID, libyears.Libyears, libyears.Outdated, libyears.Dependencies)
+		lags := calculator.DependencyLags(node)
+		if transitive, _ := cmd.Flags().GetBool("transitive"); transitive {
+			lags = calculator.TransitiveLags(node)
+		}
+		return writeRecords(cmd, export.LagRecords(lags))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lagCmd)
+	addGraphFlags(lagCmd)
+	addOutputFlag(lagCmd)
+	addIntervalFlags(lagCmd)
+	lagCmd.Flags().Bool("transitive", false, "Include the dependencies of the dependencies")
+	lagCmd.Flags().String("every", "1y", "Length of the periods the libyears are summarized over (for example 1y, 6m, 2w or 30d)")
+}
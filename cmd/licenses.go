@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/AJMBrands/SoftwareThatMatters/ingest"
+	"github.com/spf13/cobra"
+)
+
+// licensesCmd represents the licenses command
+var licensesCmd = &cobra.Command{
+	Use:   "licenses <name@version|purl>",
+	Short: "Lists the licenses a package pulls in through its dependencies",
+	Long: `Walks the transitive dependencies of a package and groups them by the SPDX license expression they declare.
+Every license shows the shortest chain of dependencies that introduced it and whether it conflicts with the license
+of the package. By default strong and network copyleft licenses conflict with permissive and weak copyleft packages,
+--policy points to a YAML or JSON file that changes the categories of licenses, the incompatible categories and the
+denied licenses.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		if lg.granularity == g.PackageGranularity {
+			return errors.New("licenses are declared by versions, licenses needs --granularity version")
+		}
+		if err := lg.requirePackages("licenses"); err != nil {
+			return err
+		}
+		policy := g.DefaultLicensePolicy()
+		if path, _ := cmd.Flags().GetString("policy"); path != "" {
+			if policy, err = ingest.LoadLicensePolicy(path); err != nil {
+				return err
+			}
+		}
+		if project, _ := cmd.Flags().GetString("project"); project != "" {
+			policy.Project = g.LicenseCategory(project)
+			if !isLicenseCategory(policy.Project) {
+				return fmt.Errorf("unknown license category %q, expected one of %v", project, g.LicenseCategories)
+			}
+		}
+		stringID, err := pickNode(lg, args[0])
+		if err != nil {
+			return err
+		}
+		kinds, _ := cmd.Flags().GetStringSlice("kind")
+
+		licenses := g.CreateStringIDToLicenseMap(lg.packagesList)
+		report := g.GetLicenseReport(lg.graph, lg.idToNodeInfo, lg.stringIDToNodeInfo, licenses, stringID, policy, g.TreeOptions{Kinds: kinds})
+		conflicts := 0
+		for _, usage := range report.Usages {
+			if usage.Conflict {
+				conflicts++
+			}
+		}
+		cmd.PrintErrf("%s is %s (%s), %d of %d licenses conflict\n", stringID, report.License, report.Category, conflicts, len(report.Usages))
+		return writeRecords(cmd, export.LicenseRecords(report))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(licensesCmd)
+	addGraphFlags(licensesCmd)
+	addOutputFlag(licensesCmd)
+	licensesCmd.Flags().String("policy", "", "YAML or JSON file with the license policy")
+	licensesCmd.Flags().String("project", "", "Category the package is distributed under (permissive, weak-copyleft, strong-copyleft, network-copyleft) instead of the one of its license")
+	licensesCmd.Flags().StringSlice("kind", nil, "Kinds of dependencies to follow (runtime, dev, peer, optional, build), all of them by default")
+}
+
+func isLicenseCategory(category g.LicenseCategory) bool {
+	for _, c := range g.LicenseCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+// rankCmd represents the rank command
+var rankCmd = &cobra.Command{
+	Use:   "rank",
+	Short: "Finds the most used packages",
+	Long: `Ranks the package versions with PageRank, which favours the packages that are depended upon the most, directly
+or transitively. When --from and --to are given, the graph is filtered to that time interval first.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		beginTime, endTime, filter, err := getInterval(cmd)
+		if err != nil {
+			return err
+		}
+		if filter {
+			g.FilterGraph(lg.graph, lg.idToNodeInfo, beginTime, endTime)
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		return writeRecords(cmd, export.RankedNodeRecords(*g.GetMostUsedNodes(lg.graph, lg.idToNodeInfo, limit)))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rankCmd)
+	addGraphFlags(rankCmd)
+	addOutputFlag(rankCmd)
+	addIntervalFlags(rankCmd)
+	rankCmd.Flags().IntP("limit", "n", 10, "Number of packages to show, 0 shows all of them")
+}
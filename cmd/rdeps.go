@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+// rdepsCmd represents the rdeps command
+var rdepsCmd = &cobra.Command{
+	Use:   "rdeps <name@version|purl>",
+	Short: "Finds all the packages that (transitively) depend on a package",
+	Long: `Finds all the packages that depend on a package, directly or transitively. When --from and --to are given,
+only the dependents published in that time interval are followed, like the dependents endpoint of the server does.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		stringID, err := pickNode(lg, args[0])
+		if err != nil {
+			return err
+		}
+		beginTime, endTime, filter, err := getInterval(cmd)
+		if err != nil {
+			return err
+		}
+		var nodes []g.NodeInfo
+		if filter {
+			nodes = *g.GetTransitiveDependentsNodeInInterval(lg.graph, lg.idToNodeInfo, lg.stringIDToNodeInfo, stringID, beginTime, endTime)
+		} else {
+			nodes = *g.GetTransitiveDependentsNode(lg.graph, lg.idToNodeInfo, lg.stringIDToNodeInfo, stringID)
+		}
+		g.SortNodes(nodes)
+		return writeRecords(cmd, export.NodeRecords(nodes))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rdepsCmd)
+	addGraphFlags(rdepsCmd)
+	addOutputFlag(rdepsCmd)
+	addIntervalFlags(rdepsCmd)
+}
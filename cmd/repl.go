@@ -0,0 +1,384 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/AJMBrands/SoftwareThatMatters/ingest"
+	"github.com/AJMBrands/SoftwareThatMatters/query"
+	"golang.org/x/term"
+	gonumgraph "gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// historyFileName is the file in the home directory in which the queries of every session are kept
+const historyFileName = ".stm_history"
+
+// replSession holds the state of the REPL between queries
+type replSession struct {
+	// lg is the graph the queries run on, versions and packages hold the graph at both granularities. The package-level
+	// graph is only created when it is used.
+	lg          *loadedGraph
+	versions    *loadedGraph
+	packages    *loadedGraph
+	out         io.Writer
+	historyPath string
+	history     []string
+}
+
+// runREPL reads queries from standard input until the user quits. On a terminal the line can be edited, the previous
+// queries can be recalled with the arrow keys and package names are completed with tab. Otherwise the queries are
+// read line by line, so a list of queries can be piped into the REPL.
+func runREPL(lg *loadedGraph) {
+	session := &replSession{lg: lg, versions: lg, out: os.Stdout}
+	if home, err := os.UserHomeDir(); err == nil {
+		session.historyPath = filepath.Join(home, historyFileName)
+		session.loadHistory()
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if !session.run(scanner.Text()) {
+				return
+			}
+		}
+		return
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		panic(err)
+	}
+	defer term.Restore(fd, state)
+	// The terminal adds every line it reads to the history of the arrow keys. It has no other way to fill that history,
+	// so the queries of the previous sessions are read by it first, with its output discarded.
+	var previous strings.Builder
+	for _, line := range session.history {
+		previous.WriteString(line + "\r")
+	}
+	output := &terminalOutput{Writer: io.Discard}
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{io.MultiReader(strings.NewReader(previous.String()), os.Stdin), output}, "stm> ")
+	for range session.history {
+		if _, err := terminal.ReadLine(); err != nil {
+			return
+		}
+	}
+	output.Writer = os.Stdout
+	terminal.AutoCompleteCallback = session.complete
+	// In raw mode newlines have to be written as \r\n, which the terminal does for us
+	session.out = terminal
+	fmt.Fprintln(terminal, "Type help to see the queries, tab completes commands and package names.")
+	for {
+		line, err := terminal.ReadLine()
+		if err != nil {
+			return
+		}
+		if !session.run(line) {
+			return
+		}
+	}
+}
+
+// terminalOutput is the writer of the terminal, which can be switched after the terminal was created
+type terminalOutput struct {
+	io.Writer
+}
+
+// run parses and executes a single line. It returns false when the user wants to quit.
+func (s *replSession) run(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return true
+	}
+	s.addHistory(line)
+	q, err := query.Parse(line)
+	if err != nil {
+		fmt.Fprintln(s.out, "Error:", err)
+		return true
+	}
+	if q.Command == "quit" {
+		return false
+	}
+
+	records, err := s.execute(q)
+	if err != nil {
+		fmt.Fprintln(s.out, "Error:", err)
+		return true
+	}
+	if err := s.write(q, records); err != nil {
+		fmt.Fprintln(s.out, "Error:", err)
+	}
+	return true
+}
+
+// execute runs a query and returns its results
+func (s *replSession) execute(q *query.Query) ([]export.Record, error) {
+	lg := s.lg
+	options := g.TreeOptions{MaxDepth: q.Depth, Kinds: q.Kinds, Interval: q.Interval, BeginTime: q.BeginTime, EndTime: q.EndTime}
+	switch q.Command {
+	case "deps", "rdeps":
+		stringID, err := s.resolve(q.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		if q.Command == "deps" {
+			return export.DependencyRecords(*g.GetDependencyTree(lg.graph, lg.idToNodeInfo, lg.stringIDToNodeInfo, stringID, options)), nil
+		}
+		return export.DependencyRecords(*g.GetDependentTree(lg.graph, lg.idToNodeInfo, lg.stringIDToNodeInfo, stringID, options)), nil
+
+	case "why":
+		from, err := s.resolve(q.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		to, err := s.resolve(q.Args[1])
+		if err != nil {
+			return nil, err
+		}
+		return export.PathRecords(*g.GetDependencyPath(lg.graph, lg.idToNodeInfo, lg.stringIDToNodeInfo, from, to)), nil
+
+	case "app":
+		components, err := ingest.ParseManifest(q.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		// Components are versions, so they are looked up in the version-level graph and then mapped to their packages
+		roots, missing := ingest.ResolveComponents(components, s.versions.stringIDToNodeInfo)
+		for _, component := range missing {
+			fmt.Fprintf(s.out, "Component %s %s was not found in the graph\n", component.Name, component.Version)
+		}
+		if lg.granularity == g.PackageGranularity {
+			for i, root := range roots {
+				name, _, _ := g.SplitStringID(root)
+				roots[i] = g.StringID(name, g.AllVersions)
+			}
+		}
+		graph := s.graphInInterval(q)
+		nodes := *g.GetTransitiveDependenciesNodes(graph, lg.idToNodeInfo, lg.stringIDToNodeInfo, roots)
+		g.SortNodes(nodes)
+		return export.NodeRecords(nodes), nil
+
+	case "filter":
+		if !q.Interval {
+			return nil, errors.New("filter expects: filter between DATE and DATE")
+		}
+		nodes := *g.GetNodesInInterval(lg.idToNodeInfo, q.BeginTime, q.EndTime)
+		g.SortNodes(nodes)
+		return export.NodeRecords(nodes), nil
+
+	case "rank":
+		limit := q.Limit
+		if limit == 0 {
+			limit = 10
+		}
+		return export.RankedNodeRecords(*g.GetMostUsedNodes(s.graphInInterval(q), lg.idToNodeInfo, limit)), nil
+
+	case "find":
+		limit := q.Limit
+		if limit == 0 {
+			limit = 20
+		}
+		return export.PackageRecords(s.lg.searchIndex().Search(q.Args[0], limit), lg.nameToVersions), nil
+
+	case "versions":
+		order, err := g.ParseVersionOrder(q.Order)
+		if err != nil {
+			return nil, err
+		}
+		nodes := s.lg.searchIndex().Versions(q.Args[0], order)
+		if nodes == nil {
+			return nil, fmt.Errorf("package %s was not found in the graph", q.Args[0])
+		}
+		return export.NodeRecords(nodes), nil
+
+	case "stats":
+		return []export.Record{export.StatsRecord(g.ComputeStats(s.graphInInterval(q), lg.nameToVersions))}, nil
+
+	case "granularity":
+		granularity, err := g.ParseGranularity(q.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		if granularity == g.PackageGranularity {
+			if s.packages == nil {
+				s.packages = s.versions.collapse()
+			}
+			s.lg = s.packages
+		} else {
+			s.lg = s.versions
+		}
+		return []export.Record{{
+			{Name: "granularity", Value: string(granularity)},
+			{Name: "nodes", Value: s.lg.graph.Nodes().Len()},
+			{Name: "edges", Value: s.lg.graph.Edges().Len()},
+		}}, nil
+
+	case "history":
+		records := make([]export.Record, len(s.history))
+		for i, line := range s.history {
+			records[i] = export.Record{{Name: "query", Value: line}}
+		}
+		return records, nil
+
+	case "help":
+		records := make([]export.Record, len(query.Commands))
+		for i, command := range query.Commands {
+			records[i] = export.Record{{Name: "usage", Value: command.Usage}}
+		}
+		return records, nil
+	}
+	return nil, fmt.Errorf("unknown command %q", q.Command)
+}
+
+// write prints the results, or writes them to the export target of the query
+func (s *replSession) write(q *query.Query, records []export.Record) error {
+	if q.Export == nil {
+		if len(records) == 0 {
+			fmt.Fprintln(s.out, "No results")
+			return nil
+		}
+		return export.Write(s.out, export.Table, records)
+	}
+
+	format, err := export.ParseFormat(q.Export.Format)
+	if err != nil {
+		return err
+	}
+	if q.Export.File == "" {
+		return export.Write(s.out, format, records)
+	}
+	file, err := os.Create(q.Export.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := export.Write(file, format, records); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "Exported %d results to %s\n", len(records), q.Export.File)
+	return file.Close()
+}
+
+// resolve turns a string id (name@version) or a package URL into the string id of its node
+func (s *replSession) resolve(reference string) (string, error) {
+	node, err := g.LookupNode(s.lg.stringIDToNodeInfo, s.lg.ecosystem, reference)
+	if err != nil {
+		return "", err
+	}
+	return g.StringID(node.Name, node.Version), nil
+}
+
+// graphInInterval returns the graph filtered to the interval of the query. The REPL keeps using the graph after the
+// query, so the filter is applied to a copy.
+func (s *replSession) graphInInterval(q *query.Query) *simple.DirectedGraph {
+	if !q.Interval {
+		return s.lg.graph
+	}
+	filtered := simple.NewDirectedGraph()
+	gonumgraph.Copy(filtered, s.lg.graph)
+	g.FilterGraph(filtered, s.lg.idToNodeInfo, q.BeginTime, q.EndTime)
+	return filtered
+}
+
+// complete is the tab completion of the terminal. The first word is completed with the commands, the arguments of
+// the commands that take packages with package names, and with versions once the @ was typed.
+func (s *replSession) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+	before := line[:pos]
+	words := strings.Fields(before)
+	word := ""
+	if len(words) > 0 && !strings.HasSuffix(before, " ") {
+		word = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	var candidates []string
+	switch {
+	case len(words) == 0:
+		for _, command := range query.Commands {
+			if strings.HasPrefix(command.Name, word) {
+				candidates = append(candidates, command.Name+" ")
+			}
+		}
+	case len(words) <= 2 && (words[0] == "deps" || words[0] == "rdeps" || words[0] == "why") || len(words) == 1 && (words[0] == "find" || words[0] == "versions"):
+		// Scoped npm packages start with an @ of their own, so only an @ after the first character separates the version
+		if i := strings.LastIndex(word, "@"); i > 0 {
+			for _, node := range s.lg.searchIndex().Versions(word[:i], g.BySemver) {
+				if candidate := node.Name + "@" + node.Version; strings.HasPrefix(candidate, word) {
+					candidates = append(candidates, candidate+" ")
+				}
+			}
+		} else {
+			for _, candidate := range s.lg.searchIndex().Prefix(word) {
+				if words[0] == "find" || words[0] == "versions" {
+					candidates = append(candidates, candidate+" ")
+				} else {
+					candidates = append(candidates, candidate+"@")
+				}
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return "", 0, false
+	}
+
+	completion := commonPrefix(candidates)
+	if len(completion) <= len(word) {
+		return "", 0, false
+	}
+	newLine := before[:len(before)-len(word)] + completion + line[pos:]
+	return newLine, pos - len(word) + len(completion), true
+}
+
+// commonPrefix returns the longest prefix all candidates share, ignoring case like the package search. The prefix is
+// written as in the first candidate.
+func commonPrefix(candidates []string) string {
+	prefix := candidates[0]
+	for _, candidate := range candidates[1:] {
+		for len(candidate) < len(prefix) || !strings.EqualFold(candidate[:len(prefix)], prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// loadHistory reads the queries of the previous sessions, runREPL passes them on to the terminal
+func (s *replSession) loadHistory() {
+	data, err := os.ReadFile(s.historyPath)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			s.history = append(s.history, line)
+		}
+	}
+}
+
+// addHistory remembers a query and appends it to the history file
+func (s *replSession) addHistory(line string) {
+	s.history = append(s.history, line)
+	if s.historyPath == "" {
+		return
+	}
+	file, err := os.OpenFile(s.historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	fmt.Fprintln(file, line)
+}
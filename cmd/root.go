@@ -12,7 +12,11 @@ var rootCmd = &cobra.Command{
 	Short: "SoftwareThatMatters-Graph is an application that creates a graph from a JSON file and allows you to query it",
 	Long: `SoftwareThatMatters-Graph is an application that loads a list of packages and their dependencies
 from a JSON, creates a time-dependent graph from and allows you to query it.
-The accepted json format can be found at TODO: Point to JSON format.`,
+The accepted json format can be found at TODO: Point to JSON format.
+
+Use "start" for the interactive mode, or one of the other commands to run a single query from a script.`,
+	// Errors of the non-interactive commands are about their input, not about how they were called
+	SilenceUsage: true,
 
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
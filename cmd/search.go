@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Searches packages by name",
+	Long: `Searches the package names that match the query best. The search ignores case, and also finds names that contain
+the query, or only its characters in order. With --versions the versions of the best match are listed instead, in the
+order given by --sort.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sort, _ := cmd.Flags().GetString("sort")
+		order, err := g.ParseVersionOrder(sort)
+		if err != nil {
+			return err
+		}
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+
+		index := lg.searchIndex()
+		limit, _ := cmd.Flags().GetInt("limit")
+		names := index.Search(args[0], limit)
+		if versions, _ := cmd.Flags().GetBool("versions"); versions {
+			if len(names) == 0 {
+				return errors.New("no package matches " + args[0])
+			}
+			return writeRecords(cmd, export.NodeRecords(index.Versions(names[0], order)))
+		}
+		return writeRecords(cmd, export.PackageRecords(names, lg.nameToVersions))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	addGraphFlags(searchCmd)
+	addOutputFlag(searchCmd)
+	searchCmd.Flags().IntP("limit", "n", 20, "Number of packages to show")
+	searchCmd.Flags().Bool("versions", false, "List the versions of the best match instead of the packages")
+	searchCmd.Flags().String("sort", string(g.BySemver), "Order of the versions, date or semver")
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"github.com/AJMBrands/SoftwareThatMatters/server"
+	"github.com/spf13/cobra"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serves the queries on the graph over an HTTP JSON API",
+	Long: `Loads the graph (or a snapshot of it) once and answers queries on it over HTTP, so several tools can share
+one in-memory graph. Every endpoint answers with JSON:
+
+  GET /api/packages?q=<prefix>          package names
+  GET /api/versions?name=<name>         versions of a package
+  GET /api/node?id=<name@version|purl>  a single package version
+  GET /api/dependencies?id=&from=&to=   transitive dependencies, optionally in a time interval
+  GET /api/dependents?id=&from=&to=     transitive dependents, optionally in a time interval
+  GET /api/filter?from=&to=             package versions published in a time interval
+  GET /api/path?source=&target=         shortest chain of dependencies between two versions
+  GET /api/rank                         package versions ordered by PageRank
+  GET /api/stats                        statistics of the graph
+
+GraphQL queries over packages, versions and their dependency trees are answered on /graphql. Unless --ui=false is
+given, a web front-end to explore the graph is served on /.
+
+Listings are paginated with the offset and limit parameters.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+
+		options := server.DefaultOptions
+		options.Timeout, _ = cmd.Flags().GetDuration("timeout")
+		options.MaxLimit, _ = cmd.Flags().GetInt("max-limit")
+		options.UI, _ = cmd.Flags().GetBool("ui")
+		options.Ecosystem = lg.ecosystem
+		addr, _ := cmd.Flags().GetString("addr")
+
+		s := server.NewServer(lg.graph, lg.stringIDToNodeInfo, lg.idToNodeInfo, lg.nameToVersions, options)
+		cmd.PrintErrf("Serving a graph with %d nodes on %s\n", lg.graph.Nodes().Len(), addr)
+		return s.ListenAndServe(addr)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	addGraphFlags(serveCmd)
+	serveCmd.Flags().String("addr", ":8080", "Address to listen on")
+	serveCmd.Flags().Duration("timeout", server.DefaultOptions.Timeout, "Time a single request may take")
+	serveCmd.Flags().Int("max-limit", server.DefaultOptions.MaxLimit, "Largest page size a request may ask for")
+	serveCmd.Flags().Bool("ui", server.DefaultOptions.UI, "Serve the web front-end on /")
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+// simulateCmd represents the simulate command
+var simulateCmd = &cobra.Command{
+	Use:   "simulate <name@version>",
+	Short: "Shows what publishing a new version of a package would affect",
+	Long: `Evaluates the constraints of every version in the graph against a version of a package that is not in it yet,
+and lists the dependents whose constraints would match it. A dependent adopts the new version when it is higher than
+the version it resolves to now. The number of versions that would then depend on the new version, directly or
+transitively, is printed as well. The graph itself is not changed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, version, ok := g.SplitStringID(args[0])
+		if !ok {
+			return fmt.Errorf("%s is not a package version, expected name@version", args[0])
+		}
+		release := g.Release{Name: name, Version: version, VersionInfo: g.VersionInfo{Dependencies: make(map[string]string)}}
+		release.Timestamp, _ = cmd.Flags().GetString("timestamp")
+		if release.Timestamp == "" {
+			release.Timestamp = time.Now().UTC().Format(time.RFC3339)
+		}
+		dependencies, _ := cmd.Flags().GetStringArray("dependency")
+		for _, dependency := range dependencies {
+			dependencyName, constraint, ok := strings.Cut(dependency, "=")
+			if !ok || dependencyName == "" {
+				return fmt.Errorf("invalid dependency %q, expected name=constraint", dependency)
+			}
+			release.Dependencies[dependencyName] = constraint
+		}
+
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		if lg.granularity == g.PackageGranularity {
+			return errors.New("constraints match versions, simulate needs --granularity version")
+		}
+		if err := lg.requirePackages("simulate"); err != nil {
+			return err
+		}
+		impact, err := g.SimulateRelease(lg.graph, lg.idToNodeInfo, lg.stringIDToNodeInfo, lg.packagesList, lg.nameToVersions, lg.ecosystem, release)
+		if err != nil {
+			return err
+		}
+		adopters := 0
+		for _, dependent := range impact.Dependents {
+			if dependent.Adopts {
+				adopters++
+			}
+		}
+		cmd.PrintErrf("%s would match %d dependents, %d would adopt it and %d versions would depend on it transitively\n", args[0], len(impact.Dependents), adopters, impact.Affected)
+		return writeRecords(cmd, export.AffectedDependentRecords(impact.Dependents))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+	addGraphFlags(simulateCmd)
+	addOutputFlag(simulateCmd)
+	simulateCmd.Flags().String("timestamp", "", "Moment the new version is published, now by default")
+	simulateCmd.Flags().StringArrayP("dependency", "d", nil, "Dependency of the new version as name=constraint, can be repeated")
+}
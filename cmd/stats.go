@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Shows statistics about the graph",
+	Long: `Shows the number of nodes, edges and packages in the graph together with its degree statistics. When --from
+and --to are given, the graph is filtered to that time interval first.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		beginTime, endTime, filter, err := getInterval(cmd)
+		if err != nil {
+			return err
+		}
+		if filter {
+			g.FilterGraph(lg.graph, lg.idToNodeInfo, beginTime, endTime)
+		}
+
+		stats := g.ComputeStats(lg.graph, lg.nameToVersions)
+		return writeRecords(cmd, []export.Record{export.StatsRecord(stats)})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	addGraphFlags(statsCmd)
+	addOutputFlag(statsCmd)
+	addIntervalFlags(statsCmd)
+}
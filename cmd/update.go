@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/AJMBrands/SoftwareThatMatters/ingest"
+	"github.com/spf13/cobra"
+)
+
+// updateCmd represents the update command
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Adds the versions in a registry change log to the graph",
+	Long: `Reads a change log in the NDJSON format of the npm _changes feed (requested with include_docs=true) and adds
+the versions it holds that are not in the graph yet, without building the graph again. The dependencies of the new
+versions are matched against the versions in the graph, and the constraints of the versions in the graph are matched
+against the new versions. Versions that are already in the graph get the deprecation and unpublish status of the
+change. Deleted packages stay in the graph with their versions unpublished at the time of the change, or when the
+feed doesn't hold it at the time the update runs. The updated graph is written to --save-snapshot. The sequence of
+the last change is reported, so the next change log can continue after it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		start := time.Now()
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		if lg.granularity != g.VersionGranularity {
+			return errors.New("snapshots hold the version-level graph, update can't be combined with --granularity package")
+		}
+		if err := lg.requirePackages("update"); err != nil {
+			return err
+		}
+		updater := g.NewUpdater(lg.graph, lg.packagesList, lg.stringIDToNodeInfo, lg.idToNodeInfo, lg.nameToVersions, lg.ecosystem)
+
+		changes, _ := cmd.Flags().GetString("changes")
+		count, deleted, lastSeq := 0, 0, ""
+		err = ingest.LoadChanges(changes, func(change ingest.Change) error {
+			count++
+			lastSeq = change.Seq
+			if change.Deleted {
+				deleted++
+				at := change.Time
+				if at == "" {
+					at = start.UTC().Format(time.RFC3339)
+				}
+				updater.Unpublish(change.Name, at)
+				return nil
+			}
+			updater.AddPackage(change.Package)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		snapshot, _ := cmd.Flags().GetString("save-snapshot")
+		if err := g.SaveSnapshot(snapshot, lg.graph, lg.packagesList, lg.idToNodeInfo, lg.ecosystem); err != nil {
+			return err
+		}
+		cmd.PrintErrf("Wrote a snapshot of the updated graph to %s\n", snapshot)
+		return writeRecords(cmd, []export.Record{{
+			{Name: "changes", Value: count},
+			{Name: "deleted", Value: deleted},
+			{Name: "versions", Value: updater.Versions},
+			{Name: "updated", Value: updater.Updated},
+			{Name: "edges", Value: updater.Edges},
+			{Name: "last_seq", Value: lastSeq},
+			{Name: "duration_ms", Value: time.Since(start).Milliseconds()},
+		}})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(updateCmd)
+	addGraphFlags(updateCmd)
+	addOutputFlag(updateCmd)
+	updateCmd.Flags().String("changes", "", "NDJSON file with the changes, in the format of the npm _changes feed")
+	updateCmd.Flags().String("save-snapshot", "", "File the snapshot of the updated graph is written to")
+	_ = updateCmd.MarkFlagRequired("changes")
+	_ = updateCmd.MarkFlagRequired("save-snapshot")
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/AJMBrands/SoftwareThatMatters/ingest"
+	"github.com/spf13/cobra"
+)
+
+// vulnsCmd represents the vulns command
+var vulnsCmd = &cobra.Command{
+	Use:   "vulns [name@version|purl]",
+	Short: "Finds the package versions that are exposed to known vulnerabilities",
+	Long: `Reads the OSV advisories in the JSON files of --advisories and lists every package version that depends,
+directly or transitively, on a version an advisory applies to. Every exposure shows the shortest chain of dependencies
+to the vulnerable version and the time window in which it existed: from the moment every version on the chain had been
+published until a version of the vulnerable package that fixes the advisory was published. When a package is given,
+only its exposures are shown.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		if lg.granularity == g.PackageGranularity {
+			return errors.New("advisories apply to versions, vulns needs --granularity version")
+		}
+		dir, _ := cmd.Flags().GetString("advisories")
+		advisories, err := ingest.LoadAdvisories(dir, lg.ecosystem)
+		if err != nil {
+			return err
+		}
+		var stringID string
+		if len(args) == 1 {
+			if stringID, err = pickNode(lg, args[0]); err != nil {
+				return err
+			}
+		}
+
+		exposures := *g.GetExposures(lg.graph, lg.idToNodeInfo, lg.stringIDToNodeInfo, lg.nameToVersions, lg.ecosystem, advisories)
+		if stringID != "" {
+			filtered := make([]g.Exposure, 0)
+			for _, exposure := range exposures {
+				if g.StringID(exposure.Node.Name, exposure.Node.Version) == stringID {
+					filtered = append(filtered, exposure)
+				}
+			}
+			exposures = filtered
+		}
+		cmd.PrintErrf("%d advisories, %d exposed versions\n", len(advisories), len(exposures))
+		return writeRecords(cmd, export.ExposureRecords(exposures))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(vulnsCmd)
+	addGraphFlags(vulnsCmd)
+	addOutputFlag(vulnsCmd)
+	vulnsCmd.Flags().String("advisories", "", "Directory with OSV advisories in JSON format")
+	_ = vulnsCmd.MarkFlagRequired("advisories")
+}
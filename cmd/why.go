@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+// whyCmd represents the why command
+var whyCmd = &cobra.Command{
+	Use:   "why <dependent name@version|purl> <dependency name@version|purl>",
+	Short: "Explains why a package depends on another one",
+	Long: `Shows the shortest chain of dependencies through which the first package (transitively) depends on the
+second one.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		stringIDs := make([]string, len(args))
+		for i, arg := range args {
+			if stringIDs[i], err = pickNode(lg, arg); err != nil {
+				return err
+			}
+		}
+
+		nodes := *g.GetDependencyPath(lg.graph, lg.idToNodeInfo, lg.stringIDToNodeInfo, stringIDs[0], stringIDs[1])
+		if len(nodes) == 0 {
+			cmd.PrintErrf("%s does not depend on %s\n", stringIDs[0], stringIDs[1])
+		}
+		return writeRecords(cmd, export.PathRecords(nodes))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whyCmd)
+	addGraphFlags(whyCmd)
+	addOutputFlag(whyCmd)
+}
@@ -1 +1,148 @@
+// Package export writes query results in the formats other tools can consume.
 package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Format is an output format for query results
+type Format string
+
+const (
+	Table  Format = "table"
+	JSON   Format = "json"
+	NDJSON Format = "ndjson"
+	CSV    Format = "csv"
+)
+
+// Formats lists every supported format, the first one is the default
+var Formats = []Format{Table, JSON, NDJSON, CSV}
+
+// ParseFormat returns the format with the given name
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(name) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q, expected one of %v", name, Formats)
+}
+
+// Field is a named value in a Record
+type Field struct {
+	Name  string
+	Value interface{}
+}
+
+// Record is one row of a query result. The fields are kept in order so the columns of tables and CSV files and the
+// keys of JSON objects always come out the same way.
+type Record []Field
+
+// MarshalJSON writes the record as a JSON object with its keys in the order of the fields
+func (record Record) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, field := range record {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		name, err := json.Marshal(field.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(name)
+		buffer.WriteByte(':')
+		buffer.Write(value)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// Write writes the records to w in the given format. The columns of the table and CSV formats are taken from the
+// first record, so all records are expected to have the same fields.
+func Write(w io.Writer, format Format, records []Record) error {
+	switch format {
+	case Table:
+		return writeTable(w, records)
+	case JSON:
+		if records == nil {
+			records = []Record{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case NDJSON:
+		encoder := json.NewEncoder(w)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSV:
+		return writeCSV(w, records)
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+func writeTable(w io.Writer, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	names := make([]string, len(records[0]))
+	for i, field := range records[0] {
+		names[i] = strings.ToUpper(field.Name)
+	}
+	fmt.Fprintln(tw, strings.Join(names, "\t"))
+	for _, record := range records {
+		fmt.Fprintln(tw, strings.Join(stringValues(record), "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeCSV(w io.Writer, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	cw := csv.NewWriter(w)
+	names := make([]string, len(records[0]))
+	for i, field := range records[0] {
+		names[i] = field.Name
+	}
+	if err := cw.Write(names); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := cw.Write(stringValues(record)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func stringValues(record Record) []string {
+	values := make([]string, len(record))
+	for i, field := range record {
+		switch value := field.Value.(type) {
+		case float64:
+			values[i] = fmt.Sprintf("%.6g", value)
+		case []string:
+			values[i] = strings.Join(value, ";")
+		default:
+			values[i] = fmt.Sprint(value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,53 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	records := []Record{
+		{{"name", "A"}, {"version", "1.0.0"}, {"rank", 0.5}},
+		{{"name", "B, C"}, {"version", "2.0.0"}, {"rank", 0.25}},
+	}
+	tests := []struct {
+		format   Format
+		expected string
+	}{
+		{Table, "NAME  VERSION  RANK\nA     1.0.0    0.5\nB, C  2.0.0    0.25\n"},
+		{JSON, "[\n  {\n    \"name\": \"A\",\n    \"version\": \"1.0.0\",\n    \"rank\": 0.5\n  },\n  {\n    \"name\": \"B, C\",\n    \"version\": \"2.0.0\",\n    \"rank\": 0.25\n  }\n]\n"},
+		{NDJSON, "{\"name\":\"A\",\"version\":\"1.0.0\",\"rank\":0.5}\n{\"name\":\"B, C\",\"version\":\"2.0.0\",\"rank\":0.25}\n"},
+		{CSV, "name,version,rank\nA,1.0.0,0.5\n\"B, C\",2.0.0,0.25\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.format), func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := Write(&buffer, test.format, records); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if buffer.String() != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, buffer.String())
+			}
+		})
+	}
+
+	t.Run("An empty JSON result is an empty array", func(t *testing.T) {
+		var buffer bytes.Buffer
+		if err := Write(&buffer, JSON, nil); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if buffer.String() != "[]\n" {
+			t.Errorf("Expected an empty array, got %q", buffer.String())
+		}
+	})
+}
+
+func TestParseFormat(t *testing.T) {
+	if format, err := ParseFormat("NDJSON"); err != nil || format != NDJSON {
+		t.Errorf("Expected ndjson, got %q (%v)", format, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
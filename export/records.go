@@ -0,0 +1,314 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// NodeRecord converts a node to a record with the fields id, name, version and timestamp
+func NodeRecord(node g.NodeInfo) Record {
+	return Record{
+		{"id", node.ID()},
+		{"name", node.Name},
+		{"version", node.Version},
+		{"timestamp", node.Timestamp},
+	}
+}
+
+// NodeRecords converts every node to a record using NodeRecord
+func NodeRecords(nodes []g.NodeInfo) []Record {
+	records := make([]Record, len(nodes))
+	for i, node := range nodes {
+		records[i] = NodeRecord(node)
+	}
+	return records
+}
+
+// PathRecords converts a chain of dependencies to records that also hold the position of every node in the chain
+func PathRecords(nodes []g.NodeInfo) []Record {
+	records := make([]Record, len(nodes))
+	for i, node := range nodes {
+		records[i] = append(Record{{"step", i}}, NodeRecord(node)...)
+	}
+	return records
+}
+
+// RankedNodeRecords converts a ranking to records that also hold the position and score of every node
+func RankedNodeRecords(nodes []g.RankedNode) []Record {
+	records := make([]Record, len(nodes))
+	for i, node := range nodes {
+		records[i] = append(append(Record{{"position", i + 1}}, NodeRecord(node.NodeInfo)...), Field{"rank", node.Rank})
+	}
+	return records
+}
+
+// StatsRecord converts graph statistics to a single record
+func StatsRecord(stats g.Stats) Record {
+	return Record{
+		{"nodes", stats.Nodes},
+		{"edges", stats.Edges},
+		{"packages", stats.Packages},
+		{"roots", stats.Roots},
+		{"leaves", stats.Leaves},
+		{"max_dependencies", stats.MaxDependencies},
+		{"max_dependents", stats.MaxDependents},
+		{"mean_dependencies", stats.MeanDependencies},
+	}
+}
+
+// DependencyRecords converts a dependency tree to records that also hold the depth and kind of every dependency and
+// the string id of the node through which it was reached
+func DependencyRecords(dependencies []g.Dependency) []Record {
+	records := make([]Record, len(dependencies))
+	for i, dependency := range dependencies {
+		records[i] = append(append(Record{{"depth", dependency.Depth}}, NodeRecord(dependency.Node)...),
+			Field{"kind", dependency.Kind},
+			Field{"parent", g.StringID(dependency.Parent.Name, dependency.Parent.Version)})
+	}
+	return records
+}
+
+// PackageRecords converts package names to records that also hold the number of versions of every package
+func PackageRecords(names []string, nameToVersions map[string][]string) []Record {
+	records := make([]Record, len(names))
+	for i, name := range names {
+		records[i] = Record{{"name", name}, {"versions", len(nameToVersions[name])}}
+	}
+	return records
+}
+
+// EdgeRecords converts every edge of the graph to a record with the string ids of the dependent and the dependency
+// and the kind of the dependency. The edges of a package-level graph also hold the number of version edges and
+// dependent versions they aggregate and the time span in which the dependency was declared.
+func EdgeRecords(graph *simple.DirectedGraph, nodeMap map[int64]g.NodeInfo) []Record {
+	records := make([]Record, 0, graph.Edges().Len())
+	edges := graph.Edges()
+	for edges.Next() {
+		edge := edges.Edge()
+		from, to := nodeMap[edge.From().ID()], nodeMap[edge.To().ID()]
+		record := Record{
+			{"from", g.StringID(from.Name, from.Version)},
+			{"to", g.StringID(to.Name, to.Version)},
+			{"kind", g.EdgeKind(edge)},
+		}
+		if packageEdge, ok := edge.(g.PackageEdge); ok {
+			record = append(record,
+				Field{"versions", packageEdge.Count},
+				Field{"dependents", packageEdge.Dependents},
+				Field{"first_seen", packageEdge.FirstSeen},
+				Field{"last_seen", packageEdge.LastSeen})
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i][0].Value != records[j][0].Value {
+			return records[i][0].Value.(string) < records[j][0].Value.(string)
+		}
+		return records[i][1].Value.(string) < records[j][1].Value.(string)
+	})
+	return records
+}
+
+// ExposureRecords converts exposures to records with the advisory, the exposed and the vulnerable version, the chain
+// of dependencies between them and the time window of the exposure. Times that are unknown are left empty.
+func ExposureRecords(exposures []g.Exposure) []Record {
+	records := make([]Record, len(exposures))
+	for i, exposure := range exposures {
+		records[i] = Record{
+			{"advisory", exposure.Advisory},
+			{"exposed", g.StringID(exposure.Node.Name, exposure.Node.Version)},
+			{"vulnerable", g.StringID(exposure.Vulnerable.Name, exposure.Vulnerable.Version)},
+			{"depth", exposure.Depth},
+			{"path", joinPath(exposure.Path)},
+			{"exposed_from", formatTime(exposure.Begin)},
+			{"exposed_until", formatTime(exposure.End)},
+		}
+	}
+	return records
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// LicenseRecords converts a license report to records with the license, its category, whether it conflicts with the
+// license of the root, the number of dependencies that declare it and the chain of dependencies that introduced it
+func LicenseRecords(report *g.LicenseReport) []Record {
+	records := make([]Record, len(report.Usages))
+	for i, usage := range report.Usages {
+		records[i] = Record{
+			{"license", usage.License},
+			{"category", string(usage.Category)},
+			{"conflict", usage.Conflict},
+			{"versions", len(usage.Nodes)},
+			{"path", joinPath(usage.Path)},
+		}
+	}
+	return records
+}
+
+// joinPath writes a chain of dependencies as the string ids joined by arrows
+func joinPath(nodes []g.NodeInfo) string {
+	return joinNodes(nodes, " -> ")
+}
+
+func joinNodes(nodes []g.NodeInfo, separator string) string {
+	ids := make([]string, len(nodes))
+	for i, node := range nodes {
+		ids[i] = g.StringID(node.Name, node.Version)
+	}
+	return strings.Join(ids, separator)
+}
+
+// LagRecords converts the lag of dependencies to records with the dependent, the resolved and the newest version and
+// the lag in days, releases, semantic version distance and libyears
+func LagRecords(lags []g.Lag) []Record {
+	records := make([]Record, len(lags))
+	for i, lag := range lags {
+		records[i] = Record{
+			{"dependent", g.StringID(lag.Parent.Name, lag.Parent.Version)},
+			{"depth", lag.Depth},
+			{"kind", lag.Kind},
+			{"dependency", g.StringID(lag.Node.Name, lag.Node.Version)},
+			{"newest", lag.Newest.Version},
+			{"days", lag.Days},
+			{"releases", lag.Releases},
+			{"major", lag.Distance.Major},
+			{"minor", lag.Distance.Minor},
+			{"patch", lag.Distance.Patch},
+			{"libyears", lag.Libyears()},
+		}
+	}
+	return records
+}
+
+// LibyearsPointRecords converts a libyears time series to records, one for every period
+func LibyearsPointRecords(points []g.LibyearsPoint) []Record {
+	records := make([]Record, len(points))
+	for i, point := range points {
+		records[i] = Record{
+			{"begin", formatTime(point.Begin)},
+			{"end", formatTime(point.End)},
+			{"releases", point.Releases},
+			{"mean_libyears", point.MeanLibyears},
+			{"median_libyears", point.MedianLibyears},
+			{"max_libyears", point.MaxLibyears},
+		}
+	}
+	return records
+}
+
+// CycleRecords converts cycles to records with the number of members and edges, the members and an example cycle
+func CycleRecords(cycles []g.Cycle) []Record {
+	records := make([]Record, len(cycles))
+	for i, cycle := range cycles {
+		records[i] = Record{
+			{"size", len(cycle.Nodes)},
+			{"edges", cycle.Edges},
+			{"members", joinNodes(cycle.Nodes, ", ")},
+			{"example", joinPath(cycle.Example)},
+		}
+	}
+	return records
+}
+
+// ComponentRecords converts components in topological order to records with their position, size and members
+func ComponentRecords(components [][]g.NodeInfo) []Record {
+	records := make([]Record, len(components))
+	for i, members := range components {
+		records[i] = Record{
+			{"position", i + 1},
+			{"size", len(members)},
+			{"members", joinNodes(members, ", ")},
+		}
+	}
+	return records
+}
+
+// AuthorRankRecords converts an author ranking to records with the position, the number of packages and versions of
+// every author and the number of transitive dependents of those versions
+func AuthorRankRecords(ranking []g.AuthorRank) []Record {
+	records := make([]Record, len(ranking))
+	for i, rank := range ranking {
+		records[i] = Record{
+			{"position", i + 1},
+			{"author", rank.Author},
+			{"packages", rank.Packages},
+			{"versions", rank.Versions},
+			{"dependents", rank.Dependents},
+		}
+	}
+	return records
+}
+
+// CriticalPackageRecords converts packages with a single author to records with their author and reach
+func CriticalPackageRecords(packages []g.CriticalPackage) []Record {
+	records := make([]Record, len(packages))
+	for i, critical := range packages {
+		records[i] = Record{
+			{"name", critical.Name},
+			{"author", critical.Author},
+			{"versions", critical.Versions},
+			{"dependents", critical.Dependents},
+		}
+	}
+	return records
+}
+
+// EvolutionRecords converts a time series of graph statistics to records, one for every window. The most central
+// versions are written as one field with their PageRank.
+func EvolutionRecords(points []g.EvolutionPoint) []Record {
+	records := make([]Record, len(points))
+	for i, point := range points {
+		top := make([]string, len(point.Top))
+		for j, node := range point.Top {
+			top[j] = fmt.Sprintf("%s (%.4f)", g.StringID(node.Name, node.Version), node.Rank)
+		}
+		record := append(Record{{"begin", formatTime(point.Begin)}, {"end", formatTime(point.End)}}, StatsRecord(point.Stats)...)
+		records[i] = append(record,
+			Field{"mean_closure", point.MeanClosure},
+			Field{"cycles", point.Cycles},
+			Field{"cycle_nodes", point.CycleNodes},
+			Field{"top", strings.Join(top, ", ")})
+	}
+	return records
+}
+
+// DependencyChangeRecords converts the difference between two dependency trees to records, one for every package that
+// changed
+func DependencyChangeRecords(changes []g.DependencyChange) []Record {
+	records := make([]Record, len(changes))
+	for i, change := range changes {
+		records[i] = Record{
+			{"name", change.Name},
+			{"change", change.Kind},
+			{"from", change.From},
+			{"to", change.To},
+			{"semver", change.Change},
+		}
+	}
+	return records
+}
+
+// AffectedDependentRecords converts the dependents a release would match to records with the version they resolve to
+// now
+func AffectedDependentRecords(dependents []g.AffectedDependent) []Record {
+	records := make([]Record, len(dependents))
+	for i, dependent := range dependents {
+		records[i] = Record{
+			{"dependent", g.StringID(dependent.Node.Name, dependent.Node.Version)},
+			{"constraint", dependent.Constraint},
+			{"current", dependent.Current.Version},
+			{"adopts", dependent.Adopts},
+		}
+	}
+	return records
+}
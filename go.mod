@@ -4,9 +4,14 @@ go 1.18
 
 require (
 	github.com/AlecAivazis/survey/v2 v2.3.4
+	github.com/BurntSushi/toml v1.2.1
 	github.com/Masterminds/semver v1.5.0
+	github.com/graphql-go/graphql v0.8.1
+	github.com/klauspost/compress v1.16.7
 	github.com/spf13/cobra v1.4.0
+	golang.org/x/term v0.0.0-20210503060354-a79de5458b56
 	gonum.org/v1/gonum v0.11.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -16,7 +21,7 @@ require (
 	github.com/mattn/go-isatty v0.0.8 // indirect
 	github.com/mgutz/ansi v0.0.0-20170206155736-9520e82c474b // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
+	golang.org/x/exp v0.0.0-20191002040644-a1355ae1e2c3 // indirect
 	golang.org/x/sys v0.0.0-20201119102817-f84b799fce68 // indirect
-	golang.org/x/term v0.0.0-20210503060354-a79de5458b56 // indirect
 	golang.org/x/text v0.3.7 // indirect
 )
@@ -0,0 +1,232 @@
+package graph
+
+import (
+	"math/bits"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/simple"
+	"gonum.org/v1/gonum/graph/traverse"
+)
+
+// SplitAuthors splits the author field of a version into the authors it names. Registries separate several authors
+// with commas or semicolons.
+func SplitAuthors(author string) []string {
+	var result []string
+	for _, name := range strings.FieldsFunc(author, func(r rune) bool { return r == ',' || r == ';' }) {
+		if name = strings.TrimSpace(name); name != "" {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// countDependents counts the nodes that depend on one of the sources, directly or transitively, without the sources
+func countDependents(g *simple.DirectedGraph, sources []graph.Node) int {
+	isSource := make(map[int64]bool, len(sources))
+	for _, source := range sources {
+		isSource[source.ID()] = true
+	}
+	count := 0
+	w := traverse.BreadthFirst{
+		Visit: func(n graph.Node) {
+			if n.ID() != virtualSource && !isSource[n.ID()] {
+				count++
+			}
+		},
+	}
+	_ = w.Walk(sourcedGraph{reversedGraph{g}, sources}, simple.Node(virtualSource), nil)
+	return count
+}
+
+// countSetDependents counts for every set of nodes the nodes that depend on one of its members, directly or
+// transitively, without the members themselves, like countDependents does for one set. Walking the graph for every
+// set would take O(sets * (V+E)), so the sets are instead propagated over the condensation of the graph in
+// topological order, 64 at a time as the bits of a word: O((V+E) * sets/64). Sets whose members have no dependents at
+// all, which are most of them in a registry, are not propagated.
+func countSetDependents(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, sets [][]int64) []int {
+	counts := make([]int, len(sets))
+	var pending []int
+	for i, set := range sets {
+		for _, id := range set {
+			if g.To(id).Len() > 0 {
+				pending = append(pending, i)
+				break
+			}
+		}
+	}
+	if len(pending) == 0 {
+		return counts
+	}
+
+	// The components in topological order, dependencies first, and for each the positions of the components it
+	// depends on
+	condensation := Condense(g, nodeMap)
+	order := condensation.TopologicalOrder()
+	position := make(map[int64]int, len(order))
+	for i, members := range order {
+		position[condensation.ComponentOf[members[0].id]] = i
+	}
+	dependencies := make([][]int, len(order))
+	for i, members := range order {
+		componentDependencies := condensation.Graph.From(condensation.ComponentOf[members[0].id])
+		for componentDependencies.Next() {
+			dependencies[i] = append(dependencies[i], position[componentDependencies.Node().ID()])
+		}
+	}
+
+	own := make(map[int64]uint64)
+	componentOwn := make([]uint64, len(order))
+	reached := make([]uint64, len(order))
+	for begin := 0; begin < len(pending); begin += 64 {
+		batch := pending[begin:]
+		if len(batch) > 64 {
+			batch = batch[:64]
+		}
+		for id := range own {
+			delete(own, id)
+		}
+		for i := range componentOwn {
+			componentOwn[i] = 0
+		}
+		for bit, set := range batch {
+			for _, id := range sets[set] {
+				own[id] |= 1 << uint(bit)
+				componentOwn[position[condensation.ComponentOf[id]]] |= 1 << uint(bit)
+			}
+		}
+		for i, members := range order {
+			// A node reaches the sets of its dependencies and of everything they reach. In a cycle every member also
+			// depends on the other members.
+			var mask uint64
+			for _, dependency := range dependencies[i] {
+				mask |= reached[dependency] | componentOwn[dependency]
+			}
+			if len(members) > 1 {
+				mask |= componentOwn[i]
+			}
+			reached[i] = mask
+			for _, member := range members {
+				for remaining := mask &^ own[member.id]; remaining != 0; remaining &= remaining - 1 {
+					counts[batch[bits.TrailingZeros64(remaining)]]++
+				}
+			}
+		}
+	}
+	return counts
+}
+
+// AuthorRank is an author together with the reach of the versions they authored
+type AuthorRank struct {
+	Author   string
+	Packages int
+	Versions int
+	// Dependents is the number of versions of other authors' or their own packages that (transitively) depend on one
+	// of the author's versions
+	Dependents int
+}
+
+// GetAuthorRanking ranks the authors by the number of transitive dependents of their versions and returns the limit
+// highest ranked ones. The authors are read from the Author field of the nodes. A limit of zero or less returns every
+// author. The dependents of all authors are counted together in O((V+E) * authors/64), only authors with dependents
+// count.
+func GetAuthorRanking(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, limit int) *[]AuthorRank {
+	versions := make(map[string][]int64)
+	packages := make(map[string]map[string]bool)
+	for id, node := range nodeMap {
+		for _, author := range SplitAuthors(node.Author) {
+			versions[author] = append(versions[author], id)
+			if packages[author] == nil {
+				packages[author] = make(map[string]bool)
+			}
+			packages[author][node.Name] = true
+		}
+	}
+
+	names := make([]string, 0, len(versions))
+	sets := make([][]int64, 0, len(versions))
+	for author, ids := range versions {
+		names = append(names, author)
+		sets = append(sets, ids)
+	}
+	dependents := countSetDependents(g, nodeMap, sets)
+
+	result := make([]AuthorRank, 0, len(versions))
+	for i, author := range names {
+		result = append(result, AuthorRank{
+			Author:     author,
+			Packages:   len(packages[author]),
+			Versions:   len(sets[i]),
+			Dependents: dependents[i],
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Dependents != result[j].Dependents {
+			return result[i].Dependents > result[j].Dependents
+		}
+		return result[i].Author < result[j].Author
+	})
+	if limit > 0 && limit < len(result) {
+		result = result[:limit]
+	}
+	return &result
+}
+
+// CriticalPackage is a package that only one author maintains while other versions depend on it
+type CriticalPackage struct {
+	Name       string
+	Author     string
+	Versions   int
+	Dependents int
+}
+
+// GetCriticalPackages returns the packages whose versions name exactly one author between them and that have dependents,
+// ordered by the number of transitive dependents. Their whole downstream reach rests on that author, the bus factor
+// of the package is one. A limit of zero or less returns every such package. Like in GetAuthorRanking the dependents
+// of all those packages are counted together.
+func GetCriticalPackages(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, limit int) *[]CriticalPackage {
+	versions := make(map[string][]int64)
+	packageAuthors := make(map[string]map[string]bool)
+	for id, node := range nodeMap {
+		versions[node.Name] = append(versions[node.Name], id)
+		if packageAuthors[node.Name] == nil {
+			packageAuthors[node.Name] = make(map[string]bool)
+		}
+		for _, author := range SplitAuthors(node.Author) {
+			packageAuthors[node.Name][author] = true
+		}
+	}
+
+	var names []string
+	var sets [][]int64
+	for name, ids := range versions {
+		if len(packageAuthors[name]) == 1 {
+			names = append(names, name)
+			sets = append(sets, ids)
+		}
+	}
+	dependents := countSetDependents(g, nodeMap, sets)
+
+	result := make([]CriticalPackage, 0)
+	for i, name := range names {
+		if dependents[i] == 0 {
+			continue
+		}
+		critical := CriticalPackage{Name: name, Versions: len(sets[i]), Dependents: dependents[i]}
+		for author := range packageAuthors[name] {
+			critical.Author = author
+		}
+		result = append(result, critical)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Dependents != result[j].Dependents {
+			return result[i].Dependents > result[j].Dependents
+		}
+		return result[i].Name < result[j].Name
+	})
+	if limit > 0 && limit < len(result) {
+		result = result[:limit]
+	}
+	return &result
+}
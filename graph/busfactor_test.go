@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	gonumgraph "gonum.org/v1/gonum/graph"
+)
+
+func TestSplitAuthors(t *testing.T) {
+	expected := []string{"Tecnativa", "Odoo Community Association (OCA)"}
+	if authors := SplitAuthors("Tecnativa, Odoo Community Association (OCA)"); !reflect.DeepEqual(authors, expected) {
+		t.Errorf("Expected %v, got %v", expected, authors)
+	}
+	if authors := SplitAuthors(" ; "); len(authors) != 0 {
+		t.Errorf("Expected no authors, got %v", authors)
+	}
+}
+
+func TestBusFactor(t *testing.T) {
+	graph, stringIDToNodeInfo, idToNodeInfo, _ := createQueryTestGraph()
+	authors := map[string]string{
+		"A@1.0.0": "alice",
+		"B@1.0.0": "bob",
+		"B@2.0.0": "bob, carol",
+		"C@1.0.0": "alice",
+	}
+	for stringID, author := range authors {
+		node := stringIDToNodeInfo[stringID]
+		node.Author = author
+		idToNodeInfo[node.id] = node
+	}
+
+	t.Run("Ranks the authors by the dependents of their versions", func(t *testing.T) {
+		ranking := *GetAuthorRanking(graph, idToNodeInfo, 0)
+		expected := []AuthorRank{
+			{Author: "alice", Packages: 2, Versions: 2, Dependents: 2},
+			{Author: "bob", Packages: 1, Versions: 2, Dependents: 1},
+			{Author: "carol", Packages: 1, Versions: 1, Dependents: 1},
+		}
+		if !reflect.DeepEqual(ranking, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, ranking)
+		}
+	})
+
+	t.Run("Finds the packages with a single author and dependents", func(t *testing.T) {
+		critical := *GetCriticalPackages(graph, idToNodeInfo, 0)
+		expected := []CriticalPackage{{Name: "A", Author: "alice", Versions: 1, Dependents: 3}}
+		if !reflect.DeepEqual(critical, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, critical)
+		}
+	})
+}
+
+func TestCountSetDependents(t *testing.T) {
+	t.Run("Counts the same dependents as a walk per set, also through cycles", func(t *testing.T) {
+		graph, stringIDToNodeInfo, idToNodeInfo := createCycleTestGraph()
+		var sets [][]int64
+		for _, names := range [][]string{{"A"}, {"B"}, {"D"}, {"E"}, {"A", "D"}, {"B", "E"}} {
+			var set []int64
+			for _, name := range names {
+				set = append(set, stringIDToNodeInfo[StringID(name, "1.0.0")].id)
+			}
+			sets = append(sets, set)
+		}
+		counts := countSetDependents(graph, idToNodeInfo, sets)
+		for i, set := range sets {
+			var nodes []gonumgraph.Node
+			for _, id := range set {
+				nodes = append(nodes, graph.Node(id))
+			}
+			if expected := countDependents(graph, nodes); counts[i] != expected {
+				t.Errorf("Expected %d dependents of %v, got %d", expected, set, counts[i])
+			}
+		}
+	})
+
+	t.Run("Counts more sets than fit in one word", func(t *testing.T) {
+		// P0 <- P1 <- ... <- P69, so Pi has 69-i dependents
+		var packagesInfo []PackageInfo
+		for i := 0; i < 70; i++ {
+			dependencies := map[string]string{}
+			if i > 0 {
+				dependencies[fmt.Sprintf("P%d", i-1)] = "1.0.0"
+			}
+			packagesInfo = append(packagesInfo, PackageInfo{Name: fmt.Sprintf("P%d", i), Versions: map[string]VersionInfo{
+				"1.0.0": {Timestamp: "2020-01-01T00:00:00", Dependencies: dependencies},
+			}})
+		}
+		graph, _, stringIDToNodeInfo, idToNodeInfo, _ := CreateGraphFromPackages(&packagesInfo, NPM)
+		sets := make([][]int64, 70)
+		for i := range sets {
+			sets[i] = []int64{stringIDToNodeInfo[StringID(fmt.Sprintf("P%d", i), "1.0.0")].id}
+		}
+		for i, count := range countSetDependents(graph, idToNodeInfo, sets) {
+			if count != 69-i {
+				t.Errorf("Expected %d dependents of P%d, got %d", 69-i, i, count)
+			}
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Granularity is the level at which the graph is analysed
+type Granularity string
+
+const (
+	// VersionGranularity has a node for every version of a package, this is the graph CreateGraph creates
+	VersionGranularity Granularity = "version"
+	// PackageGranularity has a node for every package, see CollapseToPackages
+	PackageGranularity Granularity = "package"
+)
+
+// ParseGranularity returns the granularity with the given name, an empty name means VersionGranularity
+func ParseGranularity(name string) (Granularity, error) {
+	switch Granularity(strings.ToLower(name)) {
+	case "", VersionGranularity:
+		return VersionGranularity, nil
+	case PackageGranularity:
+		return PackageGranularity, nil
+	}
+	return "", fmt.Errorf("unknown granularity %q, expected %s or %s", name, VersionGranularity, PackageGranularity)
+}
+
+// AllVersions is the version of the nodes of a package-level graph, every node stands for all versions of its package
+const AllVersions = "*"
+
+// PackageEdge is an edge of the package-level graph. It aggregates the edges between the versions of two packages.
+type PackageEdge struct {
+	F, T graph.Node
+	// Kind is the strongest kind of the version edges, a runtime dependency in one version makes it a runtime
+	// dependency of the package
+	Kind string
+	// Count is the number of version edges, Dependents the number of versions of the dependent package that depend
+	// on a version of the dependency
+	Count      int
+	Dependents int
+	// FirstSeen and LastSeen are the timestamps of the first and the last dependent version that depends on the
+	// dependency
+	FirstSeen string
+	LastSeen  string
+}
+
+func (e PackageEdge) From() graph.Node {
+	return e.F
+}
+
+func (e PackageEdge) To() graph.Node {
+	return e.T
+}
+
+func (e PackageEdge) ReversedEdge() graph.Edge {
+	e.F, e.T = e.T, e.F
+	return e
+}
+
+// Attributes labels the edge with the number of dependent versions and the time span when the graph is written in
+// the dot format
+func (e PackageEdge) Attributes() []encoding.Attribute {
+	return []encoding.Attribute{{
+		Key:   "label",
+		Value: fmt.Sprintf("%q", fmt.Sprintf("%d versions\n%s - %s", e.Dependents, e.FirstSeen, e.LastSeen)),
+	}}
+}
+
+// kindStrength orders the kinds of dependencies, when versions disagree the strongest kind is used for the package
+var kindStrength = map[string]int{
+	KindRuntime:  4,
+	KindPeer:     3,
+	KindOptional: 2,
+	KindBuild:    1,
+	KindDev:      0,
+}
+
+// CollapseToPackages derives the package-level graph from a version-level graph. Every package becomes one node
+// with version AllVersions and the timestamp of its first version, and every pair of packages whose versions depend
+// on each other gets one PackageEdge. Dependencies between versions of the same package are left out. The maps have
+// the same shape as the ones CreateGraph returns, so every query works on both graphs.
+func CollapseToPackages(versionGraph *simple.DirectedGraph, nodeMap map[int64]NodeInfo) (*simple.DirectedGraph, map[string]NodeInfo, map[int64]NodeInfo, map[string][]string) {
+	// Every node of a graph is in the same ecosystem, the package nodes are in it as well
+	var ecosystem Ecosystem
+	firstVersion := make(map[string]NodeInfo)
+	for _, node := range nodeMap {
+		ecosystem = node.key.Ecosystem
+		first, ok := firstVersion[node.Name]
+		if !ok || earlier(node.Timestamp, first.Timestamp) {
+			firstVersion[node.Name] = node
+		}
+	}
+	// Create the nodes in name order so the ids don't depend on the order of the map
+	names := make([]string, 0, len(firstVersion))
+	for name := range firstVersion {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	packageGraph := simple.NewDirectedGraph()
+	stringIDToNodeInfo := make(map[string]NodeInfo, len(names))
+	nameToVersions := make(map[string][]string, len(names))
+	for _, name := range names {
+		newNode := packageGraph.NewNode()
+		packageGraph.AddNode(newNode)
+		stringIDToNodeInfo[StringID(name, AllVersions)] = *NewNodeInfo(newNode.ID(), ecosystem, name, AllVersions, firstVersion[name].Timestamp)
+		nameToVersions[name] = []string{AllVersions}
+	}
+
+	edges := make(map[edgeKey]*PackageEdge)
+	dependents := make(map[edgeKey]map[int64]bool)
+	versionEdges := versionGraph.Edges()
+	for versionEdges.Next() {
+		versionEdge := versionEdges.Edge()
+		dependent := nodeMap[versionEdge.From().ID()]
+		dependency := nodeMap[versionEdge.To().ID()]
+		if dependent.Name == dependency.Name {
+			continue
+		}
+		key := edgeKey{
+			from: stringIDToNodeInfo[StringID(dependent.Name, AllVersions)].id,
+			to:   stringIDToNodeInfo[StringID(dependency.Name, AllVersions)].id,
+		}
+		edge, ok := edges[key]
+		if !ok {
+			edge = &PackageEdge{
+				F:         simple.Node(key.from),
+				T:         simple.Node(key.to),
+				Kind:      EdgeKind(versionEdge),
+				FirstSeen: dependent.Timestamp,
+				LastSeen:  dependent.Timestamp,
+			}
+			edges[key] = edge
+			dependents[key] = make(map[int64]bool)
+		}
+		edge.Count++
+		dependents[key][dependent.id] = true
+		if kind := EdgeKind(versionEdge); kindStrength[kind] > kindStrength[edge.Kind] {
+			edge.Kind = kind
+		}
+		if earlier(dependent.Timestamp, edge.FirstSeen) {
+			edge.FirstSeen = dependent.Timestamp
+		}
+		if earlier(edge.LastSeen, dependent.Timestamp) {
+			edge.LastSeen = dependent.Timestamp
+		}
+	}
+	for key, edge := range edges {
+		edge.Dependents = len(dependents[key])
+		packageGraph.SetEdge(*edge)
+	}
+
+	return packageGraph, stringIDToNodeInfo, CreateNodeIdToPackageMap(stringIDToNodeInfo), nameToVersions
+}
+
+// earlier reports whether timestamp a lies before timestamp b. Timestamps that can't be parsed are later than all
+// others, so they are only used when nothing better is known.
+func earlier(a, b string) bool {
+	ta, errA := ParseTimestamp(a)
+	tb, errB := ParseTimestamp(b)
+	switch {
+	case errA != nil:
+		return false
+	case errB != nil:
+		return true
+	}
+	return ta.Before(tb)
+}
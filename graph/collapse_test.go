@@ -0,0 +1,36 @@
+package graph
+
+import "testing"
+
+func TestCollapseToPackages(t *testing.T) {
+	graph, _, idToNodeInfo, _ := createQueryTestGraph()
+	packageGraph, stringIDToNodeInfo, packageIdToNodeInfo, nameToVersions := CollapseToPackages(graph, idToNodeInfo)
+
+	if packageGraph.Nodes().Len() != 3 || packageGraph.Edges().Len() != 2 || len(nameToVersions) != 3 {
+		t.Fatalf("Expected 3 packages and 2 edges, got %d and %d", packageGraph.Nodes().Len(), packageGraph.Edges().Len())
+	}
+	b := stringIDToNodeInfo["B@*"]
+	if b.Version != AllVersions || b.Timestamp != "2021-01-01T00:00:00" {
+		t.Errorf("Expected B to have the timestamp of its first version, got %v", b)
+	}
+
+	t.Run("Aggregates the edges between the versions", func(t *testing.T) {
+		edge, ok := packageGraph.Edge(b.id, stringIDToNodeInfo["A@*"].id).(PackageEdge)
+		if !ok {
+			t.Fatal("Expected a package edge from B to A")
+		}
+		if edge.Count != 2 || edge.Dependents != 2 || edge.FirstSeen != "2021-01-01T00:00:00" || edge.LastSeen != "2021-06-01T00:00:00" {
+			t.Errorf("Expected both versions of B to depend on A between 2021-01-01 and 2021-06-01, got %+v", edge)
+		}
+	})
+
+	t.Run("Works with the version-level queries", func(t *testing.T) {
+		dependencies := *GetTransitiveDependenciesNode(packageGraph, packageIdToNodeInfo, stringIDToNodeInfo, "C@*")
+		if len(dependencies) != 3 {
+			t.Errorf("Expected C and its 2 dependencies, got %v", dependencies)
+		}
+		if node, err := LookupNode(stringIDToNodeInfo, NPM, "pkg:npm/B"); err != nil || node.Name != "B" {
+			t.Errorf("Expected the package URL without version to find B, got %v (%v)", node, err)
+		}
+	})
+}
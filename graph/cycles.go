@@ -0,0 +1,157 @@
+package graph
+
+import (
+	"sort"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/simple"
+	"gonum.org/v1/gonum/graph/topo"
+	"gonum.org/v1/gonum/graph/traverse"
+)
+
+// Cycle is a strongly connected component of more than one node: every member depends on every other member,
+// directly or transitively. CreateEdges leaves out self-dependencies, so a single node is never a cycle.
+type Cycle struct {
+	// Nodes are the members ordered by string id, Edges the number of dependencies between them
+	Nodes []NodeInfo
+	Edges int
+	// Example is one of the shortest cycles through the first member, it starts and ends with that member
+	Example []NodeInfo
+}
+
+// GetCycles finds the strongly connected components of the graph with more than one node. The largest cycles come
+// first, cycles of the same size are ordered by their first member. It works on both the version-level and the
+// package-level graph.
+func GetCycles(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo) *[]Cycle {
+	result := make([]Cycle, 0)
+	for _, component := range topo.TarjanSCC(g) {
+		if len(component) < 2 {
+			continue
+		}
+		members := make(map[int64]bool, len(component))
+		cycle := Cycle{Nodes: make([]NodeInfo, len(component))}
+		for i, node := range component {
+			members[node.ID()] = true
+			cycle.Nodes[i] = nodeMap[node.ID()]
+		}
+		sortNodesByStringID(cycle.Nodes)
+		for _, node := range component {
+			dependencies := g.From(node.ID())
+			for dependencies.Next() {
+				if members[dependencies.Node().ID()] {
+					cycle.Edges++
+				}
+			}
+		}
+		cycle.Example = shortestCycle(g, nodeMap, members, cycle.Nodes[0].id)
+		result = append(result, cycle)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i].Nodes) != len(result[j].Nodes) {
+			return len(result[i].Nodes) > len(result[j].Nodes)
+		}
+		return result[i].Nodes[0].stringID < result[j].Nodes[0].stringID
+	})
+	return &result
+}
+
+// shortestCycle walks breadth first from the start through the members of its component until it reaches a member
+// that depends on the start again
+func shortestCycle(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, members map[int64]bool, startId int64) []NodeInfo {
+	parents := make(map[int64]int64)
+	w := traverse.BreadthFirst{
+		Traverse: func(e graph.Edge) bool {
+			if !members[e.To().ID()] {
+				return false
+			}
+			if _, ok := parents[e.To().ID()]; !ok && e.To().ID() != startId {
+				parents[e.To().ID()] = e.From().ID()
+			}
+			return true
+		},
+	}
+	last := w.Walk(g, g.Node(startId), func(n graph.Node, _ int) bool {
+		return g.HasEdgeFromTo(n.ID(), startId)
+	})
+	if last == nil {
+		return nil
+	}
+	result := []NodeInfo{nodeMap[startId]}
+	for id := last.ID(); id != startId; id = parents[id] {
+		result = append(result, nodeMap[id])
+	}
+	result = append(result, nodeMap[startId])
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+func sortNodesByStringID(nodes []NodeInfo) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].stringID < nodes[j].stringID
+	})
+}
+
+// Condensation is the graph in which every strongly connected component is replaced by a single node. It has no
+// cycles, so algorithms that need a DAG, like a topological order, can run on it.
+type Condensation struct {
+	Graph *simple.DirectedGraph
+	// Components maps the nodes of the condensation to the members of their component, ordered by string id
+	Components map[int64][]NodeInfo
+	// ComponentOf maps the nodes of the original graph to the node of their component
+	ComponentOf map[int64]int64
+}
+
+// Condense creates the condensation of the graph. The dependencies between members of different components become
+// one edge between the components.
+func Condense(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo) *Condensation {
+	condensation := &Condensation{
+		Graph:       simple.NewDirectedGraph(),
+		Components:  make(map[int64][]NodeInfo),
+		ComponentOf: make(map[int64]int64, len(nodeMap)),
+	}
+	var components [][]NodeInfo
+	for _, component := range topo.TarjanSCC(g) {
+		members := make([]NodeInfo, len(component))
+		for i, member := range component {
+			members[i] = nodeMap[member.ID()]
+		}
+		sortNodesByStringID(members)
+		components = append(components, members)
+	}
+	// The components are numbered in the order of their first member, so the ids don't depend on the order of the map
+	// the graph stores its nodes in
+	sort.Slice(components, func(i, j int) bool {
+		return components[i][0].stringID < components[j][0].stringID
+	})
+	for _, members := range components {
+		node := condensation.Graph.NewNode()
+		condensation.Graph.AddNode(node)
+		condensation.Components[node.ID()] = members
+		for _, member := range members {
+			condensation.ComponentOf[member.id] = node.ID()
+		}
+	}
+	edges := g.Edges()
+	for edges.Next() {
+		from := condensation.ComponentOf[edges.Edge().From().ID()]
+		to := condensation.ComponentOf[edges.Edge().To().ID()]
+		if from != to {
+			condensation.Graph.SetEdge(simple.Edge{F: simple.Node(from), T: simple.Node(to)})
+		}
+	}
+	return condensation
+}
+
+// TopologicalOrder returns the components so that every component comes after the components it depends on, the
+// order in which the packages could be built. The order of components that don't depend on each other is stable.
+func (condensation *Condensation) TopologicalOrder() [][]NodeInfo {
+	// The condensation has no cycles, so sorting can't fail. The sort puts dependents first, hence the reverse.
+	sorted, _ := topo.SortStabilized(condensation.Graph, nil)
+	result := make([][]NodeInfo, len(sorted))
+	for i, node := range sorted {
+		result[len(sorted)-1-i] = condensation.Components[node.ID()]
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// createCycleTestGraph creates the cycle A -> B -> C -> A, where C also depends on D and E depends on A
+func createCycleTestGraph() (*simple.DirectedGraph, map[string]NodeInfo, map[int64]NodeInfo) {
+	dependsOn := func(names ...string) map[string]VersionInfo {
+		dependencies := make(map[string]string)
+		for _, name := range names {
+			dependencies[name] = "1.0.0"
+		}
+		return map[string]VersionInfo{"1.0.0": {Timestamp: "2020-01-01T00:00:00", Dependencies: dependencies}}
+	}
+	packagesInfo := []PackageInfo{
+		{Name: "A", Versions: dependsOn("B")},
+		{Name: "B", Versions: dependsOn("C")},
+		{Name: "C", Versions: dependsOn("A", "D")},
+		{Name: "D", Versions: dependsOn()},
+		{Name: "E", Versions: dependsOn("A")},
+	}
+	graph, _, stringIDToNodeInfo, idToNodeInfo, _ := CreateGraphFromPackages(&packagesInfo, NPM)
+	return graph, stringIDToNodeInfo, idToNodeInfo
+}
+
+func TestGetCycles(t *testing.T) {
+	graph, _, idToNodeInfo := createCycleTestGraph()
+	cycles := *GetCycles(graph, idToNodeInfo)
+	if len(cycles) != 1 {
+		t.Fatalf("Expected 1 cycle, got %v", cycles)
+	}
+	cycle := cycles[0]
+	if len(cycle.Nodes) != 3 || cycle.Nodes[0].Name != "A" || cycle.Nodes[2].Name != "C" || cycle.Edges != 3 {
+		t.Errorf("Expected A, B and C with 3 edges, got %+v", cycle)
+	}
+	if len(cycle.Example) != 4 || cycle.Example[0].Name != "A" || cycle.Example[1].Name != "B" || cycle.Example[3].Name != "A" {
+		t.Errorf("Expected the example A -> B -> C -> A, got %v", cycle.Example)
+	}
+
+	t.Run("Finds cycles in the package-level graph", func(t *testing.T) {
+		packageGraph, _, packageIdToNodeInfo, _ := CollapseToPackages(graph, idToNodeInfo)
+		if cycles := *GetCycles(packageGraph, packageIdToNodeInfo); len(cycles) != 1 || len(cycles[0].Nodes) != 3 {
+			t.Errorf("Expected the same cycle between the packages, got %v", cycles)
+		}
+	})
+}
+
+func TestCondense(t *testing.T) {
+	graph, stringIDToNodeInfo, idToNodeInfo := createCycleTestGraph()
+	condensation := Condense(graph, idToNodeInfo)
+	if condensation.Graph.Nodes().Len() != 3 || condensation.Graph.Edges().Len() != 2 {
+		t.Fatalf("Expected the components E, ABC and D with 2 edges, got %d and %d", condensation.Graph.Nodes().Len(), condensation.Graph.Edges().Len())
+	}
+	if condensation.ComponentOf[stringIDToNodeInfo["A@1.0.0"].id] != condensation.ComponentOf[stringIDToNodeInfo["C@1.0.0"].id] {
+		t.Error("Expected A and C to be in the same component")
+	}
+
+	order := condensation.TopologicalOrder()
+	if len(order) != 3 || order[0][0].Name != "D" || len(order[1]) != 3 || order[2][0].Name != "E" {
+		t.Errorf("Expected D, then the cycle and then E, got %v", order)
+	}
+}
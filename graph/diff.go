@@ -0,0 +1,152 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// ChangeKind is what happened to a dependency between two versions of a package
+type ChangeKind string
+
+const (
+	Added      ChangeKind = "added"
+	Removed    ChangeKind = "removed"
+	Upgraded   ChangeKind = "upgraded"
+	Downgraded ChangeKind = "downgraded"
+)
+
+// VersionChange classifies the difference between two versions by the most significant part that changed
+type VersionChange string
+
+const (
+	MajorChange VersionChange = "major"
+	MinorChange VersionChange = "minor"
+	PatchChange VersionChange = "patch"
+	// OtherChange is a difference in the pre-release, the build metadata or a part after the patch version
+	OtherChange VersionChange = "other"
+)
+
+// ClassifyChange returns the most significant part in which the versions differ. Versions that are not semantic
+// versions are compared part by part, the first three numbers count as the major, minor and patch version.
+func ClassifyChange(from, to string) VersionChange {
+	v1, err1 := semver.NewVersion(from)
+	v2, err2 := semver.NewVersion(to)
+	if err1 == nil && err2 == nil {
+		switch {
+		case v1.Major() != v2.Major():
+			return MajorChange
+		case v1.Minor() != v2.Minor():
+			return MinorChange
+		case v1.Patch() != v2.Patch():
+			return PatchChange
+		}
+		return OtherChange
+	}
+	t1, t2 := versionTokens(from), versionTokens(to)
+	for i := 0; i < len(t1) || i < len(t2); i++ {
+		if tokenAt(t1, i) == tokenAt(t2, i) {
+			continue
+		}
+		switch {
+		case i == 0:
+			return MajorChange
+		case i == 1:
+			return MinorChange
+		case i == 2:
+			return PatchChange
+		}
+		return OtherChange
+	}
+	return OtherChange
+}
+
+// DependencyChange is a package whose resolved version differs between two versions of a dependent. From is empty for
+// added packages and To for removed ones, Change is only set for upgrades and downgrades.
+type DependencyChange struct {
+	Name   string
+	Kind   ChangeKind
+	From   string
+	To     string
+	Change VersionChange
+}
+
+// DiffDependencies compares the resolved transitive dependencies of two versions, usually two versions of the same
+// package. Both are resolved with ResolveTree at the given moment, or at the moment they were published when it is
+// zero. When a package is resolved to several versions in one tree the highest one is compared. The changes are
+// ordered by package name, the packages that resolved to the same version are left out. It returns an error wrapping
+// ErrNodeNotFound when one of the versions is not in the graph, and an error when a version has to be resolved at its
+// publish timestamp but that can't be parsed.
+func DiffDependencies(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, ecosystem Ecosystem, fromStringId, toStringId string, at time.Time) (*[]DependencyChange, error) {
+	from, ok := stringMap[fromStringId]
+	if !ok {
+		return nil, fmt.Errorf("version %s was %w", fromStringId, ErrNodeNotFound)
+	}
+	to, ok := stringMap[toStringId]
+	if !ok {
+		return nil, fmt.Errorf("version %s was %w", toStringId, ErrNodeNotFound)
+	}
+	before, err := resolvedVersions(g, nodeMap, ecosystem, from, at)
+	if err != nil {
+		return nil, err
+	}
+	after, err := resolvedVersions(g, nodeMap, ecosystem, to, at)
+	if err != nil {
+		return nil, err
+	}
+	// The packages themselves are not their own dependencies
+	delete(before, from.Name)
+	delete(after, to.Name)
+
+	result := make([]DependencyChange, 0)
+
+	for name, oldVersion := range before {
+		newVersion, ok := after[name]
+		if !ok {
+			result = append(result, DependencyChange{Name: name, Kind: Removed, From: oldVersion})
+			continue
+		}
+		change := DependencyChange{Name: name, From: oldVersion, To: newVersion}
+		switch c := CompareVersions(ecosystem, oldVersion, newVersion); {
+		case c < 0:
+			change.Kind = Upgraded
+		case c > 0:
+			change.Kind = Downgraded
+		default:
+			continue
+		}
+		change.Change = ClassifyChange(oldVersion, newVersion)
+		result = append(result, change)
+	}
+	for name, newVersion := range after {
+		if _, ok := before[name]; !ok {
+			result = append(result, DependencyChange{Name: name, Kind: Added, To: newVersion})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return &result, nil
+}
+
+// resolvedVersions resolves the dependency tree of the node and returns the highest version of every package in it
+func resolvedVersions(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, ecosystem Ecosystem, node NodeInfo, at time.Time) (map[string]string, error) {
+	if at.IsZero() {
+		published, err := ParseTimestamp(node.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("version %s has no valid publish timestamp to resolve it at: %w", StringID(node.Name, node.Version), err)
+		}
+		at = published
+	}
+	result := make(map[string]string)
+	for _, dependency := range ResolveTree(g, nodeMap, ecosystem, node, at) {
+		current, ok := result[dependency.Node.Name]
+		if !ok || CompareVersions(ecosystem, dependency.Node.Version, current) > 0 {
+			result[dependency.Node.Name] = dependency.Node.Version
+		}
+	}
+	return result, nil
+}
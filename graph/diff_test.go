@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClassifyChange(t *testing.T) {
+	for _, test := range []struct {
+		from, to string
+		want     VersionChange
+	}{
+		{"1.2.3", "2.0.0", MajorChange},
+		{"1.2.3", "1.4.0", MinorChange},
+		{"1.2.3", "1.2.2", PatchChange},
+		{"1.0.0-beta", "1.0.0", OtherChange},
+		{"1.0.0.1", "1.0.0.2", OtherChange},
+		{"1.0.0.1", "1.1.0.1", MinorChange},
+		{"5.final", "6.final", MajorChange},
+	} {
+		if got := ClassifyChange(test.from, test.to); got != test.want {
+			t.Errorf("Expected %s -> %s to be a %s change, got %s", test.from, test.to, test.want, got)
+		}
+	}
+}
+
+func TestDiffDependencies(t *testing.T) {
+	packagesInfo := []PackageInfo{
+		{Name: "X", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-01-01T00:00:00", Dependencies: map[string]string{"A": "^1.0.0", "B": "^2.0.0", "C": "~1.2.0"}},
+			"2.0.0": {Timestamp: "2022-01-01T00:00:00", Dependencies: map[string]string{"A": "^2.0.0", "C": "~1.1.0", "D": "^1.0.0"}},
+		}},
+		{Name: "A", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2020-01-01T00:00:00"},
+			"2.0.0": {Timestamp: "2021-06-01T00:00:00", Dependencies: map[string]string{"E": "^1.0.0"}},
+		}},
+		{Name: "B", Versions: map[string]VersionInfo{
+			"2.0.0": {Timestamp: "2020-01-01T00:00:00"},
+		}},
+		{Name: "C", Versions: map[string]VersionInfo{
+			"1.1.0": {Timestamp: "2020-01-01T00:00:00"},
+			"1.2.0": {Timestamp: "2020-02-01T00:00:00"},
+		}},
+		{Name: "D", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2020-01-01T00:00:00"},
+		}},
+		{Name: "E", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2020-01-01T00:00:00"},
+			"1.0.1": {Timestamp: "2023-01-01T00:00:00"},
+		}},
+	}
+	graph, _, stringIDToNodeInfo, idToNodeInfo, _ := CreateGraphFromPackages(&packagesInfo, NPM)
+
+	t.Run("Resolves every version when it was published", func(t *testing.T) {
+		changes, err := DiffDependencies(graph, idToNodeInfo, stringIDToNodeInfo, NPM, "X@1.0.0", "X@2.0.0", time.Time{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		got := *changes
+		want := []DependencyChange{
+			{Name: "A", Kind: Upgraded, From: "1.0.0", To: "2.0.0", Change: MajorChange},
+			{Name: "B", Kind: Removed, From: "2.0.0"},
+			{Name: "C", Kind: Downgraded, From: "1.2.0", To: "1.1.0", Change: MinorChange},
+			{Name: "D", Kind: Added, To: "1.0.0"},
+			{Name: "E", Kind: Added, To: "1.0.0"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("Resolves both versions at the same moment", func(t *testing.T) {
+		at := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+		changes, err := DiffDependencies(graph, idToNodeInfo, stringIDToNodeInfo, NPM, "X@1.0.0", "X@2.0.0", at)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got := *changes; len(got) != 5 || got[4].To != "1.0.1" {
+			t.Errorf("Expected E@1.0.1 to be added, got %+v", got)
+		}
+	})
+
+	t.Run("Returns an error for unknown versions", func(t *testing.T) {
+		if _, err := DiffDependencies(graph, idToNodeInfo, stringIDToNodeInfo, NPM, "X@1.0.0", "X@3.0.0", time.Time{}); !errors.Is(err, ErrNodeNotFound) {
+			t.Errorf("Expected an error wrapping ErrNodeNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Returns an error for versions without a valid publish timestamp", func(t *testing.T) {
+		broken := stringIDToNodeInfo["X@1.0.0"]
+		broken.Timestamp = "yesterday"
+		brokenMap := map[string]NodeInfo{"X@1.0.0": broken, "X@2.0.0": stringIDToNodeInfo["X@2.0.0"]}
+		if _, err := DiffDependencies(graph, idToNodeInfo, brokenMap, NPM, "X@1.0.0", "X@2.0.0", time.Time{}); err == nil {
+			t.Error("Expected an error for the timestamp that can't be parsed")
+		}
+		if _, err := DiffDependencies(graph, idToNodeInfo, brokenMap, NPM, "X@1.0.0", "X@2.0.0", time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+			t.Errorf("Expected no error when the moment is given, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package graph
+
+import (
+	"sort"
+	"time"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/simple"
+	"gonum.org/v1/gonum/graph/traverse"
+)
+
+// EvolutionOptions describes the windows GetEvolution slides over the publish timestamps
+type EvolutionOptions struct {
+	// Window is the length of every window and Step the time between the beginnings of consecutive windows. With
+	// Cumulative every window starts at the beginning of the series, so it holds everything published up to its end.
+	Window     Period
+	Step       Period
+	Cumulative bool
+	// Top is the number of most central versions that are reported per window. They are ranked with the sparse
+	// PageRank of GetMostUsedNodes, which takes time in proportion to the edges of the window, so with Cumulative the
+	// last windows cost about as much as ranking the whole graph. Zero or less skips the ranking.
+	Top int
+	// Samples limits the number of versions whose transitive closure is computed per window, zero or less computes it
+	// for every version. The sample is spread evenly over the versions ordered by id.
+	Samples int
+}
+
+// EvolutionPoint holds the statistics of the graph of the versions published in one window
+type EvolutionPoint struct {
+	Begin time.Time
+	End   time.Time
+	Stats Stats
+	// MeanClosure is the mean number of transitive dependencies of a version
+	MeanClosure float64
+	// Top are the versions with the highest PageRank in the window
+	Top []RankedNode
+	// Cycles is the number of dependency cycles and CycleNodes the number of versions in them. They are counted with
+	// all dependencies between the versions of the window, a cycle can't respect the publish order.
+	Cycles     int
+	CycleNodes int
+}
+
+// GetEvolution computes the statistics of the graph for every window between begin and end. A window includes its
+// beginning but not its end, so windows that follow each other don't share versions. The graph of a window holds the
+// versions published in it and the dependencies between them that could have been used: the dependency was
+// published before the dependent, like in FilterGraph. Only the cycles are found in the graph with all dependencies
+// between the versions published in the window.
+func GetEvolution(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, begin, end time.Time, options EvolutionOptions) []EvolutionPoint {
+	published := make(map[int64]time.Time, len(nodeMap))
+	for id, node := range nodeMap {
+		if t, err := ParseTimestamp(node.Timestamp); err == nil {
+			published[id] = t
+		}
+	}
+
+	var result []EvolutionPoint
+	for windowBegin := begin; windowBegin.Before(end); windowBegin = options.Step.After(windowBegin) {
+		point := EvolutionPoint{Begin: windowBegin, End: options.Window.After(windowBegin)}
+		if options.Cumulative {
+			point.Begin = begin
+			point.End = options.Step.After(windowBegin)
+		}
+		if point.End.After(end) {
+			point.End = end
+		}
+		window, induced, nameToVersions := graphInWindow(g, nodeMap, published, point.Begin, point.End)
+		point.Stats = ComputeStats(window, nameToVersions)
+		point.MeanClosure = meanClosure(window, options.Samples)
+		if options.Top > 0 && window.Nodes().Len() > 0 {
+			point.Top = *GetMostUsedNodes(window, nodeMap, options.Top)
+		}
+		for _, cycle := range *GetCycles(induced, nodeMap) {
+			point.Cycles++
+			point.CycleNodes += len(cycle.Nodes)
+		}
+		result = append(result, point)
+	}
+	return result
+}
+
+// graphInWindow creates the graphs of the versions published in [begin, end) with the ids of the original graph, and
+// the versions of every package in them. The window only holds the dependencies on versions published before the
+// dependent, induced holds all dependencies between the versions.
+func graphInWindow(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, published map[int64]time.Time, begin, end time.Time) (window *simple.DirectedGraph, induced *simple.DirectedGraph, nameToVersions map[string][]string) {
+	window, induced = simple.NewDirectedGraph(), simple.NewDirectedGraph()
+	nameToVersions = make(map[string][]string)
+	for id, t := range published {
+		if !t.Before(begin) && t.Before(end) {
+			window.AddNode(simple.Node(id))
+			induced.AddNode(simple.Node(id))
+			nameToVersions[nodeMap[id].Name] = append(nameToVersions[nodeMap[id].Name], nodeMap[id].Version)
+		}
+	}
+	edges := g.Edges()
+	for edges.Next() {
+		edge := edges.Edge()
+		from, to := edge.From().ID(), edge.To().ID()
+		if window.Node(from) == nil || window.Node(to) == nil {
+			continue
+		}
+		induced.SetEdge(edge)
+		if published[from].After(published[to]) {
+			window.SetEdge(edge)
+		}
+	}
+	return window, induced, nameToVersions
+}
+
+// meanClosure computes the mean number of transitive dependencies of the versions in the graph, or of an evenly
+// spread sample of them
+func meanClosure(g *simple.DirectedGraph, samples int) float64 {
+	var ids []int64
+	nodes := g.Nodes()
+	for nodes.Next() {
+		ids = append(ids, nodes.Node().ID())
+	}
+	if len(ids) == 0 {
+		return 0
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if samples > 0 && samples < len(ids) {
+		sampled := make([]int64, samples)
+		for i := range sampled {
+			sampled[i] = ids[i*len(ids)/samples]
+		}
+		ids = sampled
+	}
+
+	total := 0
+	w := traverse.BreadthFirst{
+		Visit: func(graph.Node) {
+			total++
+		},
+	}
+	for _, id := range ids {
+		w.Reset()
+		_ = w.Walk(g, g.Node(id), nil)
+		// The walk visits the version itself as well
+		total--
+	}
+	return float64(total) / float64(len(ids))
+}
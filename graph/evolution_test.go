@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetEvolution(t *testing.T) {
+	graph, _, idToNodeInfo, _ := createQueryTestGraph()
+	begin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := begin.AddDate(3, 0, 0)
+
+	t.Run("Slides the window over the publish timestamps", func(t *testing.T) {
+		points := GetEvolution(graph, idToNodeInfo, begin, end, EvolutionOptions{Window: Period{Years: 1}, Step: Period{Years: 1}, Top: 1})
+		if len(points) != 3 {
+			t.Fatalf("Expected 3 windows, got %d", len(points))
+		}
+		for i, nodes := range []int{1, 2, 1} {
+			if points[i].Stats.Nodes != nodes || points[i].Stats.Edges != 0 {
+				t.Errorf("Expected %d nodes and no edges in window %d, got %+v", nodes, i, points[i].Stats)
+			}
+		}
+	})
+
+	t.Run("Grows the graph when the windows are cumulative", func(t *testing.T) {
+		points := GetEvolution(graph, idToNodeInfo, begin, end, EvolutionOptions{Step: Period{Years: 1}, Cumulative: true, Top: 1})
+		last := points[len(points)-1]
+		if !last.Begin.Equal(begin) || last.Stats.Nodes != 4 || last.Stats.Edges != 4 || last.Stats.Packages != 3 {
+			t.Errorf("Expected the whole graph in the last window, got %+v", last)
+		}
+		if last.MeanClosure != 5.0/4 {
+			t.Errorf("Expected a mean closure of 5/4, got %v", last.MeanClosure)
+		}
+		if len(last.Top) != 1 || last.Top[0].Name != "A" || last.Cycles != 0 {
+			t.Errorf("Expected A to be the most central version and no cycles, got %+v", last)
+		}
+	})
+
+	t.Run("Counts the cycles with all dependencies of the window", func(t *testing.T) {
+		// All versions of the cycle graph are published at the same time, so none of its dependencies could have been
+		// used, but A, B and C still form a cycle
+		cycleGraph, _, cycleIdToNodeInfo := createCycleTestGraph()
+		points := GetEvolution(cycleGraph, cycleIdToNodeInfo, begin, end, EvolutionOptions{Window: Period{Years: 1}, Step: Period{Years: 1}})
+		if points[0].Stats.Edges != 0 || points[0].Cycles != 1 || points[0].CycleNodes != 3 {
+			t.Errorf("Expected no usable dependencies and a cycle of 3 versions in the first window, got %+v", points[0])
+		}
+		if points[1].Cycles != 0 {
+			t.Errorf("Expected no cycles in the empty second window, got %+v", points[1])
+		}
+	})
+}
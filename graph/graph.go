@@ -1,11 +1,14 @@
 package graph
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/semver"
@@ -18,6 +21,99 @@ import (
 type VersionInfo struct {
 	Timestamp    string            `json:"timestamp"`
 	Dependencies map[string]string `json:"dependencies"`
+	// DependencyKinds maps dependency names to their kind. Dependencies that are not listed are runtime dependencies.
+	DependencyKinds map[string]string `json:"dependencyKinds,omitempty"`
+	// License is the SPDX license expression the version is distributed under
+	License string `json:"license,omitempty"`
+	// Author is the author or maintainer of the version as the registry lists it, several are separated by commas
+	Author string `json:"author,omitempty"`
+	VersionStatus
+	ModuleDirectives
+}
+
+// VersionStatus tells whether a version can still be installed. Its fields are stored on the version in the input
+// and copied to the node of the version.
+type VersionStatus struct {
+	// Yanked tells whether the version was withdrawn from the registry. Package managers don't pick it anymore, but it
+	// can still be installed from a lock file. The registries don't record when it happened, so a yanked version is
+	// treated as if it was never available.
+	Yanked bool `json:"yanked,omitempty"`
+	// Deprecated is the message the maintainers deprecated the version with. Deprecated versions can be installed, but
+	// npm prefers a version that is not deprecated.
+	Deprecated string `json:"deprecated,omitempty"`
+	// Unpublished is the moment the version was removed from the registry, from then on it can't be installed at all
+	Unpublished string `json:"unpublished,omitempty"`
+}
+
+// AvailableAt tells whether a package manager could pick the version at the given moment: it was not yanked and not
+// unpublished yet. Whether it was already published is not checked.
+func (status VersionStatus) AvailableAt(t time.Time) bool {
+	if status.Yanked {
+		return false
+	}
+	if status.Unpublished == "" {
+		return true
+	}
+	unpublished, err := ParseTimestamp(status.Unpublished)
+	return err != nil || t.Before(unpublished)
+}
+
+// ModuleDirectives are the replace and exclude directives of the go.mod file of a Go module version. The dependencies
+// of the version are its requirements as written, the go command only applies the directives when the version is the
+// main module, see MinimalVersionSelection.
+type ModuleDirectives struct {
+	// Replace maps a module path, or the string id of one version of it, to the string id of its replacement. The
+	// replacement is empty when it is a local directory.
+	Replace map[string]string `json:"replace,omitempty"`
+	// Exclude holds the string ids of the excluded versions
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// The kinds of dependencies. Package managers that use other names for them are mapped onto these when ingesting.
+const (
+	KindRuntime  = "runtime"
+	KindDev      = "dev"
+	KindPeer     = "peer"
+	KindOptional = "optional"
+	KindBuild    = "build"
+)
+
+// DependencyKind returns the kind of the dependency with the given name
+func (versionInfo VersionInfo) DependencyKind(name string) string {
+	if kind, ok := versionInfo.DependencyKinds[name]; ok && kind != "" {
+		return kind
+	}
+	return KindRuntime
+}
+
+// DependencyEdge is the edge between a dependent and its dependency. It works like simple.Edge but also stores the
+// kind of the dependency.
+type DependencyEdge struct {
+	F, T graph.Node
+	Kind string
+}
+
+func (e DependencyEdge) From() graph.Node {
+	return e.F
+}
+
+func (e DependencyEdge) To() graph.Node {
+	return e.T
+}
+
+func (e DependencyEdge) ReversedEdge() graph.Edge {
+	return DependencyEdge{F: e.T, T: e.F, Kind: e.Kind}
+}
+
+// EdgeKind returns the kind of dependency an edge represents. Edges that don't store a kind are runtime dependencies.
+func EdgeKind(e graph.Edge) string {
+	switch e := e.(type) {
+	case DependencyEdge:
+		return e.Kind
+	case PackageEdge:
+		return e.Kind
+	}
+	return KindRuntime
 }
 
 type PackageInfo struct {
@@ -27,39 +123,75 @@ type PackageInfo struct {
 
 // NodeInfo is a type structure for nodes. Name and Version can be removed if we find we don't use them often enough
 type NodeInfo struct {
-	id        int64
-	stringID  string
+	id       int64
+	stringID string
+	// key identifies the version across graphs, Name and Version are the package name and version it was created from
+	key       NodeKey
 	Name      string
 	Version   string
 	Timestamp string
+	// Author is the author field of the version, SplitAuthors splits it into the authors it names
+	Author string
+	VersionStatus
 }
 
-// NewNodeInfo constructs a NodeInfo structure and automatically fills the stringID.
-func NewNodeInfo(id int64, name string, version string, timestamp string) *NodeInfo {
+// NewNodeInfo constructs a NodeInfo structure and automatically fills the stringID and the key of the node in the
+// ecosystem.
+func NewNodeInfo(id int64, ecosystem Ecosystem, name string, version string, timestamp string) *NodeInfo {
 	return &NodeInfo{
 		id:        id,
-		stringID:  fmt.Sprintf("%s-%s", name, version),
+		stringID:  StringID(name, version),
+		key:       NewNodeKey(ecosystem, name, version),
 		Name:      name,
 		Version:   version,
 		Timestamp: timestamp}
 }
 
+// StringID returns the key under which the version of a package is stored in the stringID to NodeInfo map. The name
+// and the version are separated by an @, which package names can only contain as the first character of an npm
+// scope, so different packages never get the same string id.
+func StringID(name string, version string) string {
+	return name + "@" + version
+}
+
+// SplitStringID splits a string id back into the name and the version. The boolean is false when it is not a string
+// id.
+func SplitStringID(stringID string) (string, string, bool) {
+	if stringID == "" {
+		return "", "", false
+	}
+	// Skip the @ of an npm scope
+	i := strings.Index(stringID[1:], "@") + 1
+	if i <= 0 || i == len(stringID)-1 {
+		return "", "", false
+	}
+	return stringID[:i], stringID[i+1:], true
+}
+
+// ID returns the ID of the node in the graph
+func (nodeInfo NodeInfo) ID() int64 {
+	return nodeInfo.id
+}
+
 func (nodeInfo NodeInfo) String() string {
 	return fmt.Sprintf("Package: %v - Version: %v", nodeInfo.Name, nodeInfo.Version)
 }
 
-// CreateStringIDToNodeInfoMap takes a list of PackageInfo and a simple.DirectedGraph. For each of the packages,
-// it creates a mapping of stringIDs to NodeInfo and also adds a node to the graph. The handling of the IDs is delegated
-// to Gonum. These IDs are also included in the mapping for ease of access.
-func CreateStringIDToNodeInfoMap(packagesInfo *[]PackageInfo, graph *simple.DirectedGraph) map[string]NodeInfo {
+// CreateStringIDToNodeInfoMap takes a list of PackageInfo of an ecosystem and a simple.DirectedGraph. For each of the
+// packages, it creates a mapping of stringIDs to NodeInfo and also adds a node to the graph. The handling of the IDs is
+// delegated to Gonum. These IDs are also included in the mapping for ease of access.
+func CreateStringIDToNodeInfoMap(packagesInfo *[]PackageInfo, graph *simple.DirectedGraph, ecosystem Ecosystem) map[string]NodeInfo {
 	stringIDToNodeInfoMap := make(map[string]NodeInfo, len(*packagesInfo))
 	for _, packageInfo := range *packagesInfo {
 		for packageVersion, versionInfo := range packageInfo.Versions {
-			packageNameVersionString := fmt.Sprintf("%s-%s", packageInfo.Name, packageVersion)
+			packageNameVersionString := StringID(packageInfo.Name, packageVersion)
 			// Delegate the work of creating a unique ID to Gonum
 			newNode := graph.NewNode()
 			newId := newNode.ID()
-			stringIDToNodeInfoMap[packageNameVersionString] = *NewNodeInfo(newId, packageInfo.Name, packageVersion, versionInfo.Timestamp)
+			nodeInfo := *NewNodeInfo(newId, ecosystem, packageInfo.Name, packageVersion, versionInfo.Timestamp)
+			nodeInfo.Author = versionInfo.Author
+			nodeInfo.VersionStatus = versionInfo.VersionStatus
+			stringIDToNodeInfoMap[packageNameVersionString] = nodeInfo
 			// idToNodeInfo[newId] =
 			graph.AddNode(newNode)
 		}
@@ -121,6 +253,11 @@ func VisualizationNodeInfo(iDToNodeInfo *map[string]NodeInfo, graph *simple.Dire
 	}
 
 	for edgIt.Next() {
+		// Package edges are labelled with the number of dependent versions and the time span
+		if packageEdge, ok := edgIt.Edge().(PackageEdge); ok {
+			fmt.Fprintf(file, "%d -> %d [label = \"%d versions \\n %s - %s\"];\n", packageEdge.F.ID(), packageEdge.T.ID(), packageEdge.Dependents, packageEdge.FirstSeen, packageEdge.LastSeen)
+			continue
+		}
 		fmt.Fprintf(file, fmt.Sprint(edgIt.Edge().From().ID())+" -> "+fmt.Sprint(edgIt.Edge().To().ID())+";\n")
 	}
 
@@ -134,46 +271,59 @@ func VisualizationNodeInfo(iDToNodeInfo *map[string]NodeInfo, graph *simple.Dire
 
 }
 
+// mavenRangeRegexp matches a Maven version range or a single version
+var mavenRangeRegexp = regexp.MustCompile("((?P<open>[\\(\\[])(?P<bothVer>((?P<firstVer>(0|[1-9]+)(\\.(0|[1-9]+)(\\.(0|[1-9]+))?)?)(?P<comma1>,)(?P<secondVer1>(0|[1-9]+)(\\.(0|[1-9]+)(\\.(0|[1-9]+))?)?)?)|((?P<comma2>,)?(?P<secondVer2>(0|[1-9]+)(\\.(0|[1-9]+)(\\.(0|[1-9]+))?)?)?))(?P<close>[\\)\\]]))|(?P<simplevers>(0|[1-9]+)(\\.(0|[1-9]+)(\\.(0|[1-9]+))?)?)")
+
+// parseConstraint parses the version constraint of a dependency. Maven ranges are translated to semver constraints
+// first.
+func parseConstraint(constraint string, isMaven bool) (*semver.Constraints, error) {
+	if isMaven {
+		constraint = parseMultipleMavenSemVers(constraint, mavenRangeRegexp)
+	}
+	return semver.NewConstraint(constraint)
+}
+
 // CreateEdges takes a graph, a list of packages and their dependencies, a map of stringIDs to NodeInfo and
 // a map of names to versions and creates directed edges between the dependent library and its dependencies.
 // TODO: add documentation on how we use semver for edges
 // TODO: Discuss removing pointers from maps since they are reference types without the need of using * : https://stackoverflow.com/questions/40680981/are-maps-passed-by-value-or-by-reference-in-go
 func CreateEdges(graph *simple.DirectedGraph, inputList *[]PackageInfo, stringIDToNodeInfo map[string]NodeInfo, nameToVersionMap map[string][]string, isMaven bool) {
-	r, _ := regexp.Compile("((?P<open>[\\(\\[])(?P<bothVer>((?P<firstVer>(0|[1-9]+)(\\.(0|[1-9]+)(\\.(0|[1-9]+))?)?)(?P<comma1>,)(?P<secondVer1>(0|[1-9]+)(\\.(0|[1-9]+)(\\.(0|[1-9]+))?)?)?)|((?P<comma2>,)?(?P<secondVer2>(0|[1-9]+)(\\.(0|[1-9]+)(\\.(0|[1-9]+))?)?)?))(?P<close>[\\)\\]]))|(?P<simplevers>(0|[1-9]+)(\\.(0|[1-9]+)(\\.(0|[1-9]+))?)?)")
-	for id, packageInfo := range *inputList {
-		for _, dependencyInfo := range packageInfo.Versions {
-			for dependencyName, dependencyVersion := range dependencyInfo.Dependencies {
-				finaldep := dependencyVersion
-				if isMaven {
-					finaldep = parseMultipleMavenSemVers(dependencyVersion, r)
-				}
-				constraint, err := semver.NewConstraint(finaldep)
-				//c, err := semver2.ParseRange(dependencyVersion)
+	for _, packageInfo := range *inputList {
+		createPackageEdges(graph, packageInfo, stringIDToNodeInfo, nameToVersionMap, isMaven)
+	}
+}
+
+// createPackageEdges creates the edges from the versions of one package to their dependencies
+func createPackageEdges(graph *simple.DirectedGraph, packageInfo PackageInfo, stringIDToNodeInfo map[string]NodeInfo, nameToVersionMap map[string][]string, isMaven bool) {
+	for packageVersion, dependencyInfo := range packageInfo.Versions {
+		packageID := stringIDToNodeInfo[StringID(packageInfo.Name, packageVersion)].id
+		for dependencyName, dependencyVersion := range dependencyInfo.Dependencies {
+			constraint, err := parseConstraint(dependencyVersion, isMaven)
+			//c, err := semver2.ParseRange(dependencyVersion)
+			if err != nil {
+				continue
+				//fmt.Println("sunt aici")
+				//fmt.Println(finaldep)
+				////log.Fatal(finaldep)
+				//log.Fatal(err)
+			}
+			for _, v := range nameToVersionMap[dependencyName] {
+				//newVersion, _ := semver2.Parse(v)
+				newVersion, err := semver.NewVersion(v)
 				if err != nil {
+					//fmt.Println(v)
+					//panic(err)
 					continue
-					//fmt.Println("sunt aici")
-					//fmt.Println(finaldep)
-					////log.Fatal(finaldep)
-					//log.Fatal(err)
 				}
-				for _, v := range nameToVersionMap[dependencyName] {
-					//newVersion, _ := semver2.Parse(v)
-					newVersion, err := semver.NewVersion(v)
-					if err != nil {
-						//fmt.Println(v)
-						//panic(err)
-						continue
+				if constraint.Check(newVersion) {
+					dependencyNameVersionString := StringID(dependencyName, v)
+					dependencyNode := graph.Node(stringIDToNodeInfo[dependencyNameVersionString].id)
+					packageNode := graph.Node(packageID)
+					// Ensure that we do not create edges to self because some packages do that...
+					if dependencyNode != packageNode {
+						graph.SetEdge(DependencyEdge{F: packageNode, T: dependencyNode, Kind: dependencyInfo.DependencyKind(dependencyName)})
 					}
-					if constraint.Check(newVersion) {
-						dependencyNameVersionString := fmt.Sprintf("%s-%s", dependencyName, v)
-						dependencyNode := graph.Node(stringIDToNodeInfo[dependencyNameVersionString].id)
-						packageNode := graph.Node(int64(id))
-						// Ensure that we do not create edges to self because some packages do that...
-						if dependencyNode != packageNode {
-							graph.SetEdge(simple.Edge{F: packageNode, T: dependencyNode})
-						}
 
-					}
 				}
 			}
 		}
@@ -260,11 +410,10 @@ func translateMavenSemver(s string, reg *regexp.Regexp) string {
 
 }
 
+// ParseJSON reads a JSON array of packages. The slice grows with the input instead of being sized for a full registry
+// up front, inputs that don't fit in memory are read with CreateGraphStreaming instead.
 func ParseJSON(inPath string) *[]PackageInfo {
-	// For NPM at least, about 2 million packages are expected, so we initialize so the array doesn't have to be re-allocated all the time
-	const expectedAmount int = 2000000
-	// An array for now since lists aren't type-safe, and they would overcomplicate things
-	result := make([]PackageInfo, 0, expectedAmount)
+	result := make([]PackageInfo, 0)
 	f, err := os.Open(inPath)
 	if err != nil {
 		log.Fatal(err)
@@ -294,44 +443,106 @@ func ParseJSON(inPath string) *[]PackageInfo {
 }
 
 func CreateGraph(inputPath string, isUsingMaven bool) (*simple.DirectedGraph, *[]PackageInfo, map[string]NodeInfo, map[int64]NodeInfo, map[string][]string) {
-	packagesList := ParseJSON(inputPath)
+	ecosystem := NPM
+	if isUsingMaven {
+		ecosystem = Maven
+	}
+	return CreateGraphFromPackages(ParseJSON(inputPath), ecosystem)
+}
+
+// CreateGraphFromPackages does the same as CreateGraph for packages of any ecosystem that were already loaded, for
+// example by one of the readers in the ingest package.
+func CreateGraphFromPackages(packagesList *[]PackageInfo, ecosystem Ecosystem) (*simple.DirectedGraph, *[]PackageInfo, map[string]NodeInfo, map[int64]NodeInfo, map[string][]string) {
 	graph := simple.NewDirectedGraph()
-	stringIDToNodeInfo := CreateStringIDToNodeInfoMap(packagesList, graph)
+	stringIDToNodeInfo := CreateStringIDToNodeInfoMap(packagesList, graph, ecosystem)
 	idToNodeInfo := CreateNodeIdToPackageMap(stringIDToNodeInfo)
 	nameToVersions := CreateNameToVersionMap(packagesList)
-	CreateEdges(graph, packagesList, stringIDToNodeInfo, nameToVersions, isUsingMaven)
+	CreateEdges(graph, packagesList, stringIDToNodeInfo, nameToVersions, ecosystem == Maven)
 	return graph, packagesList, stringIDToNodeInfo, idToNodeInfo, nameToVersions
 }
 
+// timestampLayouts are the layouts in which the package managers we support write their timestamps
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ParseTimestamp parses the timestamp of a node. Not every data source includes a time zone, timestamps without one
+// are interpreted as UTC.
+func ParseTimestamp(timestamp string) (time.Time, error) {
+	var err error
+	for _, layout := range timestampLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, timestamp); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
 // This function returns true when time t lies in the interval [begin, end], false otherwise
 func InInterval(t, begin, end time.Time) bool {
 	return t.Equal(begin) || t.Equal(end) || t.After(begin) && t.Before(end)
 }
 
-// This is a helper function used to initialize all required auxillary data structures for the graph traversal
-func initializeTraversal(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, connected []*graph.Edge, withinInterval map[int64]bool, beginTime time.Time, endTime time.Time, w traverse.DepthFirst) {
+// GetNodesInInterval returns all the nodes that were published in the interval [begin, end]. Nodes with a timestamp
+// that can't be parsed are skipped.
+func GetNodesInInterval(nodeMap map[int64]NodeInfo, beginTime, endTime time.Time) *[]NodeInfo {
+	result := make([]NodeInfo, 0)
+	for _, node := range nodeMap {
+		nodeTime, err := ParseTimestamp(node.Timestamp)
+		if err != nil {
+			continue
+		}
+		if InInterval(nodeTime, beginTime, endTime) {
+			result = append(result, node)
+		}
+	}
+	return &result
+}
+
+// SortNodes orders nodes by name and then by version, so results don't depend on the order of a traversal or of a map
+func SortNodes(nodes []NodeInfo) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Name != nodes[j].Name {
+			return nodes[i].Name < nodes[j].Name
+		}
+		return nodes[i].Version < nodes[j].Version
+	})
+}
+
+// edgeKey identifies a directed edge by the IDs of its endpoints
+type edgeKey struct {
+	from, to int64
+}
+
+// This is a helper function used to initialize all required auxillary data structures for the graph traversal.
+// It returns the walker that records every edge it traverses in connected.
+func initializeTraversal(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, connected map[edgeKey]bool, withinInterval map[int64]bool, beginTime time.Time, endTime time.Time) *traverse.DepthFirst {
 	nodes := g.Nodes()
 	for nodes.Next() { // Initialize withinInterval data structure
 		n := nodes.Node()
 		id := n.ID()
-		publishTime, _ := time.Parse(time.RFC3339, nodeMap[id].Timestamp)
+		publishTime, _ := ParseTimestamp(nodeMap[id].Timestamp)
 		if InInterval(publishTime, beginTime, endTime) {
 			withinInterval[id] = true
 		}
 	}
 
 	// TODO: Discuss if we should just leave packages free-floating if they haven't been visited even once
-	w = traverse.DepthFirst{
+	return &traverse.DepthFirst{
 		Traverse: func(e graph.Edge) bool { // The dependent / parent node
 			var traverse bool
 			fromId := e.From().ID()
 			toId := e.To().ID()
 			if withinInterval[toId] {
-				fromTime, _ := time.Parse(time.RFC3339, nodeMap[fromId].Timestamp) // The dependent node's time stamp
-				toTime, _ := time.Parse(time.RFC3339, nodeMap[toId].Timestamp)     // The dependency node's time stamp
-				if traverse = fromTime.After(toTime); traverse {
-					connected = append(connected, &e)
-				} // If the dependency was released before the parent node, add this edge to the connected nodes
+				fromTime, _ := ParseTimestamp(nodeMap[fromId].Timestamp) // The dependent node's time stamp
+				toTime, _ := ParseTimestamp(nodeMap[toId].Timestamp)     // The dependency node's time stamp
+				if traverse = fromTime.After(toTime) && nodeMap[toId].AvailableAt(fromTime); traverse {
+					connected[edgeKey{fromId, toId}] = true
+				} // If the dependency was released before the parent node and could still be installed then, add this edge to the connected nodes
 			}
 
 			return traverse
@@ -339,28 +550,30 @@ func initializeTraversal(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, co
 	}
 }
 
-func removeDisconnected(g *simple.DirectedGraph, connected []*graph.Edge) {
+// removeDisconnected removes every edge of the graph that was not traversed
+func removeDisconnected(g *simple.DirectedGraph, connected map[edgeKey]bool) {
+	var disconnected []graph.Edge
 	edges := g.Edges()
 	for edges.Next() {
 		edge := edges.Edge()
-		for _, disconnectedEdge := range connected { // Found that it's connected, move on
-			if edge == *disconnectedEdge {
-				break
-			} else {
-				g.RemoveEdge(edge.From().ID(), edge.To().ID())
-			}
+		if !connected[edgeKey{edge.From().ID(), edge.To().ID()}] {
+			disconnected = append(disconnected, edge)
 		}
 	}
+	for _, edge := range disconnected {
+		g.RemoveEdge(edge.From().ID(), edge.To().ID())
+	}
 }
 
-// This function removes stale edges from the specified graph by doing a DFS with all packages as the root node in O(n^2)
-func traverseAndRemoveEdges(g *simple.DirectedGraph, withinInterval map[int64]bool, w traverse.DepthFirst, connected []*graph.Edge) {
+// This function removes stale edges from the specified graph by doing a DFS with all packages as the root node.
+// The walker is not reset between roots: a node that was already expanded has had all its edges considered, so
+// walking it again would not connect any new edges.
+func traverseAndRemoveEdges(g *simple.DirectedGraph, withinInterval map[int64]bool, w *traverse.DepthFirst, connected map[edgeKey]bool) {
 	nodes := g.Nodes()
 	for nodes.Next() {
 		n := nodes.Node()
-		if withinInterval[n.ID()] { // We'll only consider traversing this subtree if its root was within the specified time interval
+		if withinInterval[n.ID()] && !w.Visited(n) { // We'll only consider traversing this subtree if its root was within the specified time interval
 			_ = w.Walk(g, n, nil) // Continue walking this subtree until we've visited everything we're allowed to according to Traverse
-			w.Reset()             // Clean up for the next iteration
 		}
 	}
 
@@ -368,18 +581,20 @@ func traverseAndRemoveEdges(g *simple.DirectedGraph, withinInterval map[int64]bo
 
 }
 
-func traverseOneNode(g *simple.DirectedGraph, nodeId int64, withinInterval map[int64]bool, w traverse.DepthFirst, connected []*graph.Edge) {
+func traverseOneNode(g *simple.DirectedGraph, nodeId int64, w *traverse.DepthFirst, connected map[edgeKey]bool) {
 	_ = w.Walk(g, g.Node(nodeId), nil)
 	removeDisconnected(g, connected)
 }
 
+// FilterGraph removes every edge that could not have been used in the interval [beginTime, endTime]. An edge is kept
+// when it is reachable from a package published in the interval, its dependency was published in the interval, the
+// dependency was released before the dependent and it was not yanked or unpublished by then.
 func FilterGraph(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, beginTime, endTime time.Time) {
 	// This stores whether the package existed in the specified time range
 	withinInterval := make(map[int64]bool, len(nodeMap))
 	// This keeps track of which edges we've connected
-	connected := make([]*graph.Edge, 0, len(nodeMap)*2)
-	var w traverse.DepthFirst
-	initializeTraversal(g, nodeMap, connected, withinInterval, beginTime, endTime, w) // Initialize all auxillary data structures for the traversal
+	connected := make(map[edgeKey]bool, len(nodeMap)*2)
+	w := initializeTraversal(g, nodeMap, connected, withinInterval, beginTime, endTime) // Initialize all auxillary data structures for the traversal
 
 	traverseAndRemoveEdges(g, withinInterval, w, connected) // Traverse the graph and remove stale edges
 
@@ -398,6 +613,8 @@ func findNode(stringMap map[string]NodeInfo, stringId string) (int64, bool) {
 	return nodeId, correctOk
 }
 
+// FilterNode removes every edge that is not reachable from the specified node in the interval [beginTime, endTime],
+// using the same rules as FilterGraph.
 func FilterNode(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, stringId string, beginTime, endTime time.Time) {
 
 	var nodeId int64
@@ -410,21 +627,27 @@ func FilterNode(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap m
 	// This stores whether the package existed in the specified time range
 	withinInterval := make(map[int64]bool, len(nodeMap))
 	// This keeps track of which edges we've connected
-	connected := make([]*graph.Edge, 0, len(nodeMap)*2)
-	var w traverse.DepthFirst
-	initializeTraversal(g, nodeMap, connected, withinInterval, beginTime, endTime, w) // Initialize all auxillary data structures for the traversal
+	connected := make(map[edgeKey]bool, len(nodeMap)*2)
+	w := initializeTraversal(g, nodeMap, connected, withinInterval, beginTime, endTime) // Initialize all auxillary data structures for the traversal
 
-	traverseOneNode(g, nodeId, withinInterval, w, connected)
+	traverseOneNode(g, nodeId, w, connected)
 }
 
 // This function returns the specified node and its dependencies
 func GetTransitiveDependenciesNode(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, stringId string) *[]NodeInfo {
+	result, _ := GetTransitiveDependenciesNodeContext(context.Background(), g, nodeMap, stringMap, stringId)
+	return result
+}
+
+// GetTransitiveDependenciesNodeContext is GetTransitiveDependenciesNode that stops walking as soon as the context is
+// cancelled, in which case it returns the error of the context.
+func GetTransitiveDependenciesNodeContext(ctx context.Context, g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, stringId string) (*[]NodeInfo, error) {
 	var nodeId int64
 	result := make([]NodeInfo, 0, len(nodeMap)/2)
 	if id, ok := findNode(stringMap, stringId); ok {
 		nodeId = id
 	} else {
-		return &result // This function is a no-op if we don't have a correct string id
+		return &result, nil // This function is a no-op if we don't have a correct string id
 	}
 
 	w := traverse.DepthFirst{
@@ -433,6 +656,30 @@ func GetTransitiveDependenciesNode(g *simple.DirectedGraph, nodeMap map[int64]No
 		},
 	}
 
-	_ = w.Walk(g, g.Node(nodeId), nil)
+	_ = w.Walk(g, g.Node(nodeId), untilCancelled(ctx))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTransitiveDependenciesNodes returns the specified nodes and the union of their dependencies. Unknown string ids
+// are skipped. This is used when the roots come from outside the graph, for example from an SBOM or a lockfile.
+func GetTransitiveDependenciesNodes(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, stringIds []string) *[]NodeInfo {
+	result := make([]NodeInfo, 0, len(nodeMap)/2)
+	w := traverse.DepthFirst{
+		Visit: func(n graph.Node) {
+			result = append(result, nodeMap[n.ID()])
+		},
+	}
+
+	for _, stringId := range stringIds {
+		id, ok := findNode(stringMap, stringId)
+		if !ok || w.Visited(g.Node(id)) {
+			continue
+		}
+		// The walker is not reset between roots so dependencies shared by several roots are only reported once
+		_ = w.Walk(g, g.Node(id), nil)
+	}
 	return &result
 }
@@ -33,7 +33,7 @@ func TestNodeCreationBasicGraph(t *testing.T) {
 	}
 	//dummyMap := make(map[int64]NodeInfo)
 	graph := simple.NewDirectedGraph()
-	stringMap := CreateStringIDToNodeInfoMap(&simplePackageInfo, graph)
+	stringMap := CreateStringIDToNodeInfoMap(&simplePackageInfo, graph, NPM)
 	nameVersion := CreateNameToVersionMap(&simplePackageInfo)
 	CreateEdges(graph, &simplePackageInfo, stringMap, nameVersion, false)
 
@@ -48,16 +48,16 @@ func TestNodeCreationBasicGraph(t *testing.T) {
 
 	t.Run("Create the two unique, correct nodes", func(t *testing.T) {
 		var idA, idB int64
-		if a, check := stringMap["A-1.0.0"]; check && graph.Node(idA) != nil {
+		if a, check := stringMap["A@1.0.0"]; check && graph.Node(idA) != nil {
 			idA = a.id
 		} else {
-			t.Error("Node A-1.0.0 didn't exist")
+			t.Error("Node A@1.0.0 didn't exist")
 		}
 
-		if b, check := stringMap["B-1.0.0"]; check && graph.Node(idB) != nil {
+		if b, check := stringMap["B@1.0.0"]; check && graph.Node(idB) != nil {
 			idB = b.id
 		} else {
-			t.Error("Node B-1.0.0 didn't exist")
+			t.Error("Node B@1.0.0 didn't exist")
 		}
 
 		if idA == idB {
@@ -130,39 +130,39 @@ func TestNodeCreationMediumComplexity(t *testing.T) {
 
 	//dummyMap := make(map[int64]NodeInfo)
 	graph := simple.NewDirectedGraph()
-	stringNodeInfo := CreateStringIDToNodeInfoMap(&mediumPackageInfo, graph)
+	stringNodeInfo := CreateStringIDToNodeInfoMap(&mediumPackageInfo, graph, NPM)
 	nameVersion := CreateNameToVersionMap(&mediumPackageInfo)
 	CreateEdges(graph, &mediumPackageInfo, stringNodeInfo, nameVersion, false)
 
 	t.Run("Creates 8 nodes, one for every package version", func(t *testing.T) {
 
-		if numNodes := graph.Edges().Len(); numNodes != 8 {
-			t.Errorf("Expected 8 edges, got %d", numNodes)
+		if numNodes := graph.Nodes().Len(); numNodes != 8 {
+			t.Errorf("Expected 8 nodes, got %d", numNodes)
 		}
 
 	})
 
 	t.Run("Creates the 8 correct nodes", func(t *testing.T) {
 		packageIDS := []string{
-			"A-0.9.0",
-			"A-1.0.0-rc.1",
-			"A-1.0.0",
-			"A-1.1.0",
-			"A-2.0.0",
-			"B-1.0.0",
-			"C-1.0.0",
-			"C-2.0.0",
+			"A@0.9.0",
+			"A@1.0.0-rc.1",
+			"A@1.0.0",
+			"A@1.1.0",
+			"A@2.0.0",
+			"B@1.0.0",
+			"C@1.0.0",
+			"C@2.0.0",
 		}
 
 		testInfo := map[string]NodeInfo{
-			"A-0.9.0":      createTestNodeInfo(packageA, "0.9.0"),
-			"A-1.0.0-rc.1": createTestNodeInfo(packageA, "1.0.0-rc.1"),
-			"A-1.0.0":      createTestNodeInfo(packageA, "1.0.0"),
-			"A-1.1.0":      createTestNodeInfo(packageA, "1.1.0"),
-			"A-2.0.0":      createTestNodeInfo(packageA, "2.0.0"),
-			"B-1.0.0":      createTestNodeInfo(packageB, "1.0.0"),
-			"C-1.0.0":      createTestNodeInfo(packageC, "1.0.0"),
-			"C-2.0.0":      createTestNodeInfo(packageC, "2.0.0"),
+			"A@0.9.0":      createTestNodeInfo(packageA, "0.9.0"),
+			"A@1.0.0-rc.1": createTestNodeInfo(packageA, "1.0.0-rc.1"),
+			"A@1.0.0":      createTestNodeInfo(packageA, "1.0.0"),
+			"A@1.1.0":      createTestNodeInfo(packageA, "1.1.0"),
+			"A@2.0.0":      createTestNodeInfo(packageA, "2.0.0"),
+			"B@1.0.0":      createTestNodeInfo(packageB, "1.0.0"),
+			"C@1.0.0":      createTestNodeInfo(packageC, "1.0.0"),
+			"C@2.0.0":      createTestNodeInfo(packageC, "2.0.0"),
 		}
 
 		for _, v := range packageIDS {
@@ -221,7 +221,7 @@ func TestCreateEdgesBasicGraph(t *testing.T) {
 	}
 	//dummyMap := make(map[int64]NodeInfo)
 	graph := simple.NewDirectedGraph()
-	stringIDToNodeInfo := CreateStringIDToNodeInfoMap(&simplePackagesInfo, graph)
+	stringIDToNodeInfo := CreateStringIDToNodeInfoMap(&simplePackagesInfo, graph, NPM)
 	nameToVersions := CreateNameToVersionMap(&simplePackagesInfo)
 	CreateEdges(graph, &simplePackagesInfo, stringIDToNodeInfo, nameToVersions, false)
 
@@ -232,8 +232,8 @@ func TestCreateEdgesBasicGraph(t *testing.T) {
 		}
 	})
 	t.Run("Creates the edge with the correct direction (dependent -> dependency)", func(t *testing.T) {
-		fromID := stringIDToNodeInfo["B-1.0.0"].id
-		toID := stringIDToNodeInfo["A-1.0.0"].id
+		fromID := stringIDToNodeInfo["B@1.0.0"].id
+		toID := stringIDToNodeInfo["A@1.0.0"].id
 		if graph.Edge(fromID, toID) == nil {
 			if graph.Edge(toID, fromID) != nil {
 				t.Error("Expected the correct direction but got a reversed edge. Please check if the edge " +
@@ -294,7 +294,7 @@ func TestCreateEdgesMediumComplexityGraph(t *testing.T) {
 	}
 	//dummyMap := make(map[int64]NodeInfo)
 	graph := simple.NewDirectedGraph()
-	stringIDToNodeInfo := CreateStringIDToNodeInfoMap(&packagesInfo, graph)
+	stringIDToNodeInfo := CreateStringIDToNodeInfoMap(&packagesInfo, graph, NPM)
 	nameToVersions := CreateNameToVersionMap(&packagesInfo)
 	CreateEdges(graph, &packagesInfo, stringIDToNodeInfo, nameToVersions, false)
 	t.Run("Creates 4 edges when there are 4 possible dependencies", func(t *testing.T) {
@@ -302,26 +302,26 @@ func TestCreateEdgesMediumComplexityGraph(t *testing.T) {
 			t.Errorf("Expected 4 edges, got %d", graph.Edges().Len())
 		}
 	})
-	t.Run("Creates edges to the correct dependencies for Node B-1.0.0", func(t *testing.T) {
-		if graph.From(stringIDToNodeInfo["B-1.0.0"].id).Len() != 3 {
-			t.Errorf("Expected 3 possible dependencies for Node B-1.0.0, got %d", graph.From(stringIDToNodeInfo["B-1.0.0"].id).Len())
+	t.Run("Creates edges to the correct dependencies for Node B@1.0.0", func(t *testing.T) {
+		if graph.From(stringIDToNodeInfo["B@1.0.0"].id).Len() != 3 {
+			t.Errorf("Expected 3 possible dependencies for Node B@1.0.0, got %d", graph.From(stringIDToNodeInfo["B@1.0.0"].id).Len())
 		}
-		nodesIterator := graph.From(stringIDToNodeInfo["B-1.0.0"].id)
+		nodesIterator := graph.From(stringIDToNodeInfo["B@1.0.0"].id)
 		counter := 0
 		for nodesIterator.Next() {
 			currentNode := nodesIterator.Node()
-			if currentNode.ID() == graph.Node(stringIDToNodeInfo["C-1.0.0"].id).ID() {
+			if currentNode.ID() == graph.Node(stringIDToNodeInfo["C@1.0.0"].id).ID() {
 				counter++
 			}
 		}
 		if counter > 1 {
-			t.Errorf("Expected exactly 1 edge towards dependency C-1.0.0, got %d", counter)
+			t.Errorf("Expected exactly 1 edge towards dependency C@1.0.0, got %d", counter)
 		}
 
 	})
-	t.Run("Creates no edges from Node A-1.0.0 (it has no dependencies)", func(t *testing.T) {
-		if graph.From(stringIDToNodeInfo["A-1.0.0"].id).Len() != 0 {
-			t.Errorf("Expected 0 dependencies for Node A-1.0.0, got %d", graph.From(stringIDToNodeInfo["A-1.0.0"].id).Len())
+	t.Run("Creates no edges from Node A@1.0.0 (it has no dependencies)", func(t *testing.T) {
+		if graph.From(stringIDToNodeInfo["A@1.0.0"].id).Len() != 0 {
+			t.Errorf("Expected 0 dependencies for Node A@1.0.0, got %d", graph.From(stringIDToNodeInfo["A@1.0.0"].id).Len())
 		}
 	})
 }
@@ -0,0 +1,231 @@
+package graph
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// daysPerYear converts lag in days to libyears
+const daysPerYear = 365.25
+
+// SemverDistance is how far a version is behind another one. Only the most significant part that differs is set, so
+// 1.2.3 is 1 major version behind 2.0.0 and 2 minor versions behind 1.4.0.
+type SemverDistance struct {
+	Major int64
+	Minor int64
+	Patch int64
+}
+
+// Lag is how far the version a dependency resolved to was behind the newest version of the package, at the moment
+// the dependent was published
+type Lag struct {
+	Dependency
+	// Newest is the highest version of the dependency that was published by then, pre-releases excluded
+	Newest NodeInfo
+	// Days is the time between the publication of the resolved and the newest version, Releases the number of
+	// versions between them
+	Days     float64
+	Releases int
+	Distance SemverDistance
+}
+
+// Libyears is the lag in years
+func (lag Lag) Libyears() float64 {
+	return lag.Days / daysPerYear
+}
+
+// Libyears sums the lag of every version that a release resolved to transitively at the moment it was published
+type Libyears struct {
+	Node         NodeInfo
+	Dependencies int
+	// Outdated is the number of dependencies that did not resolve to the newest version of their package
+	Outdated int
+	Libyears float64
+}
+
+// LibyearsPoint summarizes the libyears of the releases published in a period
+type LibyearsPoint struct {
+	Begin          time.Time
+	End            time.Time
+	Releases       int
+	MeanLibyears   float64
+	MedianLibyears float64
+	MaxLibyears    float64
+}
+
+// LagCalculator computes the technical lag of the versions in a graph. It keeps the versions of every package in
+// version order and the libyears that were already computed.
+type LagCalculator struct {
+	g         *simple.DirectedGraph
+	nodeMap   map[int64]NodeInfo
+	ecosystem Ecosystem
+	// versions holds the versions of every package that are not pre-releases and have a valid timestamp, from low to
+	// high
+	versions  map[string][]NodeInfo
+	published map[int64]time.Time
+	libyears  map[int64]Libyears
+}
+
+// NewLagCalculator prepares the lag computations for a version-level graph
+func NewLagCalculator(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, ecosystem Ecosystem) *LagCalculator {
+	c := &LagCalculator{
+		g:         g,
+		nodeMap:   nodeMap,
+		ecosystem: ecosystem,
+		versions:  make(map[string][]NodeInfo),
+		published: make(map[int64]time.Time, len(nodeMap)),
+		libyears:  make(map[int64]Libyears),
+	}
+	for id, node := range nodeMap {
+		t, err := ParseTimestamp(node.Timestamp)
+		if err != nil {
+			continue
+		}
+		c.published[id] = t
+		if !IsPrerelease(ecosystem, node.Version) {
+			c.versions[node.Name] = append(c.versions[node.Name], node)
+		}
+	}
+	for _, versions := range c.versions {
+		sort.Slice(versions, func(i, j int) bool {
+			return CompareVersions(ecosystem, versions[i].Version, versions[j].Version) < 0
+		})
+	}
+	return c
+}
+
+// newestAt returns the highest version of the package that was published and still available at the given moment
+func (c *LagCalculator) newestAt(name string, at time.Time) (NodeInfo, bool) {
+	versions := c.versions[name]
+	for i := len(versions) - 1; i >= 0; i-- {
+		if !c.published[versions[i].id].After(at) && versions[i].AvailableAt(at) {
+			return versions[i], true
+		}
+	}
+	return NodeInfo{}, false
+}
+
+// lag compares the resolved version of a dependency with the newest version at the given moment
+func (c *LagCalculator) lag(dependency Dependency, at time.Time) Lag {
+	lag := Lag{Dependency: dependency, Newest: dependency.Node}
+	newest, ok := c.newestAt(dependency.Node.Name, at)
+	if !ok || CompareVersions(c.ecosystem, newest.Version, dependency.Node.Version) <= 0 {
+		return lag
+	}
+	lag.Newest = newest
+	// A backported fix can be published after the newest version, it is not behind in time then
+	if days := c.published[newest.id].Sub(c.published[dependency.Node.id]).Hours() / 24; days > 0 {
+		lag.Days = days
+	}
+	for _, version := range c.versions[dependency.Node.Name] {
+		if c.published[version.id].After(at) {
+			continue
+		}
+		if CompareVersions(c.ecosystem, version.Version, dependency.Node.Version) > 0 && CompareVersions(c.ecosystem, version.Version, newest.Version) <= 0 {
+			lag.Releases++
+		}
+	}
+	lag.Distance = semverDistance(dependency.Node.Version, newest.Version)
+	return lag
+}
+
+func semverDistance(from, to string) SemverDistance {
+	v1, err1 := semver.NewVersion(from)
+	v2, err2 := semver.NewVersion(to)
+	if err1 != nil || err2 != nil {
+		return SemverDistance{}
+	}
+	switch {
+	case v1.Major() != v2.Major():
+		return SemverDistance{Major: v2.Major() - v1.Major()}
+	case v1.Minor() != v2.Minor():
+		return SemverDistance{Minor: v2.Minor() - v1.Minor()}
+	}
+	return SemverDistance{Patch: v2.Patch() - v1.Patch()}
+}
+
+// DependencyLags returns the lag of the direct dependencies of the node at the moment it was published, ordered by
+// the name of the dependency
+func (c *LagCalculator) DependencyLags(node NodeInfo) []Lag {
+	result := make([]Lag, 0)
+	at, ok := c.published[node.id]
+	if !ok {
+		return result
+	}
+	for _, dependency := range ResolveDependencies(c.g, c.nodeMap, c.ecosystem, node, at) {
+		result = append(result, c.lag(dependency, at))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Node.Name < result[j].Node.Name
+	})
+	return result
+}
+
+// TransitiveLags returns the lag of every version the node resolved to transitively at the moment it was published,
+// in the order of ResolveTree
+func (c *LagCalculator) TransitiveLags(node NodeInfo) []Lag {
+	result := make([]Lag, 0)
+	at, ok := c.published[node.id]
+	if !ok {
+		return result
+	}
+	for _, dependency := range ResolveTree(c.g, c.nodeMap, c.ecosystem, node, at) {
+		result = append(result, c.lag(dependency, at))
+	}
+	return result
+}
+
+// Libyears sums the transitive lag of the node
+func (c *LagCalculator) Libyears(node NodeInfo) Libyears {
+	if result, ok := c.libyears[node.id]; ok {
+		return result
+	}
+	result := Libyears{Node: node}
+	for _, lag := range c.TransitiveLags(node) {
+		result.Dependencies++
+		if lag.Newest.id != lag.Node.id {
+			result.Outdated++
+		}
+		result.Libyears += lag.Libyears()
+	}
+	c.libyears[node.id] = result
+	return result
+}
+
+// LibyearsOverTime splits [begin, end) into periods of the given length and summarizes the libyears of the releases
+// published in each of them. Periods without releases are kept so the series has no gaps.
+func (c *LagCalculator) LibyearsOverTime(begin, end time.Time, step Period) []LibyearsPoint {
+	var result []LibyearsPoint
+	for periodBegin := begin; periodBegin.Before(end); periodBegin = step.After(periodBegin) {
+		point := LibyearsPoint{Begin: periodBegin, End: step.After(periodBegin)}
+		if point.End.After(end) {
+			point.End = end
+		}
+		var values []float64
+		for id, published := range c.published {
+			if published.Before(point.Begin) || !published.Before(point.End) {
+				continue
+			}
+			values = append(values, c.Libyears(c.nodeMap[id]).Libyears)
+		}
+		point.Releases = len(values)
+		if len(values) > 0 {
+			sort.Float64s(values)
+			total := 0.0
+			for _, value := range values {
+				total += value
+			}
+			point.MeanLibyears = total / float64(len(values))
+			point.MedianLibyears = values[len(values)/2]
+			if len(values)%2 == 0 {
+				point.MedianLibyears = (values[len(values)/2-1] + values[len(values)/2]) / 2
+			}
+			point.MaxLibyears = values[len(values)-1]
+		}
+		result = append(result, point)
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package graph
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// createLagTestGraph creates C -> B -> A where B can use every 1.x version of A, but A 2.0.0 was already out when B
+// was published
+func createLagTestGraph() (*simple.DirectedGraph, map[string]NodeInfo, map[int64]NodeInfo) {
+	packagesInfo := []PackageInfo{
+		{Name: "C", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2022-01-01T00:00:00", Dependencies: map[string]string{"B": ">=1.0.0"}},
+		}},
+		{Name: "B", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-06-01T00:00:00", Dependencies: map[string]string{"A": "^1.0.0"}},
+		}},
+		{Name: "A", Versions: map[string]VersionInfo{
+			"1.0.0":       {Timestamp: "2020-01-01T00:00:00"},
+			"1.1.0":       {Timestamp: "2020-06-01T00:00:00"},
+			"2.0.0":       {Timestamp: "2021-01-01T00:00:00"},
+			"3.0.0-beta1": {Timestamp: "2021-02-01T00:00:00"},
+		}},
+	}
+	graph, _, stringIDToNodeInfo, idToNodeInfo, _ := CreateGraphFromPackages(&packagesInfo, NPM)
+	return graph, stringIDToNodeInfo, idToNodeInfo
+}
+
+func TestResolveDependencies(t *testing.T) {
+	graph, stringIDToNodeInfo, idToNodeInfo := createLagTestGraph()
+	b := stringIDToNodeInfo["B@1.0.0"]
+	for at, want := range map[string]string{"2020-03-01": "1.0.0", "2021-06-01": "1.1.0"} {
+		moment, _ := time.Parse("2006-01-02", at)
+		resolved := ResolveDependencies(graph, idToNodeInfo, NPM, b, moment)
+		if resolved["A"].Node.Version != want {
+			t.Errorf("Expected B to resolve A to %s at %s, got %v", want, at, resolved)
+		}
+	}
+
+	tree := ResolveTree(graph, idToNodeInfo, NPM, stringIDToNodeInfo["C@1.0.0"], time.Now())
+	if len(tree) != 2 || tree[1].Node.Version != "1.1.0" || tree[1].Depth != 2 {
+		t.Errorf("Expected C to resolve B@1.0.0 and A@1.1.0, got %v", tree)
+	}
+}
+
+func TestResolveDependenciesHonorsStatus(t *testing.T) {
+	// B can use every 1.x version of A. 1.3.0 was yanked, 1.2.0 deprecated and 1.1.0 unpublished in 2021.
+	packagesInfo := []PackageInfo{
+		{Name: "B", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2022-01-01T00:00:00", Dependencies: map[string]string{"A": "^1.0.0"}},
+		}},
+		{Name: "A", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2020-01-01T00:00:00", VersionStatus: VersionStatus{Deprecated: "too old"}},
+			"1.1.0": {Timestamp: "2020-02-01T00:00:00", VersionStatus: VersionStatus{Unpublished: "2021-01-01T00:00:00"}},
+			"1.2.0": {Timestamp: "2020-03-01T00:00:00", VersionStatus: VersionStatus{Deprecated: "use 1.1.0"}},
+			"1.3.0": {Timestamp: "2020-04-01T00:00:00", VersionStatus: VersionStatus{Yanked: true}},
+		}},
+	}
+	graph, _, stringIDToNodeInfo, idToNodeInfo, _ := CreateGraphFromPackages(&packagesInfo, NPM)
+	b := stringIDToNodeInfo["B@1.0.0"]
+	for at, want := range map[string]string{"2020-02-15": "1.1.0", "2020-12-31": "1.1.0", "2021-06-01": "1.2.0"} {
+		moment, _ := time.Parse("2006-01-02", at)
+		if resolved := ResolveDependencies(graph, idToNodeInfo, NPM, b, moment); resolved["A"].Node.Version != want {
+			t.Errorf("Expected B to resolve A to %s at %s, got %v", want, at, resolved)
+		}
+	}
+
+	t.Run("Skips versions that were not available in the interval", func(t *testing.T) {
+		begin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		nodes := *GetTransitiveDependenciesNodeInInterval(graph, idToNodeInfo, stringIDToNodeInfo, "B@1.0.0", begin, begin.AddDate(3, 0, 0))
+		sortNodesByStringID(nodes)
+		if len(nodes) != 3 || nodes[0].Version != "1.0.0" || nodes[1].Version != "1.2.0" {
+			t.Errorf("Expected B, A@1.0.0 and A@1.2.0, the others were unpublished or yanked when B was published, got %v", nodes)
+		}
+	})
+}
+
+func TestMinimalVersionSelection(t *testing.T) {
+	// main requires a v1.1.0 and b v1.0.0, b v1.0.0 requires a v1.2.0 and a v1.2.0 requires main v0.9.0. a v1.3.0 is
+	// in the graph but nothing requires it. b excludes a v1.2.0, which doesn't matter because b is not the main module.
+	packagesInfo := []PackageInfo{
+		{Name: "example.com/main", Versions: map[string]VersionInfo{
+			"v1.0.0": {Timestamp: "2022-01-01T00:00:00", Dependencies: map[string]string{"example.com/a": "v1.1.0", "example.com/b": "v1.0.0"}},
+			"v0.9.0": {Timestamp: "2021-01-01T00:00:00"},
+			"v1.1.0": {
+				Timestamp:    "2022-02-01T00:00:00",
+				Dependencies: map[string]string{"example.com/a": "v1.1.0", "example.com/b": "v1.0.0", "example.com/local": "v1.0.0"},
+				ModuleDirectives: ModuleDirectives{
+					Replace: map[string]string{"example.com/b": "example.com/c@v1.0.0", "example.com/local@v1.0.0": ""},
+					Exclude: []string{"example.com/a@v1.1.0", "example.com/a@v1.2.0"},
+				},
+			},
+		}},
+		{Name: "example.com/a", Versions: map[string]VersionInfo{
+			"v1.1.0": {Timestamp: "2021-01-01T00:00:00"},
+			"v1.2.0": {Timestamp: "2021-02-01T00:00:00", Dependencies: map[string]string{"example.com/main": "v0.9.0"}},
+			"v1.3.0": {Timestamp: "2021-03-01T00:00:00"},
+		}},
+		{Name: "example.com/b", Versions: map[string]VersionInfo{
+			"v1.0.0": {
+				Timestamp:        "2021-01-01T00:00:00",
+				Dependencies:     map[string]string{"example.com/a": "v1.2.0"},
+				ModuleDirectives: ModuleDirectives{Exclude: []string{"example.com/a@v1.2.0"}},
+			},
+		}},
+		{Name: "example.com/c", Versions: map[string]VersionInfo{
+			"v1.0.0": {Timestamp: "2021-01-01T00:00:00"},
+		}},
+	}
+	graph, _, stringIDToNodeInfo, idToNodeInfo, nameToVersions := CreateGraphFromPackages(&packagesInfo, NPM)
+	buildList := MinimalVersionSelection(graph, idToNodeInfo, stringIDToNodeInfo, &packagesInfo, nameToVersions, Go, stringIDToNodeInfo["example.com/main@v1.0.0"])
+	if len(buildList) != 2 || buildList[0].stringID != "example.com/a@v1.2.0" || buildList[1].stringID != "example.com/b@v1.0.0" {
+		t.Errorf("Expected a@v1.2.0 and b@v1.0.0, got %v", buildList)
+	}
+
+	t.Run("Applies the replace and exclude directives of the main module", func(t *testing.T) {
+		// a v1.1.0 and v1.2.0 are excluded, so v1.3.0 is used. b is built from c and local is a directory.
+		buildList := MinimalVersionSelection(graph, idToNodeInfo, stringIDToNodeInfo, &packagesInfo, nameToVersions, Go, stringIDToNodeInfo["example.com/main@v1.1.0"])
+		if len(buildList) != 2 || buildList[0].stringID != "example.com/a@v1.3.0" || buildList[1].stringID != "example.com/c@v1.0.0" {
+			t.Errorf("Expected a@v1.3.0 and c@v1.0.0, got %v", buildList)
+		}
+	})
+}
+
+func TestLagCalculator(t *testing.T) {
+	graph, stringIDToNodeInfo, idToNodeInfo := createLagTestGraph()
+	calculator := NewLagCalculator(graph, idToNodeInfo, NPM)
+
+	t.Run("Compares the resolved version with the newest one", func(t *testing.T) {
+		lags := calculator.DependencyLags(stringIDToNodeInfo["B@1.0.0"])
+		if len(lags) != 1 {
+			t.Fatalf("Expected the lag of A, got %v", lags)
+		}
+		lag := lags[0]
+		if lag.Node.Version != "1.1.0" || lag.Newest.Version != "2.0.0" {
+			t.Errorf("Expected A@1.1.0 to be behind A@2.0.0, got %+v", lag)
+		}
+		if lag.Days != 214 || lag.Releases != 1 || lag.Distance != (SemverDistance{Major: 1}) {
+			t.Errorf("Expected 214 days, 1 release and 1 major version of lag, got %+v", lag)
+		}
+	})
+
+	t.Run("Sums the transitive lag", func(t *testing.T) {
+		libyears := calculator.Libyears(stringIDToNodeInfo["C@1.0.0"])
+		if libyears.Dependencies != 2 || libyears.Outdated != 1 || math.Abs(libyears.Libyears-214/daysPerYear) > 1e-9 {
+			t.Errorf("Expected 1 of 2 dependencies to be 214 days behind, got %+v", libyears)
+		}
+	})
+
+	t.Run("Summarizes the libyears per period", func(t *testing.T) {
+		begin := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		points := calculator.LibyearsOverTime(begin, begin.AddDate(2, 0, 0), Period{Years: 1})
+		if len(points) != 2 || points[0].Releases != 3 || points[1].Releases != 1 {
+			t.Fatalf("Expected A@2.0.0, A@3.0.0-beta1 and B in 2021 and C in 2022, got %+v", points)
+		}
+		if math.Abs(points[1].MeanLibyears-214/daysPerYear) > 1e-9 {
+			t.Errorf("Expected the libyears of C in 2022, got %+v", points[1])
+		}
+	})
+}
@@ -0,0 +1,362 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// LicenseCategory groups licenses by the obligations they put on the software that uses them
+type LicenseCategory string
+
+const (
+	Permissive LicenseCategory = "permissive"
+	// WeakCopyleft licenses only cover changes to the licensed package itself
+	WeakCopyleft LicenseCategory = "weak-copyleft"
+	// StrongCopyleft licenses cover the software the package is distributed with
+	StrongCopyleft LicenseCategory = "strong-copyleft"
+	// NetworkCopyleft licenses also cover software that is only offered over a network
+	NetworkCopyleft LicenseCategory = "network-copyleft"
+	UnknownLicense  LicenseCategory = "unknown"
+)
+
+// LicenseCategories are the categories a policy can assign to licenses
+var LicenseCategories = []LicenseCategory{Permissive, WeakCopyleft, StrongCopyleft, NetworkCopyleft, UnknownLicense}
+
+// NoLicense is the license of versions that don't declare one
+const NoLicense = "NOASSERTION"
+
+// restrictiveness orders the categories, an unknown license is treated as the most restrictive one
+var restrictiveness = map[LicenseCategory]int{
+	Permissive:      0,
+	WeakCopyleft:    1,
+	StrongCopyleft:  2,
+	NetworkCopyleft: 3,
+	UnknownLicense:  4,
+}
+
+// licenseCategories are the categories of the common SPDX license ids. The ids are stored in lower case and without
+// the -only and -or-later suffixes.
+var licenseCategories = map[string]LicenseCategory{
+	"0bsd": Permissive, "apache-1.1": Permissive, "apache-2.0": Permissive, "artistic-2.0": Permissive,
+	"blueoak-1.0.0": Permissive, "bsd-2-clause": Permissive, "bsd-3-clause": Permissive, "bsl-1.0": Permissive,
+	"cc-by-3.0": Permissive, "cc-by-4.0": Permissive, "cc0-1.0": Permissive, "isc": Permissive, "mit": Permissive,
+	"mit-0": Permissive, "psf-2.0": Permissive, "python-2.0": Permissive, "unicode-dfs-2016": Permissive,
+	"unlicense": Permissive, "wtfpl": Permissive, "x11": Permissive, "zlib": Permissive,
+
+	"cddl-1.0": WeakCopyleft, "cddl-1.1": WeakCopyleft, "epl-1.0": WeakCopyleft, "epl-2.0": WeakCopyleft,
+	"lgpl-2.0": WeakCopyleft, "lgpl-2.1": WeakCopyleft, "lgpl-3.0": WeakCopyleft, "mpl-1.1": WeakCopyleft,
+	"mpl-2.0": WeakCopyleft,
+
+	"cc-by-sa-4.0": StrongCopyleft, "eupl-1.1": StrongCopyleft, "eupl-1.2": StrongCopyleft,
+	"gpl-2.0": StrongCopyleft, "gpl-3.0": StrongCopyleft,
+
+	"agpl-3.0": NetworkCopyleft, "sspl-1.0": NetworkCopyleft,
+}
+
+// linkingExceptions allow linking to a copyleft package without the copyleft covering the rest of the software
+var linkingExceptions = map[string]bool{
+	"classpath-exception-2.0":        true,
+	"gcc-exception-2.0":              true,
+	"gcc-exception-3.1":              true,
+	"llvm-exception":                 true,
+	"openjdk-assembly-exception-1.0": true,
+}
+
+// normalizeLicense returns the key of a license id in licenseCategories
+func normalizeLicense(license string) string {
+	license = strings.ToLower(strings.TrimSuffix(license, "+"))
+	license = strings.TrimSuffix(license, "-only")
+	return strings.TrimSuffix(license, "-or-later")
+}
+
+// LicenseExpression is a parsed SPDX license expression. Leaves hold a license and the exception it is used with,
+// the other nodes combine their operands with AND or OR.
+type LicenseExpression struct {
+	License   string
+	Exception string
+	Operator  string
+	Operands  []*LicenseExpression
+}
+
+// ParseLicenseExpression parses an SPDX license expression like "(MIT OR Apache-2.0) AND GPL-2.0-only WITH
+// Classpath-exception-2.0". AND binds stronger than OR. An empty expression means the version has no license.
+func ParseLicenseExpression(expression string) (*LicenseExpression, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return &LicenseExpression{License: NoLicense}, nil
+	}
+	p := licenseParser{tokens: strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression))}
+	result, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid license expression %q: %w", expression, err)
+	}
+	if p.position < len(p.tokens) {
+		return nil, fmt.Errorf("invalid license expression %q: unexpected %q", expression, p.tokens[p.position])
+	}
+	return result, nil
+}
+
+type licenseParser struct {
+	tokens   []string
+	position int
+}
+
+func (p *licenseParser) next() string {
+	if p.position < len(p.tokens) {
+		return p.tokens[p.position]
+	}
+	return ""
+}
+
+func (p *licenseParser) parseOr() (*LicenseExpression, error) {
+	return p.parseOperator("OR", p.parseAnd)
+}
+
+func (p *licenseParser) parseAnd() (*LicenseExpression, error) {
+	return p.parseOperator("AND", p.parseTerm)
+}
+
+func (p *licenseParser) parseOperator(operator string, operand func() (*LicenseExpression, error)) (*LicenseExpression, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	result := &LicenseExpression{Operator: operator, Operands: []*LicenseExpression{first}}
+	for strings.EqualFold(p.next(), operator) {
+		p.position++
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		result.Operands = append(result.Operands, next)
+	}
+	if len(result.Operands) == 1 {
+		return first, nil
+	}
+	return result, nil
+}
+
+func (p *licenseParser) parseTerm() (*LicenseExpression, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end")
+	case token == "(":
+		p.position++
+		result, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.position++
+		return result, nil
+	case token == ")" || strings.EqualFold(token, "AND") || strings.EqualFold(token, "OR") || strings.EqualFold(token, "WITH"):
+		return nil, fmt.Errorf("unexpected %q", token)
+	}
+	p.position++
+	result := &LicenseExpression{License: token}
+	if strings.EqualFold(p.next(), "WITH") {
+		p.position++
+		if result.Exception = p.next(); result.Exception == "" || result.Exception == "(" || result.Exception == ")" {
+			return nil, fmt.Errorf("missing exception after WITH")
+		}
+		p.position++
+	}
+	return result, nil
+}
+
+// LicensePolicy decides which licenses of dependencies conflict with the license of the root
+type LicensePolicy struct {
+	// Categories adds licenses to the built-in categories or changes their category. The keys are SPDX license ids
+	// or ids with an exception, like "GPL-2.0-only WITH Classpath-exception-2.0".
+	Categories map[string]LicenseCategory `json:"categories" yaml:"categories"`
+	// Incompatible lists the categories of dependencies that conflict with a root of the category of the key
+	Incompatible map[LicenseCategory][]LicenseCategory `json:"incompatible" yaml:"incompatible"`
+	// Denied licenses conflict with every root
+	Denied []string `json:"denied" yaml:"denied"`
+	// Project is the category the root is distributed under, when it is empty it follows from the license of the root
+	Project LicenseCategory `json:"project" yaml:"project"`
+}
+
+// DefaultLicensePolicy flags strong and network copyleft dependencies of permissive and weak copyleft roots, and
+// network copyleft dependencies of strong copyleft roots
+func DefaultLicensePolicy() LicensePolicy {
+	return LicensePolicy{
+		Categories: make(map[string]LicenseCategory),
+		Incompatible: map[LicenseCategory][]LicenseCategory{
+			Permissive:     {StrongCopyleft, NetworkCopyleft},
+			WeakCopyleft:   {StrongCopyleft, NetworkCopyleft},
+			StrongCopyleft: {NetworkCopyleft},
+		},
+	}
+}
+
+// licenseCategory returns the category of a single license that may be used with an exception
+func (policy LicensePolicy) licenseCategory(license, exception string) LicenseCategory {
+	for id, category := range policy.Categories {
+		if exception != "" && strings.EqualFold(id, license+" WITH "+exception) {
+			return category
+		}
+	}
+	for id, category := range policy.Categories {
+		if strings.EqualFold(id, license) {
+			return category
+		}
+	}
+	category, ok := licenseCategories[normalizeLicense(license)]
+	if !ok {
+		return UnknownLicense
+	}
+	if category == StrongCopyleft && linkingExceptions[strings.ToLower(exception)] {
+		return WeakCopyleft
+	}
+	return category
+}
+
+// Category returns the category of a license expression. Of the licenses combined with OR the least restrictive one
+// can be picked, all licenses combined with AND apply.
+func (policy LicensePolicy) Category(expression *LicenseExpression) LicenseCategory {
+	if expression.Operator == "" {
+		return policy.licenseCategory(expression.License, expression.Exception)
+	}
+	result := policy.Category(expression.Operands[0])
+	for _, operand := range expression.Operands[1:] {
+		category := policy.Category(operand)
+		lessRestrictive := restrictiveness[category] < restrictiveness[result]
+		if lessRestrictive == (expression.Operator == "OR") {
+			result = category
+		}
+	}
+	return result
+}
+
+// Conflicts reports whether a dependency under the license expression conflicts with a root of the given category.
+// Licenses combined with OR only conflict when every alternative does.
+func (policy LicensePolicy) Conflicts(root LicenseCategory, expression *LicenseExpression) bool {
+	if expression.Operator == "" {
+		for _, denied := range policy.Denied {
+			if strings.EqualFold(denied, expression.License) {
+				return true
+			}
+		}
+		category := policy.licenseCategory(expression.License, expression.Exception)
+		for _, incompatible := range policy.Incompatible[root] {
+			if incompatible == category {
+				return true
+			}
+		}
+		return false
+	}
+	for _, operand := range expression.Operands {
+		conflicts := policy.Conflicts(root, operand)
+		if conflicts && expression.Operator == "AND" {
+			return true
+		}
+		if !conflicts && expression.Operator == "OR" {
+			return false
+		}
+	}
+	return expression.Operator == "OR"
+}
+
+// parseLicenseLeniently parses a license expression, declarations that are not valid SPDX expressions, like
+// "SEE LICENSE IN LICENSE.md", are kept as a single unknown license
+func parseLicenseLeniently(license string) *LicenseExpression {
+	expression, err := ParseLicenseExpression(license)
+	if err != nil {
+		return &LicenseExpression{License: strings.TrimSpace(license)}
+	}
+	return expression
+}
+
+// CreateStringIDToLicenseMap maps the string id of every version to the license expression it declares
+func CreateStringIDToLicenseMap(packagesInfo *[]PackageInfo) map[string]string {
+	result := make(map[string]string)
+	for _, packageInfo := range *packagesInfo {
+		for version, versionInfo := range packageInfo.Versions {
+			result[StringID(packageInfo.Name, version)] = versionInfo.License
+		}
+	}
+	return result
+}
+
+// LicenseUsage is a license expression that is declared by one or more dependencies of the root
+type LicenseUsage struct {
+	License  string
+	Category LicenseCategory
+	// Conflict is set when the policy doesn't allow the license in dependencies of the root
+	Conflict bool
+	// Nodes are the dependencies that declare the license, Path is the shortest chain of dependencies from the root to
+	// one of them, both included
+	Nodes []NodeInfo
+	Path  []NodeInfo
+}
+
+// LicenseReport lists the licenses of the transitive dependencies of a root
+type LicenseReport struct {
+	Root     NodeInfo
+	License  string
+	Category LicenseCategory
+	Usages   []LicenseUsage
+}
+
+// GetLicenseReport walks the dependency tree of the root and groups the dependencies by the license they declare.
+// The options restrict the tree in the same way as for GetDependencyTree. The usages are sorted with the conflicts
+// first and then by license.
+func GetLicenseReport(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, licenses map[string]string, stringId string, policy LicensePolicy, options TreeOptions) *LicenseReport {
+	root := stringMap[stringId]
+	report := &LicenseReport{Root: root, License: licenses[stringId]}
+	report.Category = policy.Project
+	if report.Category == "" {
+		report.Category = policy.Category(parseLicenseLeniently(report.License))
+	}
+	if report.License == "" {
+		report.License = NoLicense
+	}
+
+	parents := make(map[int64]NodeInfo)
+	usages := make(map[string]*LicenseUsage)
+	// The tree is in breadth first order, so the first version of every license is the closest one
+	for _, dependency := range *GetDependencyTree(g, nodeMap, stringMap, stringId, options) {
+		parents[dependency.Node.id] = dependency.Parent
+		license := licenses[StringID(dependency.Node.Name, dependency.Node.Version)]
+		if license == "" {
+			license = NoLicense
+		}
+		usage, ok := usages[license]
+		if !ok {
+			expression := parseLicenseLeniently(license)
+			usage = &LicenseUsage{
+				License:  license,
+				Category: policy.Category(expression),
+				Conflict: policy.Conflicts(report.Category, expression),
+			}
+			for id := dependency.Node.id; id != root.id; id = parents[id].id {
+				usage.Path = append(usage.Path, nodeMap[id])
+			}
+			usage.Path = append(usage.Path, root)
+			for i, j := 0, len(usage.Path)-1; i < j; i, j = i+1, j-1 {
+				usage.Path[i], usage.Path[j] = usage.Path[j], usage.Path[i]
+			}
+			usages[license] = usage
+		}
+		usage.Nodes = append(usage.Nodes, dependency.Node)
+	}
+
+	report.Usages = make([]LicenseUsage, 0, len(usages))
+	for _, usage := range usages {
+		report.Usages = append(report.Usages, *usage)
+	}
+	sort.Slice(report.Usages, func(i, j int) bool {
+		if report.Usages[i].Conflict != report.Usages[j].Conflict {
+			return report.Usages[i].Conflict
+		}
+		return report.Usages[i].License < report.Usages[j].License
+	})
+	return report
+}
@@ -0,0 +1,81 @@
+package graph
+
+import "testing"
+
+func TestParseLicenseExpression(t *testing.T) {
+	expression, err := ParseLicenseExpression("(MIT OR Apache-2.0) AND GPL-2.0-only WITH Classpath-exception-2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expression.Operator != "AND" || len(expression.Operands) != 2 || expression.Operands[0].Operator != "OR" {
+		t.Fatalf("Expected an AND of an OR and a license, got %+v", expression)
+	}
+	if gpl := expression.Operands[1]; gpl.License != "GPL-2.0-only" || gpl.Exception != "Classpath-exception-2.0" {
+		t.Errorf("Expected GPL-2.0-only with the classpath exception, got %+v", gpl)
+	}
+	for _, invalid := range []string{"MIT OR", "(MIT", "MIT WITH", "AND MIT", "MIT Apache-2.0"} {
+		if _, err := ParseLicenseExpression(invalid); err == nil {
+			t.Errorf("Expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestLicensePolicy(t *testing.T) {
+	policy := DefaultLicensePolicy()
+	tests := []struct {
+		license  string
+		category LicenseCategory
+		conflict bool
+	}{
+		{"MIT", Permissive, false},
+		{"GPL-3.0-or-later", StrongCopyleft, true},
+		{"GPL-2.0+", StrongCopyleft, true},
+		{"MIT OR GPL-3.0-only", Permissive, false},
+		{"MIT AND AGPL-3.0-only", NetworkCopyleft, true},
+		{"GPL-2.0-only WITH Classpath-exception-2.0", WeakCopyleft, false},
+		{"SEE-LICENSE-IN-LICENSE", UnknownLicense, false},
+	}
+	for _, test := range tests {
+		expression := parseLicenseLeniently(test.license)
+		if category := policy.Category(expression); category != test.category {
+			t.Errorf("Expected %s to be %s, got %s", test.license, test.category, category)
+		}
+		if conflict := policy.Conflicts(Permissive, expression); conflict != test.conflict {
+			t.Errorf("Expected conflict %v for %s in a permissive root, got %v", test.conflict, test.license, conflict)
+		}
+	}
+
+	policy.Denied = []string{"WTFPL"}
+	policy.Categories["SEE-LICENSE-IN-LICENSE"] = StrongCopyleft
+	if !policy.Conflicts(StrongCopyleft, parseLicenseLeniently("WTFPL")) {
+		t.Error("Expected a denied license to conflict with every root")
+	}
+	if policy.Category(parseLicenseLeniently("SEE-LICENSE-IN-LICENSE")) != StrongCopyleft {
+		t.Error("Expected the policy to override the category of a license")
+	}
+}
+
+func TestGetLicenseReport(t *testing.T) {
+	graph, stringIDToNodeInfo, idToNodeInfo, _ := createQueryTestGraph()
+	licenses := map[string]string{
+		"C@1.0.0": "MIT",
+		"B@1.0.0": "MIT",
+		"B@2.0.0": "MIT",
+		"A@1.0.0": "GPL-3.0-only",
+	}
+	report := GetLicenseReport(graph, idToNodeInfo, stringIDToNodeInfo, licenses, "C@1.0.0", DefaultLicensePolicy(), TreeOptions{})
+	if report.Category != Permissive || len(report.Usages) != 2 {
+		t.Fatalf("Expected a permissive root with 2 licenses, got %+v", report)
+	}
+
+	gpl := report.Usages[0]
+	if gpl.License != "GPL-3.0-only" || !gpl.Conflict || len(gpl.Nodes) != 1 {
+		t.Errorf("Expected the GPL license of A first as a conflict, got %+v", gpl)
+	}
+	if len(gpl.Path) != 3 || gpl.Path[0].Name != "C" || gpl.Path[2].Name != "A" {
+		t.Errorf("Expected the path C -> B -> A to introduce the GPL license, got %v", gpl.Path)
+	}
+	if mit := report.Usages[1]; mit.Conflict || len(mit.Nodes) != 2 || len(mit.Path) != 2 {
+		t.Errorf("Expected both versions of B under MIT without conflict, got %+v", mit)
+	}
+}
@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Period is a length of calendar time. Months and years don't have a fixed number of days, so they are kept apart
+// from the days instead of being converted to a time.Duration.
+type Period struct {
+	Years  int
+	Months int
+	Days   int
+}
+
+// ParsePeriod parses a period written as a number followed by y (years), m (months), w (weeks) or d (days), for
+// example 1y or 6m
+func ParsePeriod(value string) (Period, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if len(value) < 2 {
+		return Period{}, fmt.Errorf("invalid period %q, expected a number followed by y, m, w or d", value)
+	}
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n <= 0 {
+		return Period{}, fmt.Errorf("invalid period %q, expected a positive number followed by y, m, w or d", value)
+	}
+	switch value[len(value)-1] {
+	case 'y':
+		return Period{Years: n}, nil
+	case 'm':
+		return Period{Months: n}, nil
+	case 'w':
+		return Period{Days: 7 * n}, nil
+	case 'd':
+		return Period{Days: n}, nil
+	}
+	return Period{}, fmt.Errorf("invalid period %q, expected a number followed by y, m, w or d", value)
+}
+
+// After returns the moment the period after t
+func (p Period) After(t time.Time) time.Time {
+	return t.AddDate(p.Years, p.Months, p.Days)
+}
+
+func (p Period) String() string {
+	var builder strings.Builder
+	for _, part := range []struct {
+		n    int
+		unit string
+	}{{p.Years, "y"}, {p.Months, "m"}, {p.Days, "d"}} {
+		if part.n != 0 {
+			fmt.Fprintf(&builder, "%d%s", part.n, part.unit)
+		}
+	}
+	if builder.Len() == 0 {
+		return "0d"
+	}
+	return builder.String()
+}
+
+// PublishedSpan returns the moments the first and the last version of the graph were published. Both are zero when no
+// version has a valid timestamp.
+func PublishedSpan(nodeMap map[int64]NodeInfo) (time.Time, time.Time) {
+	var first, last time.Time
+	for _, node := range nodeMap {
+		t, err := ParseTimestamp(node.Timestamp)
+		if err != nil {
+			continue
+		}
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+	return first, last
+}
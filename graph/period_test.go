@@ -0,0 +1,16 @@
+package graph
+
+import "testing"
+
+func TestParsePeriod(t *testing.T) {
+	for value, want := range map[string]Period{"1y": {Years: 1}, "6m": {Months: 6}, "2w": {Days: 14}, "30D": {Days: 30}} {
+		if got, err := ParsePeriod(value); err != nil || got != want {
+			t.Errorf("Expected %s to be %+v, got %+v (%v)", value, want, got, err)
+		}
+	}
+	for _, value := range []string{"", "y", "0m", "-1y", "3h"} {
+		if _, err := ParsePeriod(value); err == nil {
+			t.Errorf("Expected %q to be invalid", value)
+		}
+	}
+}
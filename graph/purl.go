@@ -0,0 +1,189 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Ecosystem is the package manager the packages of a graph come from. The values are the package URL types.
+type Ecosystem string
+
+const (
+	NPM   Ecosystem = "npm"
+	Maven Ecosystem = "maven"
+	PyPI  Ecosystem = "pypi"
+	Go    Ecosystem = "golang"
+	Cargo Ecosystem = "cargo"
+)
+
+// Ecosystems are the ecosystems the graph can be created for
+var Ecosystems = []Ecosystem{NPM, Maven, PyPI, Go, Cargo}
+
+// ParseEcosystem returns the ecosystem with the given name
+func ParseEcosystem(name string) (Ecosystem, error) {
+	for _, ecosystem := range Ecosystems {
+		if strings.EqualFold(name, string(ecosystem)) {
+			return ecosystem, nil
+		}
+	}
+	return "", fmt.Errorf("unknown ecosystem %q, expected one of %v", name, Ecosystems)
+}
+
+// NodeKey identifies a version of a package independently of the graph it is in. The namespace is the scope of npm
+// packages (including the @), the group of Maven packages and the module path up to the last element of Go modules,
+// other ecosystems don't use it.
+type NodeKey struct {
+	Ecosystem Ecosystem
+	Namespace string
+	Name      string
+	Version   string
+}
+
+// NewNodeKey splits the package name as it is stored in the graph into the namespace and the name. npm scopes are
+// written as @scope/name, Maven groups as group:artifact and Go modules by their module path.
+func NewNodeKey(ecosystem Ecosystem, packageName string, version string) NodeKey {
+	key := NodeKey{Ecosystem: ecosystem, Name: packageName, Version: version}
+	switch ecosystem {
+	case NPM:
+		if i := strings.Index(packageName, "/"); strings.HasPrefix(packageName, "@") && i > 0 {
+			key.Namespace, key.Name = packageName[:i], packageName[i+1:]
+		}
+	case Maven:
+		if i := strings.Index(packageName, ":"); i > 0 {
+			key.Namespace, key.Name = packageName[:i], packageName[i+1:]
+		}
+	case Go:
+		if i := strings.LastIndex(packageName, "/"); i > 0 {
+			key.Namespace, key.Name = packageName[:i], packageName[i+1:]
+		}
+	}
+	return key
+}
+
+// PackageName joins the namespace and the name the way the package is named in the graph
+func (key NodeKey) PackageName() string {
+	if key.Namespace == "" {
+		return key.Name
+	}
+	if key.Ecosystem == Maven {
+		return key.Namespace + ":" + key.Name
+	}
+	return key.Namespace + "/" + key.Name
+}
+
+// StringID returns the key of the version in the stringID to NodeInfo map
+func (key NodeKey) StringID() string {
+	return StringID(key.PackageName(), key.Version)
+}
+
+// Purl formats the key as a package URL, for example pkg:npm/%40babel/core@7.0.0 or
+// pkg:maven/org.apache.commons/commons-lang3@3.12.0
+func (key NodeKey) Purl() string {
+	var builder strings.Builder
+	builder.WriteString("pkg:")
+	builder.WriteString(string(key.Ecosystem))
+	builder.WriteString("/")
+	if key.Namespace != "" {
+		for _, segment := range strings.Split(key.Namespace, "/") {
+			builder.WriteString(escapePurl(segment))
+			builder.WriteString("/")
+		}
+	}
+	builder.WriteString(escapePurl(key.Name))
+	if key.Version != "" {
+		builder.WriteString("@")
+		builder.WriteString(escapePurl(key.Version))
+	}
+	return builder.String()
+}
+
+func (key NodeKey) String() string {
+	return key.Purl()
+}
+
+// escapePurl percent-encodes a component of a package URL. The @ has to be encoded as well, it separates the version.
+func escapePurl(component string) string {
+	return strings.ReplaceAll(url.PathEscape(component), "@", "%40")
+}
+
+// ParsePurl parses a package URL. Qualifiers and subpaths are ignored, they don't identify a node. The version is
+// empty when the package URL refers to a package instead of one of its versions.
+func ParsePurl(purl string) (NodeKey, error) {
+	if !strings.HasPrefix(strings.ToLower(purl), "pkg:") {
+		return NodeKey{}, fmt.Errorf("%q is not a package URL, it has to start with pkg:", purl)
+	}
+	rest := strings.TrimLeft(purl[len("pkg:"):], "/")
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest = rest[:i]
+	}
+	slash := strings.Index(rest, "/")
+	if slash <= 0 {
+		return NodeKey{}, fmt.Errorf("package URL %q has no type", purl)
+	}
+	key := NodeKey{Ecosystem: Ecosystem(strings.ToLower(rest[:slash]))}
+	rest = strings.Trim(rest[slash+1:], "/")
+
+	// Only an @ after the last / separates the version, an unencoded npm scope also starts with one
+	if at := strings.LastIndex(rest, "@"); at > strings.LastIndex(rest, "/") {
+		version, err := url.PathUnescape(rest[at+1:])
+		if err != nil {
+			return NodeKey{}, fmt.Errorf("package URL %q has an invalid version: %w", purl, err)
+		}
+		key.Version, rest = version, rest[:at]
+	}
+	segments := strings.Split(rest, "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return NodeKey{}, fmt.Errorf("package URL %q is not encoded correctly: %w", purl, err)
+		}
+		segments[i] = unescaped
+	}
+	key.Name = segments[len(segments)-1]
+	key.Namespace = strings.Join(segments[:len(segments)-1], "/")
+	if key.Name == "" {
+		return NodeKey{}, fmt.Errorf("package URL %q has no name", purl)
+	}
+	return key, nil
+}
+
+// Key returns the key of the node, in the ecosystem of the graph it was created in
+func (nodeInfo NodeInfo) Key() NodeKey {
+	return nodeInfo.key
+}
+
+// Purl returns the package URL of the node
+func (nodeInfo NodeInfo) Purl() string {
+	return nodeInfo.key.Purl()
+}
+
+// ErrNodeNotFound is wrapped by the error LookupNode returns when the reference is valid but not in the graph
+var ErrNodeNotFound = errors.New("not found in the graph")
+
+// LookupNode finds the node a reference points to. The reference is either a string id (name@version) or a package
+// URL of the ecosystem of the graph. In a package-level graph the name or a package URL without version is enough.
+func LookupNode(stringIDToNodeInfo map[string]NodeInfo, ecosystem Ecosystem, reference string) (NodeInfo, error) {
+	stringID := reference
+	if strings.HasPrefix(strings.ToLower(reference), "pkg:") {
+		key, err := ParsePurl(reference)
+		if err != nil {
+			return NodeInfo{}, err
+		}
+		if key.Ecosystem != ecosystem {
+			return NodeInfo{}, fmt.Errorf("%s is a %s package URL, but the graph holds %s packages", reference, key.Ecosystem, ecosystem)
+		}
+		if key.Version == "" {
+			key.Version = AllVersions
+		}
+		stringID = key.StringID()
+	} else if _, _, ok := SplitStringID(reference); !ok {
+		stringID = StringID(reference, AllVersions)
+	}
+	node, ok := stringIDToNodeInfo[stringID]
+	if !ok {
+		return NodeInfo{}, fmt.Errorf("package %s was %w", reference, ErrNodeNotFound)
+	}
+	return node, nil
+}
@@ -0,0 +1,104 @@
+package graph
+
+import "testing"
+
+func TestPurl(t *testing.T) {
+	tests := []struct {
+		ecosystem   Ecosystem
+		packageName string
+		version     string
+		purl        string
+	}{
+		{NPM, "lodash", "4.17.21", "pkg:npm/lodash@4.17.21"},
+		{NPM, "@babel/core", "7.0.0", "pkg:npm/%40babel/core@7.0.0"},
+		{Maven, "org.apache.commons:commons-lang3", "3.12.0", "pkg:maven/org.apache.commons/commons-lang3@3.12.0"},
+		{PyPI, "requests", "2.28.1", "pkg:pypi/requests@2.28.1"},
+		{NPM, "ws", "sizzle-0.0.8", "pkg:npm/ws@sizzle-0.0.8"},
+		{Go, "github.com/spf13/cobra", "v1.5.0", "pkg:golang/github.com/spf13/cobra@v1.5.0"},
+		{Cargo, "serde", "1.0.152", "pkg:cargo/serde@1.0.152"},
+	}
+	for _, test := range tests {
+		key := NewNodeKey(test.ecosystem, test.packageName, test.version)
+		if purl := key.Purl(); purl != test.purl {
+			t.Errorf("Expected %s@%s to be formatted as %s, got %s", test.packageName, test.version, test.purl, purl)
+		}
+		parsed, err := ParsePurl(test.purl)
+		if err != nil {
+			t.Errorf("Expected no error for %s, got %v", test.purl, err)
+			continue
+		}
+		if parsed != key || parsed.PackageName() != test.packageName {
+			t.Errorf("Expected %s to parse into %+v, got %+v", test.purl, key, parsed)
+		}
+	}
+
+	t.Run("Accepts an unencoded scope and ignores qualifiers and subpaths", func(t *testing.T) {
+		key, err := ParsePurl("pkg:npm/@babel/core@7.0.0?repository_url=https://example.com#lib")
+		if err != nil || key.PackageName() != "@babel/core" || key.Version != "7.0.0" {
+			t.Errorf("Expected @babel/core 7.0.0, got %+v (%v)", key, err)
+		}
+	})
+
+	for _, purl := range []string{"npm/lodash@4.17.21", "pkg:lodash", "pkg:npm/"} {
+		if _, err := ParsePurl(purl); err == nil {
+			t.Errorf("Expected an error for %q", purl)
+		}
+	}
+}
+
+func TestStringIDs(t *testing.T) {
+	// With a dash as separator these two versions got the same string id
+	if StringID("ws-sizzle", "0.0.8") == StringID("ws", "sizzle-0.0.8") {
+		t.Error("Expected different string ids for different packages")
+	}
+
+	tests := []struct {
+		stringID, name, version string
+		ok                      bool
+	}{
+		{"lodash@4.17.21", "lodash", "4.17.21", true},
+		{"@babel/core@7.0.0", "@babel/core", "7.0.0", true},
+		{"ws@sizzle-0.0.8", "ws", "sizzle-0.0.8", true},
+		{"@babel/core", "", "", false},
+		{"lodash@", "", "", false},
+		{"", "", "", false},
+	}
+	for _, test := range tests {
+		name, version, ok := SplitStringID(test.stringID)
+		if name != test.name || version != test.version || ok != test.ok {
+			t.Errorf("Expected %q to split into %q and %q (%v), got %q and %q (%v)", test.stringID, test.name, test.version, test.ok, name, version, ok)
+		}
+	}
+}
+
+func TestLookupNode(t *testing.T) {
+	_, stringIDToNodeInfo, _, _ := createQueryTestGraph()
+	for _, reference := range []string{"B@2.0.0", "pkg:npm/B@2.0.0"} {
+		if node, err := LookupNode(stringIDToNodeInfo, NPM, reference); err != nil || node.Name != "B" || node.Version != "2.0.0" {
+			t.Errorf("Expected %s to find B@2.0.0, got %v (%v)", reference, node, err)
+		}
+	}
+	for _, reference := range []string{"B@3.0.0", "pkg:maven/B@2.0.0", "pkg:npm/B"} {
+		if _, err := LookupNode(stringIDToNodeInfo, NPM, reference); err == nil {
+			t.Errorf("Expected an error for %s", reference)
+		}
+	}
+}
+
+func TestNodeKeys(t *testing.T) {
+	packagesInfo := []PackageInfo{
+		{Name: "org.example:app", Versions: map[string]VersionInfo{"1.0.0": {Timestamp: "2021-01-01T00:00:00", Dependencies: map[string]string{"org.example:lib": "1.0.0"}}}},
+		{Name: "org.example:lib", Versions: map[string]VersionInfo{"1.0.0": {Timestamp: "2020-01-01T00:00:00"}}},
+	}
+	graph, _, stringIDToNodeInfo, idToNodeInfo, _ := CreateGraphFromPackages(&packagesInfo, Maven)
+	node := stringIDToNodeInfo["org.example:lib@1.0.0"]
+	expected := NodeKey{Ecosystem: Maven, Namespace: "org.example", Name: "lib", Version: "1.0.0"}
+	if node.Key() != expected || node.Purl() != "pkg:maven/org.example/lib@1.0.0" {
+		t.Errorf("Expected the node to carry the key %v, got %v", expected, node.Key())
+	}
+
+	_, packageStringMap, _, _ := CollapseToPackages(graph, idToNodeInfo)
+	if key := packageStringMap["org.example:app@*"].Key(); key.Ecosystem != Maven || key.Namespace != "org.example" {
+		t.Errorf("Expected the package node to keep the ecosystem of the versions, got %v", key)
+	}
+}
@@ -0,0 +1,425 @@
+package graph
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/iterator"
+	"gonum.org/v1/gonum/graph/network"
+	"gonum.org/v1/gonum/graph/simple"
+	"gonum.org/v1/gonum/graph/traverse"
+)
+
+// reversedGraph is a view of a directed graph in which every edge points the other way. It lets the gonum traversals
+// walk from a dependency to its dependents without copying the graph.
+type reversedGraph struct {
+	g *simple.DirectedGraph
+}
+
+func (r reversedGraph) Node(id int64) graph.Node {
+	return r.g.Node(id)
+}
+
+func (r reversedGraph) Nodes() graph.Nodes {
+	return r.g.Nodes()
+}
+
+func (r reversedGraph) From(id int64) graph.Nodes {
+	return r.g.To(id)
+}
+
+func (r reversedGraph) HasEdgeBetween(xid, yid int64) bool {
+	return r.g.HasEdgeBetween(xid, yid)
+}
+
+func (r reversedGraph) Edge(uid, vid int64) graph.Edge {
+	edge := r.g.Edge(vid, uid)
+	if edge == nil {
+		return nil
+	}
+	return edge.ReversedEdge()
+}
+
+// virtualSource is the id of the node sourcedGraph adds, simple graphs only use ids from zero up
+const virtualSource int64 = -1
+
+// sourcedGraph is a view of the reversed graph with an extra node that has an edge to every source. A breadth first
+// search from that node walks from all sources at once and reaches every dependent through its closest source.
+type sourcedGraph struct {
+	reversedGraph
+	sources []graph.Node
+}
+
+func (s sourcedGraph) Node(id int64) graph.Node {
+	if id == virtualSource {
+		return simple.Node(virtualSource)
+	}
+	return s.reversedGraph.Node(id)
+}
+
+func (s sourcedGraph) From(id int64) graph.Nodes {
+	if id == virtualSource {
+		return iterator.NewOrderedNodes(s.sources)
+	}
+	return s.reversedGraph.From(id)
+}
+
+func (s sourcedGraph) Edge(uid, vid int64) graph.Edge {
+	if uid == virtualSource {
+		return simple.Edge{F: simple.Node(uid), T: simple.Node(vid)}
+	}
+	return s.reversedGraph.Edge(uid, vid)
+}
+
+// untilCancelled returns the until function of a depth first walk that ends the walk once the context is cancelled
+func untilCancelled(ctx context.Context) func(graph.Node) bool {
+	return func(graph.Node) bool {
+		return ctx.Err() != nil
+	}
+}
+
+// GetTransitiveDependentsNode returns the specified node and every node that depends on it, directly or transitively.
+func GetTransitiveDependentsNode(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, stringId string) *[]NodeInfo {
+	result, _ := GetTransitiveDependentsNodeContext(context.Background(), g, nodeMap, stringMap, stringId)
+	return result
+}
+
+// GetTransitiveDependentsNodeContext is GetTransitiveDependentsNode that stops walking as soon as the context is
+// cancelled, in which case it returns the error of the context.
+func GetTransitiveDependentsNodeContext(ctx context.Context, g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, stringId string) (*[]NodeInfo, error) {
+	var nodeId int64
+	result := make([]NodeInfo, 0)
+	if id, ok := findNode(stringMap, stringId); ok {
+		nodeId = id
+	} else {
+		return &result, nil // This function is a no-op if we don't have a correct string id
+	}
+
+	w := traverse.DepthFirst{
+		Visit: func(n graph.Node) {
+			result = append(result, nodeMap[n.ID()])
+		},
+	}
+
+	_ = w.Walk(reversedGraph{g}, g.Node(nodeId), untilCancelled(ctx))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetDependencyPath returns the shortest chain of dependencies that leads from the first node to the second one,
+// both included. The result is empty when the second node is not a (transitive) dependency of the first one.
+func GetDependencyPath(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, fromStringId, toStringId string) *[]NodeInfo {
+	result, _ := GetDependencyPathContext(context.Background(), g, nodeMap, stringMap, fromStringId, toStringId)
+	return result
+}
+
+// GetDependencyPathContext is GetDependencyPath that stops searching as soon as the context is cancelled, in which
+// case it returns the error of the context.
+func GetDependencyPathContext(ctx context.Context, g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, fromStringId, toStringId string) (*[]NodeInfo, error) {
+	result := make([]NodeInfo, 0)
+	fromId, fromOk := findNode(stringMap, fromStringId)
+	toId, toOk := findNode(stringMap, toStringId)
+	if !fromOk || !toOk {
+		return &result, nil
+	}
+
+	// The breadth first search reaches every node through the shortest path first, so the first parent is kept
+	parents := make(map[int64]int64)
+	w := traverse.BreadthFirst{
+		Traverse: func(e graph.Edge) bool {
+			if _, ok := parents[e.To().ID()]; !ok && e.To().ID() != fromId {
+				parents[e.To().ID()] = e.From().ID()
+			}
+			return true
+		},
+	}
+	found := w.Walk(g, g.Node(fromId), func(n graph.Node, _ int) bool {
+		return n.ID() == toId || ctx.Err() != nil
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return &result, nil
+	}
+
+	for id := toId; id != fromId; id = parents[id] {
+		result = append(result, nodeMap[id])
+	}
+	result = append(result, nodeMap[fromId])
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return &result, nil
+}
+
+// RankedNode is a node together with its score in a ranking
+type RankedNode struct {
+	NodeInfo
+	Rank float64
+}
+
+// GetMostUsedNodes ranks the nodes with PageRank and returns the limit highest ranked ones. Since edges point from
+// the dependent to the dependency, the rank flows towards the packages that are (transitively) used the most.
+// A limit of zero or less returns every node. The ranks are computed on the sparse graph, so memory and time grow with
+// the number of edges and not with the square of the number of nodes.
+func GetMostUsedNodes(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, limit int) *[]RankedNode {
+	ranks := network.PageRankSparse(g, 0.85, 0.00001)
+	result := make([]RankedNode, 0, len(ranks))
+	for id, rank := range ranks {
+		result = append(result, RankedNode{NodeInfo: nodeMap[id], Rank: rank})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Rank != result[j].Rank {
+			return result[i].Rank > result[j].Rank
+		}
+		return result[i].id < result[j].id
+	})
+	if limit > 0 && limit < len(result) {
+		result = result[:limit]
+	}
+	return &result
+}
+
+// Stats summarizes the size and shape of a graph
+type Stats struct {
+	Nodes    int
+	Edges    int
+	Packages int
+	// Roots are the nodes nothing depends on, Leaves are the nodes without dependencies
+	Roots            int
+	Leaves           int
+	MaxDependencies  int
+	MaxDependents    int
+	MeanDependencies float64
+}
+
+// ComputeStats counts the nodes, edges and packages of the graph and computes its degree statistics
+func ComputeStats(g *simple.DirectedGraph, nameToVersions map[string][]string) Stats {
+	stats := Stats{
+		Nodes:    g.Nodes().Len(),
+		Edges:    g.Edges().Len(),
+		Packages: len(nameToVersions),
+	}
+	nodes := g.Nodes()
+	for nodes.Next() {
+		id := nodes.Node().ID()
+		dependencies := g.From(id).Len()
+		dependents := g.To(id).Len()
+		if dependencies == 0 {
+			stats.Leaves++
+		}
+		if dependents == 0 {
+			stats.Roots++
+		}
+		if dependencies > stats.MaxDependencies {
+			stats.MaxDependencies = dependencies
+		}
+		if dependents > stats.MaxDependents {
+			stats.MaxDependents = dependents
+		}
+	}
+	if stats.Nodes > 0 {
+		stats.MeanDependencies = float64(stats.Edges) / float64(stats.Nodes)
+	}
+	return stats
+}
+
+// GetTransitiveDependenciesNodeInInterval returns the specified node and the dependencies that could have been used
+// in the interval [beginTime, endTime], following the same rules as FilterNode. Unlike FilterNode it leaves the
+// graph untouched, so it is safe to use on a graph that is shared between queries.
+func GetTransitiveDependenciesNodeInInterval(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, stringId string, beginTime, endTime time.Time) *[]NodeInfo {
+	result, _ := GetTransitiveDependenciesNodeInIntervalContext(context.Background(), g, nodeMap, stringMap, stringId, beginTime, endTime)
+	return result
+}
+
+// GetTransitiveDependenciesNodeInIntervalContext is GetTransitiveDependenciesNodeInInterval that stops walking as
+// soon as the context is cancelled, in which case it returns the error of the context.
+func GetTransitiveDependenciesNodeInIntervalContext(ctx context.Context, g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, stringId string, beginTime, endTime time.Time) (*[]NodeInfo, error) {
+	var nodeId int64
+	result := make([]NodeInfo, 0)
+	if id, ok := findNode(stringMap, stringId); ok {
+		nodeId = id
+	} else {
+		return &result, nil // This function is a no-op if we don't have a correct string id
+	}
+
+	withinInterval := make(map[int64]bool, len(nodeMap))
+	connected := make(map[edgeKey]bool)
+	w := initializeTraversal(g, nodeMap, connected, withinInterval, beginTime, endTime)
+	w.Visit = func(n graph.Node) {
+		result = append(result, nodeMap[n.ID()])
+	}
+
+	_ = w.Walk(g, g.Node(nodeId), untilCancelled(ctx))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTransitiveDependentsNodeInInterval returns the specified node and the packages published in the interval
+// [beginTime, endTime] that (transitively) depend on it. A dependent is only followed when it was released after its
+// dependency and the dependency was not yanked or unpublished by then. The graph is left untouched.
+func GetTransitiveDependentsNodeInInterval(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, stringId string, beginTime, endTime time.Time) *[]NodeInfo {
+	result, _ := GetTransitiveDependentsNodeInIntervalContext(context.Background(), g, nodeMap, stringMap, stringId, beginTime, endTime)
+	return result
+}
+
+// GetTransitiveDependentsNodeInIntervalContext is GetTransitiveDependentsNodeInInterval that stops walking as soon as
+// the context is cancelled, in which case it returns the error of the context.
+func GetTransitiveDependentsNodeInIntervalContext(ctx context.Context, g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, stringId string, beginTime, endTime time.Time) (*[]NodeInfo, error) {
+	var nodeId int64
+	result := make([]NodeInfo, 0)
+	if id, ok := findNode(stringMap, stringId); ok {
+		nodeId = id
+	} else {
+		return &result, nil // This function is a no-op if we don't have a correct string id
+	}
+
+	w := traverse.DepthFirst{
+		// In the reversed graph the edge goes from the dependency to the dependent
+		Traverse: func(e graph.Edge) bool {
+			dependencyTime, _ := ParseTimestamp(nodeMap[e.From().ID()].Timestamp)
+			dependentTime, _ := ParseTimestamp(nodeMap[e.To().ID()].Timestamp)
+			return InInterval(dependentTime, beginTime, endTime) && dependentTime.After(dependencyTime) &&
+				nodeMap[e.From().ID()].AvailableAt(dependentTime)
+		},
+		Visit: func(n graph.Node) {
+			result = append(result, nodeMap[n.ID()])
+		},
+	}
+
+	_ = w.Walk(reversedGraph{g}, g.Node(nodeId), untilCancelled(ctx))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Dependency is an edge of the dependency tree of a node: Node is a dependency of Parent, Depth edges away from the
+// root of the tree.
+type Dependency struct {
+	Parent NodeInfo
+	Node   NodeInfo
+	Kind   string
+	Depth  int
+}
+
+// TreeOptions restricts which part of a dependency tree is returned
+type TreeOptions struct {
+	// MaxDepth is the number of edges the tree may be deep, zero or less means unlimited
+	MaxDepth int
+	// Kinds are the kinds of dependencies that are followed, none means all of them
+	Kinds []string
+	// When Interval is set only the edges that could have been used between BeginTime and EndTime are followed
+	Interval  bool
+	BeginTime time.Time
+	EndTime   time.Time
+}
+
+func (options TreeOptions) followsKind(kind string) bool {
+	if len(options.Kinds) == 0 {
+		return true
+	}
+	for _, k := range options.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDependencyTree returns the dependencies of the specified node in breadth first order, every dependency once
+// together with the node through which it was first reached. The time interval uses the same rules as FilterNode.
+func GetDependencyTree(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, stringId string, options TreeOptions) *[]Dependency {
+	result, _ := GetDependencyTreeContext(context.Background(), g, nodeMap, stringMap, stringId, options)
+	return result
+}
+
+// GetDependencyTreeContext is GetDependencyTree that stops walking as soon as the context is cancelled, in which case
+// it returns the error of the context.
+func GetDependencyTreeContext(ctx context.Context, g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, stringId string, options TreeOptions) (*[]Dependency, error) {
+	withinInterval := make(map[int64]bool)
+	if options.Interval {
+		initializeTraversal(g, nodeMap, make(map[edgeKey]bool), withinInterval, options.BeginTime, options.EndTime)
+	}
+	return getTree(ctx, g, nodeMap, stringMap, stringId, options, func(e graph.Edge) bool {
+		if !options.Interval {
+			return true
+		}
+		fromTime, _ := ParseTimestamp(nodeMap[e.From().ID()].Timestamp)
+		toTime, _ := ParseTimestamp(nodeMap[e.To().ID()].Timestamp)
+		return withinInterval[e.To().ID()] && fromTime.After(toTime) && nodeMap[e.To().ID()].AvailableAt(fromTime)
+	})
+}
+
+// GetDependentTree returns the dependents of the specified node in breadth first order, in the same way
+// GetDependencyTree returns its dependencies. In the result Node depends on Parent. The time interval uses the same
+// rules as GetTransitiveDependentsNodeInInterval.
+func GetDependentTree(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, stringId string, options TreeOptions) *[]Dependency {
+	result, _ := GetDependentTreeContext(context.Background(), g, nodeMap, stringMap, stringId, options)
+	return result
+}
+
+// GetDependentTreeContext is GetDependentTree that stops walking as soon as the context is cancelled, in which case
+// it returns the error of the context.
+func GetDependentTreeContext(ctx context.Context, g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, stringId string, options TreeOptions) (*[]Dependency, error) {
+	return getTree(ctx, reversedGraph{g}, nodeMap, stringMap, stringId, options, func(e graph.Edge) bool {
+		if !options.Interval {
+			return true
+		}
+		dependencyTime, _ := ParseTimestamp(nodeMap[e.From().ID()].Timestamp)
+		dependentTime, _ := ParseTimestamp(nodeMap[e.To().ID()].Timestamp)
+		return InInterval(dependentTime, options.BeginTime, options.EndTime) && dependentTime.After(dependencyTime) &&
+			nodeMap[e.From().ID()].AvailableAt(dependentTime)
+	})
+}
+
+// getTree does the breadth first walk for GetDependencyTree and GetDependentTree. The follow function decides
+// whether an edge may be used besides its kind.
+func getTree(ctx context.Context, g traverse.Graph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, stringId string, options TreeOptions, follow func(e graph.Edge) bool) (*[]Dependency, error) {
+	result := make([]Dependency, 0)
+	rootId, ok := findNode(stringMap, stringId)
+	if !ok {
+		return &result, nil // This function is a no-op if we don't have a correct string id
+	}
+
+	// Visit is called right after Traverse accepted the edge that leads to the node, so that edge is remembered
+	var current graph.Edge
+	depths := map[int64]int{rootId: 0}
+	w := traverse.BreadthFirst{
+		Traverse: func(e graph.Edge) bool {
+			if !options.followsKind(EdgeKind(e)) || !follow(e) {
+				return false
+			}
+			current = e
+			return true
+		},
+		Visit: func(n graph.Node) {
+			if n.ID() == rootId {
+				return
+			}
+			parentId := current.From().ID()
+			depths[n.ID()] = depths[parentId] + 1
+			result = append(result, Dependency{
+				Parent: nodeMap[parentId],
+				Node:   nodeMap[n.ID()],
+				Kind:   EdgeKind(current),
+				Depth:  depths[n.ID()],
+			})
+		},
+	}
+	// The walk goes level by level, so once a node at the maximum depth is reached every deeper node can be skipped
+	_ = w.Walk(g, simple.Node(rootId), func(_ graph.Node, depth int) bool {
+		return options.MaxDepth > 0 && depth >= options.MaxDepth || ctx.Err() != nil
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,198 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// createQueryTestGraph creates the graph C -> B -> A where B has two versions that both depend on A
+func createQueryTestGraph() (*simple.DirectedGraph, map[string]NodeInfo, map[int64]NodeInfo, map[string][]string) {
+	packagesInfo := []PackageInfo{
+		{
+			Name: "C",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {
+					Timestamp:    "2022-01-01T00:00:00",
+					Dependencies: map[string]string{"B": ">=1.0.0"},
+				},
+			},
+		},
+		{
+			Name: "B",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {
+					Timestamp:    "2021-01-01T00:00:00",
+					Dependencies: map[string]string{"A": "1.0.0"},
+				},
+				"2.0.0": {
+					Timestamp:    "2021-06-01T00:00:00",
+					Dependencies: map[string]string{"A": "1.0.0"},
+				},
+			},
+		},
+		{
+			Name: "A",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {
+					Timestamp:    "2020-01-01T00:00:00",
+					Dependencies: map[string]string{},
+				},
+			},
+		},
+	}
+	graph := simple.NewDirectedGraph()
+	stringIDToNodeInfo := CreateStringIDToNodeInfoMap(&packagesInfo, graph, NPM)
+	idToNodeInfo := CreateNodeIdToPackageMap(stringIDToNodeInfo)
+	nameToVersions := CreateNameToVersionMap(&packagesInfo)
+	CreateEdges(graph, &packagesInfo, stringIDToNodeInfo, nameToVersions, false)
+	return graph, stringIDToNodeInfo, idToNodeInfo, nameToVersions
+}
+
+func TestCreateEdgesMultipleVersions(t *testing.T) {
+	graph, stringIDToNodeInfo, _, _ := createQueryTestGraph()
+	for _, dependent := range []string{"B@1.0.0", "B@2.0.0"} {
+		if graph.Edge(stringIDToNodeInfo[dependent].id, stringIDToNodeInfo["A@1.0.0"].id) == nil {
+			t.Errorf("Expected an edge from %s to A@1.0.0", dependent)
+		}
+	}
+	if graph.Edges().Len() != 4 {
+		t.Errorf("Expected 4 edges, got %d", graph.Edges().Len())
+	}
+}
+
+func TestGetTransitiveDependentsNode(t *testing.T) {
+	graph, stringIDToNodeInfo, idToNodeInfo, _ := createQueryTestGraph()
+	dependents := GetTransitiveDependentsNode(graph, idToNodeInfo, stringIDToNodeInfo, "A@1.0.0")
+	if len(*dependents) != 4 {
+		t.Errorf("Expected A@1.0.0 and its 3 dependents, got %v", *dependents)
+	}
+}
+
+func TestTraversalsStopWhenCancelled(t *testing.T) {
+	graph, stringIDToNodeInfo, idToNodeInfo, _ := createQueryTestGraph()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := GetTransitiveDependenciesNodeContext(ctx, graph, idToNodeInfo, stringIDToNodeInfo, "C@1.0.0"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the dependencies to be cancelled, got %v", err)
+	}
+	if _, err := GetTransitiveDependentsNodeContext(ctx, graph, idToNodeInfo, stringIDToNodeInfo, "A@1.0.0"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the dependents to be cancelled, got %v", err)
+	}
+	if _, err := GetDependencyPathContext(ctx, graph, idToNodeInfo, stringIDToNodeInfo, "C@1.0.0", "A@1.0.0"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the path search to be cancelled, got %v", err)
+	}
+	if _, err := GetDependencyTreeContext(ctx, graph, idToNodeInfo, stringIDToNodeInfo, "C@1.0.0", TreeOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the dependency tree to be cancelled, got %v", err)
+	}
+}
+
+func TestGetDependencyPath(t *testing.T) {
+	graph, stringIDToNodeInfo, idToNodeInfo, _ := createQueryTestGraph()
+
+	t.Run("Finds the chain from the dependent to the dependency", func(t *testing.T) {
+		path := *GetDependencyPath(graph, idToNodeInfo, stringIDToNodeInfo, "C@1.0.0", "A@1.0.0")
+		if len(path) != 3 || path[0].Name != "C" || path[1].Name != "B" || path[2].Name != "A" {
+			t.Errorf("Expected the path C -> B -> A, got %v", path)
+		}
+	})
+
+	t.Run("Returns nothing when there is no dependency", func(t *testing.T) {
+		if path := *GetDependencyPath(graph, idToNodeInfo, stringIDToNodeInfo, "A@1.0.0", "C@1.0.0"); len(path) != 0 {
+			t.Errorf("Expected no path, got %v", path)
+		}
+	})
+}
+
+func TestFilterGraph(t *testing.T) {
+	graph, stringIDToNodeInfo, idToNodeInfo, _ := createQueryTestGraph()
+	beginTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	FilterGraph(graph, idToNodeInfo, beginTime, endTime)
+
+	// Only B@1.0.0 and A@1.0.0 were published in the interval, so only the edge between them remains
+	if graph.Edges().Len() != 1 {
+		t.Errorf("Expected 1 edge, got %d", graph.Edges().Len())
+	}
+	if graph.Edge(stringIDToNodeInfo["B@1.0.0"].id, stringIDToNodeInfo["A@1.0.0"].id) == nil {
+		t.Error("Expected the edge from B@1.0.0 to A@1.0.0 to remain")
+	}
+}
+
+func TestGetMostUsedNodes(t *testing.T) {
+	graph, _, idToNodeInfo, _ := createQueryTestGraph()
+	ranked := *GetMostUsedNodes(graph, idToNodeInfo, 1)
+	if len(ranked) != 1 || ranked[0].Name != "A" {
+		t.Errorf("Expected A to be the most used package, got %v", ranked)
+	}
+
+	t.Run("Ranks a graph too large for a dense matrix", func(t *testing.T) {
+		// Every version depends on hub, every even one on popular as well. A dense matrix of this graph would take
+		// 20 GB.
+		const versions = 50000
+		large := simple.NewDirectedGraph()
+		nodeMap := make(map[int64]NodeInfo, versions)
+		for id := int64(0); id < versions; id++ {
+			large.AddNode(simple.Node(id))
+			nodeMap[id] = *NewNodeInfo(id, NPM, fmt.Sprintf("package%d", id), "1.0.0", "2020-01-01T00:00:00")
+		}
+		for id := int64(2); id < versions; id++ {
+			large.SetEdge(DependencyEdge{F: simple.Node(id), T: simple.Node(0), Kind: KindRuntime})
+			if id%2 == 0 {
+				large.SetEdge(DependencyEdge{F: simple.Node(id), T: simple.Node(1), Kind: KindRuntime})
+			}
+		}
+		ranked := *GetMostUsedNodes(large, nodeMap, 2)
+		if len(ranked) != 2 || ranked[0].Name != "package0" || ranked[1].Name != "package1" {
+			t.Errorf("Expected package0 and package1 to be the most used packages, got %v", ranked)
+		}
+	})
+}
+
+func TestComputeStats(t *testing.T) {
+	graph, _, _, nameToVersions := createQueryTestGraph()
+	stats := ComputeStats(graph, nameToVersions)
+	if stats.Nodes != 4 || stats.Edges != 4 || stats.Packages != 3 {
+		t.Errorf("Expected 4 nodes, 4 edges and 3 packages, got %+v", stats)
+	}
+	if stats.Roots != 1 || stats.Leaves != 1 || stats.MaxDependents != 2 {
+		t.Errorf("Expected 1 root, 1 leaf and at most 2 dependents, got %+v", stats)
+	}
+}
+
+func TestGetDependencyTree(t *testing.T) {
+	packagesInfo := []PackageInfo{
+		{
+			Name: "B",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {
+					Timestamp:       "2021-01-01T00:00:00",
+					Dependencies:    map[string]string{"A": "1.0.0", "T": "1.0.0"},
+					DependencyKinds: map[string]string{"T": KindDev},
+				},
+			},
+		},
+		{Name: "A", Versions: map[string]VersionInfo{"1.0.0": {Timestamp: "2020-01-01T00:00:00"}}},
+		{Name: "T", Versions: map[string]VersionInfo{"1.0.0": {Timestamp: "2020-01-01T00:00:00"}}},
+	}
+	graph, _, stringIDToNodeInfo, idToNodeInfo, _ := CreateGraphFromPackages(&packagesInfo, NPM)
+
+	t.Run("Stores the kind of the dependency on the edge", func(t *testing.T) {
+		edge := graph.Edge(stringIDToNodeInfo["B@1.0.0"].id, stringIDToNodeInfo["T@1.0.0"].id)
+		if edge == nil || EdgeKind(edge) != KindDev {
+			t.Errorf("Expected a dev dependency on T@1.0.0, got %v", edge)
+		}
+	})
+
+	t.Run("Only follows the requested kinds", func(t *testing.T) {
+		tree := *GetDependencyTree(graph, idToNodeInfo, stringIDToNodeInfo, "B@1.0.0", TreeOptions{Kinds: []string{KindRuntime}})
+		if len(tree) != 1 || tree[0].Node.Name != "A" || tree[0].Kind != KindRuntime || tree[0].Depth != 1 {
+			t.Errorf("Expected only the runtime dependency on A, got %v", tree)
+		}
+	})
+}
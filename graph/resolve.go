@@ -0,0 +1,176 @@
+package graph
+
+import (
+	"sort"
+	"time"
+
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// ResolveDependencies returns for every package the node depends on the version a package manager would have
+// installed at the given moment: the highest version that satisfies the constraint, was published by then and was not
+// yanked or unpublished yet. Like npm it only picks a deprecated version when every other one is deprecated too. The
+// edges of the graph already hold the versions that satisfy the constraints. Packages without such a version are left
+// out, the result maps package names to the dependency on the chosen version.
+func ResolveDependencies(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, ecosystem Ecosystem, node NodeInfo, at time.Time) map[string]Dependency {
+	result := make(map[string]Dependency)
+	dependencies := g.From(node.id)
+	for dependencies.Next() {
+		candidate := nodeMap[dependencies.Node().ID()]
+		published, err := ParseTimestamp(candidate.Timestamp)
+		if err != nil || published.After(at) || !candidate.AvailableAt(at) {
+			continue
+		}
+		if current, ok := result[candidate.Name]; ok && !preferredVersion(ecosystem, candidate, current.Node) {
+			continue
+		}
+		result[candidate.Name] = Dependency{
+			Parent: node,
+			Node:   candidate,
+			Kind:   EdgeKind(g.Edge(node.id, candidate.id)),
+			Depth:  1,
+		}
+	}
+	return result
+}
+
+// preferredVersion tells whether a package manager picks the candidate over the current version: a version that is not
+// deprecated over one that is, and otherwise the higher version
+func preferredVersion(ecosystem Ecosystem, candidate, current NodeInfo) bool {
+	if (candidate.Deprecated == "") != (current.Deprecated == "") {
+		return candidate.Deprecated == ""
+	}
+	return CompareVersions(ecosystem, candidate.Version, current.Version) > 0
+}
+
+// ResolveTree resolves the dependencies of the root at the given moment and then the dependencies of those versions,
+// until every reachable version is resolved. The result is in breadth first order like GetDependencyTree, packages on
+// the same level are ordered by name. A version that is reached twice is only listed the first time.
+func ResolveTree(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, ecosystem Ecosystem, root NodeInfo, at time.Time) []Dependency {
+	result := make([]Dependency, 0)
+	visited := map[int64]bool{root.id: true}
+	queue := []Dependency{{Node: root}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		resolved := ResolveDependencies(g, nodeMap, ecosystem, current.Node, at)
+		names := make([]string, 0, len(resolved))
+		for name := range resolved {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			dependency := resolved[name]
+			if visited[dependency.Node.id] {
+				continue
+			}
+			visited[dependency.Node.id] = true
+			dependency.Depth = current.Depth + 1
+			result = append(result, dependency)
+			queue = append(queue, dependency)
+		}
+	}
+	return result
+}
+
+// MinimalVersionSelection returns the build list of a Go module: every version the root requires, directly or through
+// the go.mod files of the versions it requires, and of every module the highest of those versions. Unlike
+// ResolveDependencies it never picks a version that is newer than a requirement, so the result doesn't depend on when
+// it is built. The edges of a Go graph are the requirements, the root's own module is left out and the result is
+// ordered by name.
+//
+// Like the go command it applies the replace and exclude directives of the root, the main module, to every
+// requirement and ignores those of the other versions: a required version that is excluded is raised to the next
+// version in the graph that is not, a replaced module is built from its replacement and replacements with a local
+// directory are left out. The root's own requirements are taken from the packages, so they can be replaced even when
+// the required version is not in the graph. Deeper requirements are only known through the edges, to versions that
+// are in the graph.
+func MinimalVersionSelection(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, packagesList *[]PackageInfo, nameToVersions map[string][]string, ecosystem Ecosystem, root NodeInfo) []NodeInfo {
+	var main VersionInfo
+	for _, packageInfo := range *packagesList {
+		if packageInfo.Name == root.Name {
+			main = packageInfo.Versions[root.Version]
+			break
+		}
+	}
+	excluded := make(map[string]bool, len(main.Exclude))
+	for _, stringID := range main.Exclude {
+		excluded[stringID] = true
+	}
+	// use returns the version that is built for a requirement, if any
+	use := func(name, version string) (NodeInfo, bool) {
+		if excluded[StringID(name, version)] {
+			next := ""
+			for _, candidate := range nameToVersions[name] {
+				if CompareVersions(ecosystem, candidate, version) > 0 && !excluded[StringID(name, candidate)] &&
+					(next == "" || CompareVersions(ecosystem, candidate, next) < 0) {
+					next = candidate
+				}
+			}
+			if next == "" {
+				return NodeInfo{}, false
+			}
+			version = next
+		}
+		// A replacement of the exact version takes precedence over one of the whole module. A local directory is not in
+		// the graph.
+		replacement, ok := main.Replace[StringID(name, version)]
+		if !ok {
+			replacement, ok = main.Replace[name]
+		}
+		if !ok {
+			replacement = StringID(name, version)
+		}
+		node, found := stringMap[replacement]
+		return node, found
+	}
+	requirements := func(current NodeInfo) []NodeInfo {
+		var result []NodeInfo
+		add := func(name, version string) {
+			if node, ok := use(name, version); ok {
+				result = append(result, node)
+			}
+		}
+		if current.id == root.id && main.Dependencies != nil {
+			for name, version := range main.Dependencies {
+				add(name, version)
+			}
+			return result
+		}
+		edges := g.From(current.id)
+		for edges.Next() {
+			required := nodeMap[edges.Node().ID()]
+			add(required.Name, required.Version)
+		}
+		return result
+	}
+
+	selected := make(map[string]NodeInfo)
+	visited := map[int64]bool{root.id: true}
+	queue := []NodeInfo{root}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, required := range requirements(current) {
+			if visited[required.id] {
+				continue
+			}
+			visited[required.id] = true
+			queue = append(queue, required)
+			if required.Name == root.Name {
+				continue
+			}
+			if current, ok := selected[required.Name]; !ok || CompareVersions(ecosystem, required.Version, current.Version) > 0 {
+				selected[required.Name] = required
+			}
+		}
+	}
+	result := make([]NodeInfo, 0, len(selected))
+	for _, node := range selected {
+		result = append(result, node)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
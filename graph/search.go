@@ -0,0 +1,232 @@
+package graph
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+)
+
+// VersionOrder is the order in which SearchIndex.Versions returns the versions of a package
+type VersionOrder string
+
+const (
+	// ByDate orders versions by their timestamp, oldest first
+	ByDate VersionOrder = "date"
+	// BySemver orders versions by semantic version, lowest first. Versions that are not valid semantic versions come
+	// last, ordered by their timestamp.
+	BySemver VersionOrder = "semver"
+)
+
+// ParseVersionOrder returns the order with the given name, an empty name means ByDate
+func ParseVersionOrder(name string) (VersionOrder, error) {
+	switch VersionOrder(strings.ToLower(name)) {
+	case "", ByDate:
+		return ByDate, nil
+	case BySemver:
+		return BySemver, nil
+	}
+	return "", fmt.Errorf("unknown version order %q, expected %s or %s", name, ByDate, BySemver)
+}
+
+// SearchIndex finds package names by prefix or with a fuzzy match, both ignore case. The index is built once and only
+// read afterwards, so it can be shared between goroutines.
+type SearchIndex struct {
+	// lower holds the names in lower case, sorted, and names the same names as they are written
+	names              []string
+	lower              []string
+	nameToVersions     map[string][]string
+	stringIDToNodeInfo map[string]NodeInfo
+}
+
+// NewSearchIndex creates the index over the packages of a graph
+func NewSearchIndex(stringIDToNodeInfo map[string]NodeInfo, nameToVersions map[string][]string) *SearchIndex {
+	type indexedName struct {
+		name, lower string
+	}
+	indexed := make([]indexedName, 0, len(nameToVersions))
+	for name := range nameToVersions {
+		indexed = append(indexed, indexedName{name: name, lower: strings.ToLower(name)})
+	}
+	sort.Slice(indexed, func(i, j int) bool {
+		if indexed[i].lower != indexed[j].lower {
+			return indexed[i].lower < indexed[j].lower
+		}
+		return indexed[i].name < indexed[j].name
+	})
+	names := make([]string, len(indexed))
+	lower := make([]string, len(indexed))
+	for i, n := range indexed {
+		names[i], lower[i] = n.name, n.lower
+	}
+	return &SearchIndex{names: names, lower: lower, nameToVersions: nameToVersions, stringIDToNodeInfo: stringIDToNodeInfo}
+}
+
+// Names returns every package name, sorted without regard to case. The slice is shared and must not be modified.
+func (s *SearchIndex) Names() []string {
+	return s.names
+}
+
+// Prefix returns the package names that start with the prefix, ignoring case like Search, in the order of Names. It
+// uses a binary search, so it is fast even for millions of packages. The slice is shared and must not be modified.
+func (s *SearchIndex) Prefix(prefix string) []string {
+	prefix = strings.ToLower(prefix)
+	begin := sort.SearchStrings(s.lower, prefix)
+	end := begin
+	for end < len(s.lower) && strings.HasPrefix(s.lower[end], prefix) {
+		end++
+	}
+	return s.names[begin:end]
+}
+
+// match is a package name together with how well it matches a query, lower scores are better
+type match struct {
+	name  string
+	score int
+}
+
+// less orders matches by score, then by length, then by name
+func (m match) less(other match) bool {
+	if m.score != other.score {
+		return m.score < other.score
+	}
+	if len(m.name) != len(other.name) {
+		return len(m.name) < len(other.name)
+	}
+	return m.name < other.name
+}
+
+// matchHeap keeps the worst match on top, so the best limit matches can be kept while scanning
+type matchHeap []match
+
+func (h matchHeap) Len() int            { return len(h) }
+func (h matchHeap) Less(i, j int) bool  { return h[j].less(h[i]) }
+func (h matchHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *matchHeap) Push(x interface{}) { *h = append(*h, x.(match)) }
+func (h *matchHeap) Pop() interface{} {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// Search returns at most limit package names that match the query, best match first. The match ignores case. Exact
+// matches come first, then names that start with the query, then names that contain it after a separator such as
+// the / of a scope or the : of a Maven group, then names that contain it anywhere and finally names that contain
+// its characters in order. Within the same kind of match shorter names come first.
+func (s *SearchIndex) Search(query string, limit int) []string {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" || limit <= 0 {
+		return []string{}
+	}
+	best := make(matchHeap, 0, limit)
+	for i, name := range s.lower {
+		score, ok := fuzzyScore(name, query)
+		if !ok {
+			continue
+		}
+		m := match{name: s.names[i], score: score}
+		if len(best) < limit {
+			heap.Push(&best, m)
+		} else if m.less(best[0]) {
+			best[0] = m
+			heap.Fix(&best, 0)
+		}
+	}
+	sort.Slice(best, func(i, j int) bool {
+		return best[i].less(best[j])
+	})
+	names := make([]string, len(best))
+	for i, m := range best {
+		names[i] = m.name
+	}
+	return names
+}
+
+// fuzzyScore scores how well the lower case name matches the lower case query. The boolean is false when it doesn't
+// match at all.
+func fuzzyScore(name string, query string) (int, bool) {
+	switch {
+	case name == query:
+		return 0, true
+	case strings.HasPrefix(name, query):
+		return 1, true
+	}
+	index := strings.Index(name, query)
+	if index > 0 {
+		for index >= 0 {
+			if strings.ContainsRune("/:.-_@", rune(name[index-1])) {
+				return 2, true
+			}
+			next := strings.Index(name[index+1:], query)
+			if next < 0 {
+				break
+			}
+			index += next + 1
+		}
+		return 3, true
+	}
+	// Check whether the characters of the query appear in order
+	position := 0
+	for i := 0; i < len(name) && position < len(query); i++ {
+		if name[i] == query[position] {
+			position++
+		}
+	}
+	return 4, position == len(query)
+}
+
+// Versions returns the nodes of every version of the package in the given order. It returns nil when the package is
+// not in the graph.
+func (s *SearchIndex) Versions(name string, order VersionOrder) []NodeInfo {
+	versions, ok := s.nameToVersions[name]
+	if !ok {
+		return nil
+	}
+	nodes := make([]NodeInfo, 0, len(versions))
+	for _, version := range versions {
+		nodes = append(nodes, s.stringIDToNodeInfo[StringID(name, version)])
+	}
+	// The timestamps are parsed once, versions whose timestamp can't be parsed come last
+	published := make(map[string]time.Time, len(nodes))
+	for _, node := range nodes {
+		if t, err := ParseTimestamp(node.Timestamp); err == nil {
+			published[node.Version] = t
+		}
+	}
+	publishedBefore := func(a, b NodeInfo) bool {
+		ta, okA := published[a.Version]
+		tb, okB := published[b.Version]
+		if okA && okB {
+			return ta.Before(tb)
+		}
+		return okA && !okB
+	}
+	if order != BySemver {
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return publishedBefore(nodes[i], nodes[j])
+		})
+		return nodes
+	}
+
+	parsed := make(map[string]*semver.Version, len(nodes))
+	for _, node := range nodes {
+		if v, err := semver.NewVersion(node.Version); err == nil {
+			parsed[node.Version] = v
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		vi, vj := parsed[nodes[i].Version], parsed[nodes[j].Version]
+		switch {
+		case vi != nil && vj != nil:
+			return vi.LessThan(vj)
+		case vi != nil || vj != nil:
+			return vi != nil
+		}
+		return publishedBefore(nodes[i], nodes[j])
+	})
+	return nodes
+}
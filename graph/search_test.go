@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func createSearchIndex() *SearchIndex {
+	packagesInfo := []PackageInfo{
+		{Name: "react", Versions: map[string]VersionInfo{
+			"16.0.0":      {Timestamp: "2017-09-26T00:00:00"},
+			"9.0.0":       {Timestamp: "2018-01-01T00:00:00"},
+			"18.0.0-rc.0": {Timestamp: "2021-11-15T00:00:00"},
+			"latest":      {Timestamp: "2022-01-01T00:00:00"},
+		}},
+		{Name: "react-dom", Versions: map[string]VersionInfo{"16.0.0": {Timestamp: "2017-09-26T00:00:00"}}},
+		{Name: "@types/react", Versions: map[string]VersionInfo{"16.0.0": {Timestamp: "2017-09-26T00:00:00"}}},
+		{Name: "preact", Versions: map[string]VersionInfo{"10.0.0": {Timestamp: "2019-10-01T00:00:00"}}},
+		{Name: "Reactor", Versions: map[string]VersionInfo{"1.0.0": {Timestamp: "2015-01-01T00:00:00"}}},
+		{Name: "rxjs", Versions: map[string]VersionInfo{
+			"7.0.0": {Timestamp: "2021-04-29"},
+			"7.0.1": {Timestamp: "2021-05-03T08:00:00Z"},
+			"7.1.0": {Timestamp: "2021-05-03T09:00:00+02:00"},
+		}},
+	}
+	_, _, stringIDToNodeInfo, _, nameToVersions := CreateGraphFromPackages(&packagesInfo, NPM)
+	return NewSearchIndex(stringIDToNodeInfo, nameToVersions)
+}
+
+func TestSearchIndexPrefix(t *testing.T) {
+	index := createSearchIndex()
+	if names := index.Prefix("react"); !reflect.DeepEqual(names, []string{"react", "react-dom", "Reactor"}) {
+		t.Errorf("Expected react, react-dom and Reactor, got %v", names)
+	}
+	if names := index.Prefix("REACTO"); !reflect.DeepEqual(names, []string{"Reactor"}) {
+		t.Errorf("Expected Reactor, got %v", names)
+	}
+	if names := index.Prefix("vue"); len(names) != 0 {
+		t.Errorf("Expected no names, got %v", names)
+	}
+}
+
+func TestSearchIndexSearch(t *testing.T) {
+	index := createSearchIndex()
+
+	t.Run("Orders exact, prefix, separator, substring and subsequence matches", func(t *testing.T) {
+		expected := []string{"react", "Reactor", "react-dom", "@types/react", "preact"}
+		if names := index.Search("React", 10); !reflect.DeepEqual(names, expected) {
+			t.Errorf("Expected %v, got %v", expected, names)
+		}
+		if names := index.Search("rjs", 10); !reflect.DeepEqual(names, []string{"rxjs"}) {
+			t.Errorf("Expected the subsequence match rxjs, got %v", names)
+		}
+	})
+
+	t.Run("Keeps only the best matches", func(t *testing.T) {
+		if names := index.Search("react", 2); !reflect.DeepEqual(names, []string{"react", "Reactor"}) {
+			t.Errorf("Expected react and Reactor, got %v", names)
+		}
+	})
+}
+
+func TestSearchIndexVersions(t *testing.T) {
+	index := createSearchIndex()
+	versions := func(order VersionOrder) []string {
+		var result []string
+		for _, node := range index.Versions("react", order) {
+			result = append(result, node.Version)
+		}
+		return result
+	}
+	if bySemver := versions(BySemver); !reflect.DeepEqual(bySemver, []string{"9.0.0", "16.0.0", "18.0.0-rc.0", "latest"}) {
+		t.Errorf("Expected the versions in semver order, got %v", bySemver)
+	}
+	if byDate := versions(ByDate); !reflect.DeepEqual(byDate, []string{"16.0.0", "9.0.0", "18.0.0-rc.0", "latest"}) {
+		t.Errorf("Expected the versions in date order, got %v", byDate)
+	}
+	var rxjs []string
+	for _, node := range index.Versions("rxjs", ByDate) {
+		rxjs = append(rxjs, node.Version)
+	}
+	if !reflect.DeepEqual(rxjs, []string{"7.0.0", "7.1.0", "7.0.1"}) {
+		t.Errorf("Expected the versions in date order across time zones and layouts, got %v", rxjs)
+	}
+	if index.Versions("vue", ByDate) != nil {
+		t.Error("Expected no versions for an unknown package")
+	}
+}
@@ -0,0 +1,110 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/semver"
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Release is a version of a package that is not in the graph, for example one that is about to be published
+type Release struct {
+	Name    string
+	Version string
+	VersionInfo
+}
+
+// AffectedDependent is an existing version whose constraint on the package of a release matches the release
+type AffectedDependent struct {
+	Node       NodeInfo
+	Constraint string
+	// Current is the highest version of the package the constraint matches now. It is the zero NodeInfo when no
+	// version matched before.
+	Current NodeInfo
+	// Adopts is true when the release is higher than Current, so the dependent would pick the release up on its next
+	// install
+	Adopts bool
+}
+
+// ReleaseImpact is what publishing a release would change in the graph
+type ReleaseImpact struct {
+	Release NodeInfo
+	// Dependencies are the existing versions the constraints of the release match, ordered by string id
+	Dependencies []NodeInfo
+	// Dependents are ordered by string id
+	Dependents []AffectedDependent
+	// Affected is the number of versions that would depend on the release, directly or transitively, through the
+	// dependents that adopt it
+	Affected int
+}
+
+// SimulateRelease computes the impact of publishing a release without adding it to the graph: the existing versions
+// whose constraints would match it and how many versions would transitively depend on it. The constraints are
+// evaluated the same way CreateEdges does, so the packages the graph was created from are needed.
+func SimulateRelease(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, packagesList *[]PackageInfo, nameToVersions map[string][]string, ecosystem Ecosystem, release Release) (*ReleaseImpact, error) {
+	stringID := StringID(release.Name, release.Version)
+	if _, ok := stringMap[stringID]; ok {
+		return nil, fmt.Errorf("%s is already in the graph", stringID)
+	}
+	version, err := semver.NewVersion(release.Version)
+	if err != nil {
+		return nil, fmt.Errorf("version %s of %s is not a semantic version, no constraint can match it", release.Version, release.Name)
+	}
+	isMaven := ecosystem == Maven
+	impact := &ReleaseImpact{
+		// The release is not a node of the graph, it gets the id no node uses
+		Release:      *NewNodeInfo(virtualSource, ecosystem, release.Name, release.Version, release.Timestamp),
+		Dependencies: make([]NodeInfo, 0),
+		Dependents:   make([]AffectedDependent, 0),
+	}
+
+	for name, requirement := range release.Dependencies {
+		constraint, err := parseConstraint(requirement, isMaven)
+		if err != nil || name == release.Name {
+			continue
+		}
+		for _, v := range nameToVersions[name] {
+			if candidate, err := semver.NewVersion(v); err == nil && constraint.Check(candidate) {
+				impact.Dependencies = append(impact.Dependencies, stringMap[StringID(name, v)])
+			}
+		}
+	}
+	sortNodesByStringID(impact.Dependencies)
+
+	var adopters []graph.Node
+	for _, packageInfo := range *packagesList {
+		if packageInfo.Name == release.Name {
+			continue
+		}
+		for packageVersion, versionInfo := range packageInfo.Versions {
+			requirement, ok := versionInfo.Dependencies[release.Name]
+			if !ok {
+				continue
+			}
+			constraint, err := parseConstraint(requirement, isMaven)
+			if err != nil || !constraint.Check(version) {
+				continue
+			}
+			dependent := AffectedDependent{Node: stringMap[StringID(packageInfo.Name, packageVersion)], Constraint: requirement}
+			dependencies := g.From(dependent.Node.id)
+			for dependencies.Next() {
+				candidate := nodeMap[dependencies.Node().ID()]
+				if candidate.Name == release.Name && (dependent.Current.Name == "" || CompareVersions(ecosystem, candidate.Version, dependent.Current.Version) > 0) {
+					dependent.Current = candidate
+				}
+			}
+			dependent.Adopts = dependent.Current.Name == "" || CompareVersions(ecosystem, release.Version, dependent.Current.Version) > 0
+			if dependent.Adopts {
+				adopters = append(adopters, g.Node(dependent.Node.id))
+			}
+			impact.Dependents = append(impact.Dependents, dependent)
+		}
+	}
+	sort.Slice(impact.Dependents, func(i, j int) bool {
+		return impact.Dependents[i].Node.stringID < impact.Dependents[j].Node.stringID
+	})
+	impact.Affected = len(adopters) + countDependents(g, adopters)
+	return impact, nil
+}
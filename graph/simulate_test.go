@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"testing"
+)
+
+func TestSimulateRelease(t *testing.T) {
+	packagesInfo := []PackageInfo{
+		{Name: "A", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2020-01-01T00:00:00"},
+			"2.0.0": {Timestamp: "2021-01-01T00:00:00"},
+		}},
+		{Name: "B", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-01-01T00:00:00", Dependencies: map[string]string{"A": "^1.0.0"}},
+			"2.0.0": {Timestamp: "2021-02-01T00:00:00", Dependencies: map[string]string{"A": "^2.0.0"}},
+		}},
+		{Name: "C", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-03-01T00:00:00", Dependencies: map[string]string{"B": "^1.0.0"}},
+		}},
+		{Name: "D", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-03-01T00:00:00", Dependencies: map[string]string{"A": ">=1.0.0"}},
+		}},
+		{Name: "E", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-03-01T00:00:00", Dependencies: map[string]string{"A": "^1.2.0"}},
+		}},
+	}
+	graph, packagesList, stringIDToNodeInfo, idToNodeInfo, nameToVersions := CreateGraphFromPackages(&packagesInfo, NPM)
+	edges := graph.Edges().Len()
+
+	release := Release{Name: "A", Version: "1.5.0", VersionInfo: VersionInfo{
+		Timestamp:    "2022-01-01T00:00:00",
+		Dependencies: map[string]string{"D": "^1.0.0"},
+	}}
+	impact, err := SimulateRelease(graph, idToNodeInfo, stringIDToNodeInfo, packagesList, nameToVersions, NPM, release)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(impact.Dependencies) != 1 || impact.Dependencies[0].Name != "D" {
+		t.Errorf("Expected the release to depend on D@1.0.0, got %v", impact.Dependencies)
+	}
+	// B@2.0.0 needs A 2.x, D already resolves to A@2.0.0 and E had no matching version before
+	if len(impact.Dependents) != 3 {
+		t.Fatalf("Expected B@1.0.0, D@1.0.0 and E@1.0.0 to match the release, got %+v", impact.Dependents)
+	}
+	for i, want := range []struct {
+		name    string
+		current string
+		adopts  bool
+	}{{"B", "1.0.0", true}, {"D", "2.0.0", false}, {"E", "", true}} {
+		dependent := impact.Dependents[i]
+		if dependent.Node.Name != want.name || dependent.Current.Version != want.current || dependent.Adopts != want.adopts {
+			t.Errorf("Expected %s to resolve to %q and adopt the release %v, got %+v", want.name, want.current, want.adopts, dependent)
+		}
+	}
+	// B@1.0.0 and E@1.0.0 adopt the release, C@1.0.0 depends on B@1.0.0
+	if impact.Affected != 3 {
+		t.Errorf("Expected 3 affected versions, got %d", impact.Affected)
+	}
+	if graph.Edges().Len() != edges || len(stringIDToNodeInfo) != 7 {
+		t.Errorf("Expected the graph to stay the same")
+	}
+
+	if _, err := SimulateRelease(graph, idToNodeInfo, stringIDToNodeInfo, packagesList, nameToVersions, NPM, Release{Name: "A", Version: "1.0.0"}); err == nil {
+		t.Errorf("Expected an error for a version that is already in the graph")
+	}
+}
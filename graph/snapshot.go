@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"os"
+
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// snapshotNode holds the fields of NodeInfo, gob only encodes exported fields
+type snapshotNode struct {
+	ID        int64
+	Name      string
+	Version   string
+	Timestamp string
+	Author    string
+	Status    VersionStatus
+}
+
+type snapshotEdge struct {
+	From, To int64
+	Kind     string
+}
+
+// snapshot is what gets written to disk. The packages are kept so the constraints of the dependencies are still
+// available after loading, the edges are kept so they don't have to be recomputed from those constraints.
+type snapshot struct {
+	Ecosystem Ecosystem
+	Packages  []PackageInfo
+	Nodes     []snapshotNode
+	Edges     []snapshotEdge
+}
+
+// SaveSnapshot writes the graph and the packages it was created from to a gzipped gob file, so it can be loaded
+// again without parsing the input and creating the edges.
+func SaveSnapshot(path string, g *simple.DirectedGraph, packagesList *[]PackageInfo, nodeMap map[int64]NodeInfo, ecosystem Ecosystem) error {
+	s := snapshot{
+		Ecosystem: ecosystem,
+		Packages:  *packagesList,
+		Nodes:     make([]snapshotNode, 0, len(nodeMap)),
+		Edges:     make([]snapshotEdge, 0, g.Edges().Len()),
+	}
+	for id, node := range nodeMap {
+		s.Nodes = append(s.Nodes, snapshotNode{ID: id, Name: node.Name, Version: node.Version, Timestamp: node.Timestamp, Author: node.Author, Status: node.VersionStatus})
+	}
+	edges := g.Edges()
+	for edges.Next() {
+		edge := edges.Edge()
+		s.Edges = append(s.Edges, snapshotEdge{From: edge.From().ID(), To: edge.To().ID(), Kind: EdgeKind(edge)})
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	zw := gzip.NewWriter(file)
+	if err := gob.NewEncoder(zw).Encode(&s); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// LoadSnapshot reads a file written by SaveSnapshot. It returns the same values as CreateGraph together with the
+// ecosystem of the packages.
+func LoadSnapshot(path string) (*simple.DirectedGraph, *[]PackageInfo, map[string]NodeInfo, map[int64]NodeInfo, map[string][]string, Ecosystem, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, nil, nil, nil, "", err
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, nil, nil, nil, nil, "", err
+	}
+	var s snapshot
+	if err := gob.NewDecoder(zr).Decode(&s); err != nil {
+		return nil, nil, nil, nil, nil, "", err
+	}
+
+	// The versions are taken from the nodes, a snapshot of a graph created with CreateGraphStreaming has no packages
+	graph := simple.NewDirectedGraph()
+	stringIDToNodeInfo := make(map[string]NodeInfo, len(s.Nodes))
+	nameToVersions := make(map[string][]string)
+	for _, node := range s.Nodes {
+		nameToVersions[node.Name] = append(nameToVersions[node.Name], node.Version)
+		graph.AddNode(simple.Node(node.ID))
+		nodeInfo := *NewNodeInfo(node.ID, s.Ecosystem, node.Name, node.Version, node.Timestamp)
+		nodeInfo.Author = node.Author
+		nodeInfo.VersionStatus = node.Status
+		stringIDToNodeInfo[StringID(node.Name, node.Version)] = nodeInfo
+	}
+	for _, edge := range s.Edges {
+		graph.SetEdge(DependencyEdge{F: simple.Node(edge.From), T: simple.Node(edge.To), Kind: edge.Kind})
+	}
+	idToNodeInfo := CreateNodeIdToPackageMap(stringIDToNodeInfo)
+	return graph, &s.Packages, stringIDToNodeInfo, idToNodeInfo, nameToVersions, s.Ecosystem, nil
+}
@@ -0,0 +1,46 @@
+package graph
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	graph, stringIDToNodeInfo, idToNodeInfo, _ := createQueryTestGraph()
+	for stringID, node := range stringIDToNodeInfo {
+		node.Yanked = true
+		node.Author = "alice, bob"
+		stringIDToNodeInfo[stringID], idToNodeInfo[node.id] = node, node
+		break
+	}
+	packagesInfo := []PackageInfo{{Name: "A", Versions: map[string]VersionInfo{"1.0.0": {Timestamp: "2020-01-01T00:00:00"}}}}
+	path := filepath.Join(t.TempDir(), "graph.snapshot")
+	if err := SaveSnapshot(path, graph, &packagesInfo, idToNodeInfo, PyPI); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	loaded, loadedPackages, loadedStringMap, _, loadedNameToVersions, ecosystem, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ecosystem != PyPI || len(*loadedPackages) != 1 {
+		t.Error("Expected the snapshot to keep the ecosystem and the packages")
+	}
+	if loaded.Nodes().Len() != graph.Nodes().Len() || loaded.Edges().Len() != graph.Edges().Len() {
+		t.Errorf("Expected %d nodes and %d edges, got %d and %d", graph.Nodes().Len(), graph.Edges().Len(), loaded.Nodes().Len(), loaded.Edges().Len())
+	}
+	versions := 0
+	for _, packageVersions := range loadedNameToVersions {
+		versions += len(packageVersions)
+	}
+	if versions != len(stringIDToNodeInfo) {
+		t.Errorf("Expected the versions of all %d nodes, not only of the saved packages, got %v", len(stringIDToNodeInfo), loadedNameToVersions)
+	}
+	for stringID, node := range stringIDToNodeInfo {
+		// The nodes get their key in the ecosystem of the snapshot
+		node.key = NewNodeKey(PyPI, node.Name, node.Version)
+		if loadedNode, ok := loadedStringMap[stringID]; !ok || loadedNode != node {
+			t.Errorf("Expected node %s to be loaded unchanged, got %v", stringID, loadedNode)
+		}
+	}
+}
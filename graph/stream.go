@@ -0,0 +1,75 @@
+package graph
+
+import "gonum.org/v1/gonum/graph/simple"
+
+// PackageReader calls handle for every package of an input, one at a time, and stops at the first error handle
+// returns. CreateGraphStreaming reads the input twice, so every call has to start at the beginning of the input.
+type PackageReader func(handle func(PackageInfo) error) error
+
+// StreamProgress tells how far CreateGraphStreaming got. The first pass creates the nodes, the second the edges.
+type StreamProgress struct {
+	Pass     int
+	Packages int
+	Versions int
+	Done     bool
+}
+
+// progressInterval is the number of packages between two progress reports
+const progressInterval = 100000
+
+// CreateGraphStreaming creates the same graph as CreateGraphFromPackages without holding all the packages in memory.
+// The first pass over the input creates a node for every version, the second pass creates the edges of every package
+// as soon as it is read, since by then all the versions they can point to are known. Only the nodes and the edges are
+// kept, so commands that need the constraints or the other details of the packages can't be used on the result.
+// Progress is called every progressInterval packages and at the end of both passes, it may be nil.
+func CreateGraphStreaming(read PackageReader, ecosystem Ecosystem, progress func(StreamProgress)) (*simple.DirectedGraph, map[string]NodeInfo, map[int64]NodeInfo, map[string][]string, error) {
+	graph := simple.NewDirectedGraph()
+	stringIDToNodeInfo := make(map[string]NodeInfo)
+	nameToVersions := make(map[string][]string)
+	report := func(p StreamProgress) {
+		if progress != nil && (p.Done || p.Packages%progressInterval == 0) {
+			progress(p)
+		}
+	}
+
+	first := StreamProgress{Pass: 1}
+	err := read(func(packageInfo PackageInfo) error {
+		for version, versionInfo := range packageInfo.Versions {
+			stringID := StringID(packageInfo.Name, version)
+			if _, ok := stringIDToNodeInfo[stringID]; ok {
+				continue
+			}
+			newNode := graph.NewNode()
+			graph.AddNode(newNode)
+			nodeInfo := *NewNodeInfo(newNode.ID(), ecosystem, packageInfo.Name, version, versionInfo.Timestamp)
+			nodeInfo.Author = versionInfo.Author
+			nodeInfo.VersionStatus = versionInfo.VersionStatus
+			stringIDToNodeInfo[stringID] = nodeInfo
+			nameToVersions[packageInfo.Name] = append(nameToVersions[packageInfo.Name], version)
+			first.Versions++
+		}
+		first.Packages++
+		report(first)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	first.Done = true
+	report(first)
+
+	second := StreamProgress{Pass: 2}
+	err = read(func(packageInfo PackageInfo) error {
+		createPackageEdges(graph, packageInfo, stringIDToNodeInfo, nameToVersions, ecosystem == Maven)
+		second.Packages++
+		second.Versions += len(packageInfo.Versions)
+		report(second)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	second.Done = true
+	report(second)
+	return graph, stringIDToNodeInfo, CreateNodeIdToPackageMap(stringIDToNodeInfo), nameToVersions, nil
+}
@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCreateGraphStreaming(t *testing.T) {
+	packagesInfo := []PackageInfo{
+		{Name: "A", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2020-01-01T00:00:00"},
+			"1.1.0": {Timestamp: "2020-06-01T00:00:00", VersionStatus: VersionStatus{Yanked: true}},
+		}},
+		{Name: "B", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-01-01T00:00:00", Dependencies: map[string]string{"A": "^1.0.0", "C": "*"}, DependencyKinds: map[string]string{"C": KindDev}},
+		}},
+		{Name: "C", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-03-01T00:00:00", Dependencies: map[string]string{"B": "^1.0.0"}},
+		}},
+	}
+	expected, _, _, expectedNodeMap, _ := CreateGraphFromPackages(&packagesInfo, NPM)
+
+	passes := 0
+	read := func(handle func(PackageInfo) error) error {
+		passes++
+		for _, packageInfo := range packagesInfo {
+			if err := handle(packageInfo); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var reports []StreamProgress
+	graph, stringIDToNodeInfo, idToNodeInfo, nameToVersions, err := CreateGraphStreaming(read, NPM, func(progress StreamProgress) {
+		reports = append(reports, progress)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passes != 2 || len(reports) != 2 || reports[0] != (StreamProgress{Pass: 1, Packages: 3, Versions: 4, Done: true}) || reports[1].Pass != 2 {
+		t.Errorf("Expected two passes that are reported when they are done, got %d and %+v", passes, reports)
+	}
+	if len(stringIDToNodeInfo) != 4 || len(idToNodeInfo) != 4 || len(nameToVersions["A"]) != 2 || !stringIDToNodeInfo["A@1.1.0"].Yanked {
+		t.Errorf("Expected the 4 versions in the maps with their status, got %v", stringIDToNodeInfo)
+	}
+	if graph.Edges().Len() != expected.Edges().Len() {
+		t.Fatalf("Expected %d edges like CreateGraphFromPackages, got %d", expected.Edges().Len(), graph.Edges().Len())
+	}
+	edges := expected.Edges()
+	for edges.Next() {
+		from := expectedNodeMap[edges.Edge().From().ID()]
+		to := expectedNodeMap[edges.Edge().To().ID()]
+		edge := graph.Edge(stringIDToNodeInfo[from.stringID].id, stringIDToNodeInfo[to.stringID].id)
+		if edge == nil || EdgeKind(edge) != EdgeKind(edges.Edge()) {
+			t.Errorf("Expected a %s edge from %s to %s", EdgeKind(edges.Edge()), from.stringID, to.stringID)
+		}
+	}
+
+	t.Run("Stops at the first error of the reader", func(t *testing.T) {
+		failure := errors.New("truncated input")
+		_, _, _, _, err := CreateGraphStreaming(func(handle func(PackageInfo) error) error { return failure }, NPM, nil)
+		if !errors.Is(err, failure) {
+			t.Errorf("Expected the error of the reader, got %v", err)
+		}
+	})
+}
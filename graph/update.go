@@ -0,0 +1,163 @@
+package graph
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Updater adds versions to a graph that was already created, without creating it again. It keeps the maps that
+// CreateGraph returns and the packages list up to date, so the result can be queried and saved as a snapshot like a
+// graph that was created from scratch.
+type Updater struct {
+	graph              *simple.DirectedGraph
+	packagesList       *[]PackageInfo
+	stringIDToNodeInfo map[string]NodeInfo
+	idToNodeInfo       map[int64]NodeInfo
+	nameToVersions     map[string][]string
+	ecosystem          Ecosystem
+	isMaven            bool
+	// packageIndex maps package names to their index in the packages list
+	packageIndex map[string]int
+	// dependents maps package names to the versions that have a constraint on them
+	dependents map[string][]NodeInfo
+	// Versions and Edges count what the updater added, Updated the versions in the graph whose status it changed
+	Versions int
+	Edges    int
+	Updated  int
+}
+
+// NewUpdater prepares the incremental updates of a version-level graph and the values CreateGraph returned with it
+func NewUpdater(g *simple.DirectedGraph, packagesList *[]PackageInfo, stringMap map[string]NodeInfo, nodeMap map[int64]NodeInfo, nameToVersions map[string][]string, ecosystem Ecosystem) *Updater {
+	u := &Updater{
+		graph:              g,
+		packagesList:       packagesList,
+		stringIDToNodeInfo: stringMap,
+		idToNodeInfo:       nodeMap,
+		nameToVersions:     nameToVersions,
+		ecosystem:          ecosystem,
+		isMaven:            ecosystem == Maven,
+		packageIndex:       make(map[string]int, len(*packagesList)),
+		dependents:         make(map[string][]NodeInfo),
+	}
+	for i, packageInfo := range *packagesList {
+		u.packageIndex[packageInfo.Name] = i
+		for version, versionInfo := range packageInfo.Versions {
+			node := stringMap[StringID(packageInfo.Name, version)]
+			for dependencyName := range versionInfo.Dependencies {
+				u.dependents[dependencyName] = append(u.dependents[dependencyName], node)
+			}
+		}
+	}
+	return u
+}
+
+// AddPackage adds the versions of the package that are not in the graph yet and returns them. Of the versions that
+// are already in the graph only the status is updated, a registry deprecates or unpublishes versions after they were
+// published.
+func (u *Updater) AddPackage(packageInfo PackageInfo) []NodeInfo {
+	var added []NodeInfo
+	for version, versionInfo := range packageInfo.Versions {
+		if node, ok := u.stringIDToNodeInfo[StringID(packageInfo.Name, version)]; ok {
+			u.setStatus(node, versionInfo.VersionStatus)
+			continue
+		}
+		node, _ := u.AddVersion(packageInfo.Name, version, versionInfo)
+		added = append(added, node)
+	}
+	sortNodesByStringID(added)
+	return added
+}
+
+// Unpublish marks the versions of a deleted package as unpublished at the given moment. Versions that were already
+// unpublished keep their moment.
+func (u *Updater) Unpublish(name, at string) {
+	for _, version := range u.nameToVersions[name] {
+		node := u.stringIDToNodeInfo[StringID(name, version)]
+		if node.Unpublished == "" {
+			status := node.VersionStatus
+			status.Unpublished = at
+			u.setStatus(node, status)
+		}
+	}
+}
+
+// setStatus changes the status of a version in the graph, in its maps and in the packages list
+func (u *Updater) setStatus(node NodeInfo, status VersionStatus) {
+	if node.VersionStatus == status {
+		return
+	}
+	node.VersionStatus = status
+	u.stringIDToNodeInfo[node.stringID] = node
+	u.idToNodeInfo[node.id] = node
+	if i, ok := u.packageIndex[node.Name]; ok {
+		if versionInfo, ok := (*u.packagesList)[i].Versions[node.Version]; ok {
+			versionInfo.VersionStatus = status
+			(*u.packagesList)[i].Versions[node.Version] = versionInfo
+		}
+	}
+	u.Updated++
+}
+
+// AddVersion adds a version to the graph with the edges CreateEdges would have created for it: to the versions its
+// constraints match, and from the versions whose constraints match it.
+func (u *Updater) AddVersion(name, version string, versionInfo VersionInfo) (NodeInfo, error) {
+	stringID := StringID(name, version)
+	if _, ok := u.stringIDToNodeInfo[stringID]; ok {
+		return NodeInfo{}, fmt.Errorf("%s is already in the graph", stringID)
+	}
+	newNode := u.graph.NewNode()
+	u.graph.AddNode(newNode)
+	node := *NewNodeInfo(newNode.ID(), u.ecosystem, name, version, versionInfo.Timestamp)
+	node.Author = versionInfo.Author
+	node.VersionStatus = versionInfo.VersionStatus
+	u.stringIDToNodeInfo[stringID] = node
+	u.idToNodeInfo[node.id] = node
+	u.nameToVersions[name] = append(u.nameToVersions[name], version)
+	if i, ok := u.packageIndex[name]; ok {
+		if (*u.packagesList)[i].Versions == nil {
+			(*u.packagesList)[i].Versions = make(map[string]VersionInfo)
+		}
+		(*u.packagesList)[i].Versions[version] = versionInfo
+	} else {
+		u.packageIndex[name] = len(*u.packagesList)
+		*u.packagesList = append(*u.packagesList, PackageInfo{Name: name, Versions: map[string]VersionInfo{version: versionInfo}})
+	}
+	u.Versions++
+
+	for dependencyName, requirement := range versionInfo.Dependencies {
+		u.dependents[dependencyName] = append(u.dependents[dependencyName], node)
+		constraint, err := parseConstraint(requirement, u.isMaven)
+		if err != nil {
+			continue
+		}
+		for _, v := range u.nameToVersions[dependencyName] {
+			if candidate, err := semver.NewVersion(v); err == nil && constraint.Check(candidate) {
+				u.setEdge(node, u.stringIDToNodeInfo[StringID(dependencyName, v)], versionInfo.DependencyKind(dependencyName))
+			}
+		}
+	}
+
+	newVersion, err := semver.NewVersion(version)
+	if err != nil {
+		return node, nil
+	}
+	for _, dependent := range u.dependents[name] {
+		dependentInfo := (*u.packagesList)[u.packageIndex[dependent.Name]].Versions[dependent.Version]
+		constraint, err := parseConstraint(dependentInfo.Dependencies[name], u.isMaven)
+		if err == nil && constraint.Check(newVersion) {
+			u.setEdge(dependent, node, dependentInfo.DependencyKind(name))
+		}
+	}
+	return node, nil
+}
+
+// setEdge adds the dependency unless it is a dependency on itself or already in the graph
+func (u *Updater) setEdge(from, to NodeInfo, kind string) {
+	if from.id == to.id || u.graph.HasEdgeFromTo(from.id, to.id) {
+		return
+	}
+	u.graph.SetEdge(DependencyEdge{F: u.graph.Node(from.id), T: u.graph.Node(to.id), Kind: kind})
+	u.Edges++
+}
@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdater(t *testing.T) {
+	all := []PackageInfo{
+		{Name: "A", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2020-01-01T00:00:00"},
+			"1.1.0": {Timestamp: "2020-06-01T00:00:00"},
+		}},
+		{Name: "B", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-01-01T00:00:00", Dependencies: map[string]string{"A": "^1.0.0"}},
+			"2.0.0": {Timestamp: "2021-06-01T00:00:00", Dependencies: map[string]string{"A": "^1.1.0", "C": "*"}, DependencyKinds: map[string]string{"C": KindDev}},
+		}},
+		{Name: "C", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-03-01T00:00:00", Dependencies: map[string]string{"B": "^1.0.0"}},
+		}},
+	}
+	expected, _, _, expectedNodeMap, _ := CreateGraphFromPackages(&all, NPM)
+
+	initial := []PackageInfo{
+		{Name: "A", Versions: map[string]VersionInfo{"1.0.0": all[0].Versions["1.0.0"]}},
+		{Name: "B", Versions: map[string]VersionInfo{"1.0.0": all[1].Versions["1.0.0"]}},
+	}
+	graph, packagesList, stringIDToNodeInfo, idToNodeInfo, nameToVersions := CreateGraphFromPackages(&initial, NPM)
+	updater := NewUpdater(graph, packagesList, stringIDToNodeInfo, idToNodeInfo, nameToVersions, NPM)
+
+	if added := updater.AddPackage(all[2]); len(added) != 1 || added[0].Name != "C" {
+		t.Errorf("Expected C@1.0.0 to be added, got %v", added)
+	}
+	if added := updater.AddPackage(all[1]); len(added) != 1 || added[0].Version != "2.0.0" {
+		t.Errorf("Expected only B@2.0.0 to be added, got %v", added)
+	}
+	if _, err := updater.AddVersion("A", "1.1.0", all[0].Versions["1.1.0"]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := updater.AddVersion("A", "1.1.0", all[0].Versions["1.1.0"]); err == nil {
+		t.Error("Expected an error when a version is added twice")
+	}
+
+	if updater.Versions != 3 || len(stringIDToNodeInfo) != 5 || len(idToNodeInfo) != 5 || len(nameToVersions["A"]) != 2 || len(*packagesList) != 3 {
+		t.Errorf("Expected 3 versions to be added to the graph and its maps, got %d", updater.Versions)
+	}
+	if updater.Edges != graph.Edges().Len()-1 || graph.Edges().Len() != expected.Edges().Len() {
+		t.Fatalf("Expected %d edges like a graph created from scratch, got %d", expected.Edges().Len(), graph.Edges().Len())
+	}
+	// The node ids differ from the ones of the graph created from scratch, so the edges are compared by string id
+	edges := expected.Edges()
+	for edges.Next() {
+		from := expectedNodeMap[edges.Edge().From().ID()]
+		to := expectedNodeMap[edges.Edge().To().ID()]
+		edge := graph.Edge(stringIDToNodeInfo[from.stringID].id, stringIDToNodeInfo[to.stringID].id)
+		if edge == nil {
+			t.Errorf("Expected an edge from %s to %s", from.stringID, to.stringID)
+		} else if EdgeKind(edge) != EdgeKind(edges.Edge()) {
+			t.Errorf("Expected the edge from %s to %s to be a %s dependency", from.stringID, to.stringID, EdgeKind(edges.Edge()))
+		}
+	}
+
+	t.Run("Updates the status of versions in the graph", func(t *testing.T) {
+		deprecated := PackageInfo{Name: "A", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2020-01-01T00:00:00", VersionStatus: VersionStatus{Deprecated: "use 1.1.0"}},
+			"1.1.0": all[0].Versions["1.1.0"],
+		}}
+		if added := updater.AddPackage(deprecated); len(added) != 0 || updater.Updated != 1 {
+			t.Fatalf("Expected only the status of A@1.0.0 to change, got %v and %d updates", added, updater.Updated)
+		}
+		node := stringIDToNodeInfo["A@1.0.0"]
+		if node.Deprecated != "use 1.1.0" || idToNodeInfo[node.id].Deprecated != "use 1.1.0" || (*packagesList)[0].Versions["1.0.0"].Deprecated != "use 1.1.0" {
+			t.Errorf("Expected A@1.0.0 to be deprecated in the maps and the packages list, got %+v", node)
+		}
+
+		updater.Unpublish("A", "2022-01-01T00:00:00Z")
+		for _, version := range []string{"1.0.0", "1.1.0"} {
+			if node := stringIDToNodeInfo[StringID("A", version)]; node.Unpublished != "2022-01-01T00:00:00Z" || node.AvailableAt(time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("Expected A@%s to be unpublished in 2022, got %+v", version, node)
+			}
+		}
+		if node := stringIDToNodeInfo["A@1.0.0"]; node.Deprecated != "use 1.1.0" || updater.Updated != 3 {
+			t.Errorf("Expected A@1.0.0 to stay deprecated and 3 updates, got %+v and %d", node, updater.Updated)
+		}
+	})
+}
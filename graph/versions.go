@@ -0,0 +1,172 @@
+package graph
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Masterminds/semver"
+)
+
+// qualifierRanks orders the qualifiers of versions in the ecosystems that don't use semantic versioning. Qualifiers
+// with a negative rank are pre-releases and come before the release, which has rank 0. Qualifiers that are not listed
+// come after the listed ones.
+var qualifierRanks = map[Ecosystem]map[string]int{
+	Maven: rankQualifiers(
+		[]string{"alpha", "a"},
+		[]string{"beta", "b"},
+		[]string{"milestone", "m"},
+		[]string{"rc", "cr"},
+		[]string{"snapshot"},
+		[]string{"", "ga", "final", "release"},
+		[]string{"sp"},
+	),
+	PyPI: rankQualifiers(
+		[]string{"dev"},
+		[]string{"a", "alpha"},
+		[]string{"b", "beta"},
+		[]string{"rc", "c", "pre", "preview"},
+		[]string{""},
+		[]string{"post", "rev", "r"},
+	),
+}
+
+// rankQualifiers ranks groups of qualifiers in the given order, the group with the empty qualifier gets rank 0
+func rankQualifiers(groups ...[]string) map[string]int {
+	releaseGroup := 0
+	for i, group := range groups {
+		if group[0] == "" {
+			releaseGroup = i
+		}
+	}
+	ranks := make(map[string]int)
+	for i, group := range groups {
+		for _, qualifier := range group {
+			ranks[qualifier] = i - releaseGroup
+		}
+	}
+	return ranks
+}
+
+// CompareVersions compares two versions with the version semantics of the ecosystem. It returns -1 when a comes
+// before b, 1 when it comes after b and 0 when they are the same version. npm, Go module and Cargo versions are
+// compared as semantic versions, which orders the pseudo-versions of Go before the release they are based on. Maven
+// and PyPI versions are split into numbers and qualifiers which are compared one by one, this follows the ordering of
+// Maven's ComparableVersion and of PEP 440 for the versions that are used in practice.
+func CompareVersions(ecosystem Ecosystem, a, b string) int {
+	if ecosystem == NPM || ecosystem == Go || ecosystem == Cargo {
+		va, errA := semver.NewVersion(a)
+		vb, errB := semver.NewVersion(b)
+		if errA == nil && errB == nil {
+			return va.Compare(vb)
+		}
+	}
+	ranks := qualifierRanks[ecosystem]
+	ta, tb := versionTokens(a), versionTokens(b)
+	for i := 0; i < len(ta) || i < len(tb); i++ {
+		if c := compareTokens(tokenAt(ta, i), tokenAt(tb, i), ranks); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// versionToken is a number or a qualifier of a version
+type versionToken struct {
+	isNumber  bool
+	number    int64
+	qualifier string
+}
+
+// release is the token missing tokens are compared as, so 1.0 equals 1.0.0 and 1.0 comes after 1.0-rc1
+var release = versionToken{}
+
+func tokenAt(tokens []versionToken, i int) versionToken {
+	if i < len(tokens) {
+		return tokens[i]
+	}
+	return release
+}
+
+// versionTokens splits a version on separators and between digits and letters, so 1.0.0-RC2 becomes 1 0 0 rc 2
+func versionTokens(version string) []versionToken {
+	var tokens []versionToken
+	version = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "v")
+	start := 0
+	flush := func(end int) {
+		if start >= end {
+			return
+		}
+		part := version[start:end]
+		if n, err := strconv.ParseInt(part, 10, 64); err == nil {
+			tokens = append(tokens, versionToken{isNumber: true, number: n})
+		} else {
+			tokens = append(tokens, versionToken{qualifier: part})
+		}
+	}
+	for i, r := range version {
+		switch {
+		case r == '.' || r == '-' || r == '_' || r == '+' || r == '!':
+			flush(i)
+			start = i + 1
+		case i > start && unicode.IsDigit(r) != unicode.IsDigit(rune(version[i-1])):
+			flush(i)
+			start = i
+		}
+	}
+	flush(len(version))
+	return tokens
+}
+
+func compareTokens(a, b versionToken, ranks map[string]int) int {
+	switch {
+	case a.isNumber && b.isNumber:
+		return compareInts(a.number, b.number)
+	case a.isNumber:
+		// A number is compared with the end of the other version or with a qualifier. A trailing zero is the same as
+		// the end, any other number comes after it and after every qualifier.
+		if b == release && a.number == 0 {
+			return 0
+		}
+		return 1
+	case b.isNumber:
+		return -compareTokens(b, a, ranks)
+	}
+	rankA, knownA := ranks[a.qualifier]
+	rankB, knownB := ranks[b.qualifier]
+	switch {
+	case knownA && knownB:
+		return compareInts(int64(rankA), int64(rankB))
+	case knownA:
+		return -1
+	case knownB:
+		return 1
+	}
+	return strings.Compare(a.qualifier, b.qualifier)
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// IsPrerelease reports whether the version is a pre-release, which package managers don't pick unless asked for it
+func IsPrerelease(ecosystem Ecosystem, version string) bool {
+	if ecosystem == NPM || ecosystem == Go || ecosystem == Cargo {
+		if v, err := semver.NewVersion(version); err == nil {
+			return v.Prerelease() != ""
+		}
+	}
+	ranks := qualifierRanks[ecosystem]
+	for _, token := range versionTokens(version) {
+		if rank, ok := ranks[token.qualifier]; !token.isNumber && ok && rank < 0 {
+			return true
+		}
+	}
+	return false
+}
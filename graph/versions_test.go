@@ -0,0 +1,44 @@
+package graph
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		ecosystem Ecosystem
+		a, b      string
+		want      int
+	}{
+		{NPM, "1.2.3", "1.10.0", -1},
+		{NPM, "2.0.0-beta.1", "2.0.0", -1},
+		{NPM, "1.0.0", "1.0.0", 0},
+		{Maven, "1.0", "1.0.0", 0},
+		{Maven, "1.0-alpha-1", "1.0-beta", -1},
+		{Maven, "1.0-RC2", "1.0", -1},
+		{Maven, "1.0-SNAPSHOT", "1.0-rc1", 1},
+		{Maven, "1.0-sp1", "1.0", 1},
+		{Maven, "2.9.10.1", "2.9.10", 1},
+		{Maven, "1.0.1", "1.0-sp1", 1},
+		{PyPI, "1.0.dev1", "1.0a1", -1},
+		{PyPI, "1.0rc1", "1.0", -1},
+		{PyPI, "1.0.post1", "1.0", 1},
+		{PyPI, "1.10", "1.9", 1},
+	}
+	for _, test := range tests {
+		if got := CompareVersions(test.ecosystem, test.a, test.b); got != test.want {
+			t.Errorf("CompareVersions(%s, %q, %q) = %d, expected %d", test.ecosystem, test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestIsPrerelease(t *testing.T) {
+	for version, want := range map[string]bool{"1.0.0": false, "1.0.0-beta.1": true} {
+		if got := IsPrerelease(NPM, version); got != want {
+			t.Errorf("Expected IsPrerelease(npm, %q) to be %v", version, want)
+		}
+	}
+	for version, want := range map[string]bool{"1.0": false, "1.0-SNAPSHOT": true, "1.0-RC1": true, "1.0.Final": false} {
+		if got := IsPrerelease(Maven, version); got != want {
+			t.Errorf("Expected IsPrerelease(maven, %q) to be %v", version, want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package graph
+
+import (
+	"sort"
+	"time"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/simple"
+	"gonum.org/v1/gonum/graph/traverse"
+)
+
+// Advisory is a security advisory in the OSV format, reduced to the fields needed to find the affected versions
+type Advisory struct {
+	ID        string
+	Summary   string
+	Aliases   []string
+	Published time.Time
+	Affected  []AffectedPackage
+}
+
+// AffectedPackage lists the versions of one package an advisory applies to. A version is affected when it is listed
+// in Versions or lies in one of the ranges.
+type AffectedPackage struct {
+	Name     string
+	Ranges   []VersionRange
+	Versions []string
+}
+
+// VersionRange is a range of affected versions. It starts at Introduced, where "0" means the first version, and ends
+// before Fixed or at LastAffected. A range with neither has no end.
+type VersionRange struct {
+	Introduced   string
+	Fixed        string
+	LastAffected string
+}
+
+// Contains reports whether the version lies in the range, using the version semantics of the ecosystem
+func (r VersionRange) Contains(ecosystem Ecosystem, version string) bool {
+	if r.Introduced != "" && r.Introduced != "0" && CompareVersions(ecosystem, version, r.Introduced) < 0 {
+		return false
+	}
+	if r.Fixed != "" && CompareVersions(ecosystem, version, r.Fixed) >= 0 {
+		return false
+	}
+	if r.LastAffected != "" && CompareVersions(ecosystem, version, r.LastAffected) > 0 {
+		return false
+	}
+	return true
+}
+
+// Affects reports whether the given version of the package is affected
+func (affected AffectedPackage) Affects(ecosystem Ecosystem, version string) bool {
+	for _, v := range affected.Versions {
+		if v == version {
+			return true
+		}
+	}
+	for _, r := range affected.Ranges {
+		if r.Contains(ecosystem, version) {
+			return true
+		}
+	}
+	return false
+}
+
+// AffectedNodes returns the nodes of the graph the advisory applies to, sorted by their id
+func (advisory Advisory) AffectedNodes(stringMap map[string]NodeInfo, nameToVersions map[string][]string, ecosystem Ecosystem) []NodeInfo {
+	result := make([]NodeInfo, 0)
+	seen := make(map[int64]bool)
+	for _, affected := range advisory.Affected {
+		for _, version := range nameToVersions[affected.Name] {
+			node, ok := stringMap[StringID(affected.Name, version)]
+			if !ok || seen[node.id] || !affected.Affects(ecosystem, version) {
+				continue
+			}
+			seen[node.id] = true
+			result = append(result, node)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].id < result[j].id
+	})
+	return result
+}
+
+// Exposure is a version that (transitively) depends on a version an advisory applies to
+type Exposure struct {
+	Advisory string
+	// Node is the exposed version and Vulnerable the affected version it depends on
+	Node       NodeInfo
+	Vulnerable NodeInfo
+	// Path is the shortest chain of dependencies from Node to Vulnerable, both included, and Depth its number of edges.
+	// Affected versions are exposed through themselves with a depth of zero.
+	Path  []NodeInfo
+	Depth int
+	// Begin is the moment every version on the path had been published, End the moment the first version of the
+	// vulnerable package that fixes the advisory was published. End is zero when there is no fix in the graph and lies
+	// before Begin when the fix was already available, the exposure then only exists for dependents that pin the version.
+	Begin time.Time
+	End   time.Time
+}
+
+// GetExposures returns every version that is exposed to one of the advisories, sorted by advisory and the string id of
+// the exposed version. Every exposed version is reported once per advisory, through its closest affected version.
+func GetExposures(g *simple.DirectedGraph, nodeMap map[int64]NodeInfo, stringMap map[string]NodeInfo, nameToVersions map[string][]string, ecosystem Ecosystem, advisories []Advisory) *[]Exposure {
+	result := make([]Exposure, 0)
+	for _, advisory := range advisories {
+		affected := advisory.AffectedNodes(stringMap, nameToVersions, ecosystem)
+		if len(affected) == 0 {
+			continue
+		}
+		sources := make([]graph.Node, len(affected))
+		for i, node := range affected {
+			sources[i] = g.Node(node.id)
+		}
+		fixedAt := fixTimes(affected, stringMap, nameToVersions, ecosystem)
+
+		parents := make(map[int64]int64)
+		var exposed []int64
+		w := traverse.BreadthFirst{
+			Traverse: func(e graph.Edge) bool {
+				if _, ok := parents[e.To().ID()]; !ok && e.To().ID() != virtualSource {
+					parents[e.To().ID()] = e.From().ID()
+				}
+				return true
+			},
+			Visit: func(n graph.Node) {
+				if n.ID() != virtualSource {
+					exposed = append(exposed, n.ID())
+				}
+			},
+		}
+		_ = w.Walk(sourcedGraph{reversedGraph{g}, sources}, simple.Node(virtualSource), nil)
+
+		for _, id := range exposed {
+			exposure := Exposure{Advisory: advisory.ID, Node: nodeMap[id]}
+			// Following the parents walks from the exposed version towards the affected one, which is the order of
+			// the dependency chain
+			for current := id; current != virtualSource; current = parents[current] {
+				node := nodeMap[current]
+				exposure.Path = append(exposure.Path, node)
+				if t, err := ParseTimestamp(node.Timestamp); err == nil && t.After(exposure.Begin) {
+					exposure.Begin = t
+				}
+			}
+			exposure.Vulnerable = exposure.Path[len(exposure.Path)-1]
+			exposure.Depth = len(exposure.Path) - 1
+			exposure.End = fixedAt[exposure.Vulnerable.id]
+			result = append(result, exposure)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Advisory != result[j].Advisory {
+			return result[i].Advisory < result[j].Advisory
+		}
+		return StringID(result[i].Node.Name, result[i].Node.Version) < StringID(result[j].Node.Name, result[j].Node.Version)
+	})
+	return &result
+}
+
+// fixTimes returns for every affected version when the first later version of its package that is not affected was
+// published. Yanked versions don't count as a fix, versions without such a later version are left out.
+func fixTimes(affected []NodeInfo, stringMap map[string]NodeInfo, nameToVersions map[string][]string, ecosystem Ecosystem) map[int64]time.Time {
+	isAffected := make(map[int64]bool, len(affected))
+	for _, node := range affected {
+		isAffected[node.id] = true
+	}
+	result := make(map[int64]time.Time)
+	for _, node := range affected {
+		for _, version := range nameToVersions[node.Name] {
+			fix, ok := stringMap[StringID(node.Name, version)]
+			if !ok || isAffected[fix.id] || fix.Yanked || CompareVersions(ecosystem, version, node.Version) <= 0 {
+				continue
+			}
+			t, err := ParseTimestamp(fix.Timestamp)
+			if err != nil {
+				continue
+			}
+			if current, ok := result[node.id]; !ok || t.Before(current) {
+				result[node.id] = t
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVersionRangeContains(t *testing.T) {
+	fixed := VersionRange{Introduced: "1.2.0", Fixed: "1.4.0"}
+	lastAffected := VersionRange{Introduced: "0", LastAffected: "1.3.0"}
+	for version, want := range map[string]bool{"1.1.0": false, "1.2.0": true, "1.3.5": true, "1.4.0": false} {
+		if got := fixed.Contains(NPM, version); got != want {
+			t.Errorf("Expected %v for %s in %+v, got %v", want, version, fixed, got)
+		}
+	}
+	for version, want := range map[string]bool{"0.1.0": true, "1.3.0": true, "1.3.1": false} {
+		if got := lastAffected.Contains(NPM, version); got != want {
+			t.Errorf("Expected %v for %s in %+v, got %v", want, version, lastAffected, got)
+		}
+	}
+}
+
+func TestGetExposures(t *testing.T) {
+	graph, stringIDToNodeInfo, idToNodeInfo, nameToVersions := createQueryTestGraph()
+	advisory := Advisory{
+		ID:       "GHSA-test",
+		Affected: []AffectedPackage{{Name: "B", Ranges: []VersionRange{{Introduced: "0", Fixed: "2.0.0"}}}},
+	}
+	exposures := *GetExposures(graph, idToNodeInfo, stringIDToNodeInfo, nameToVersions, NPM, []Advisory{advisory})
+	if len(exposures) != 2 {
+		t.Fatalf("Expected B@1.0.0 and C@1.0.0 to be exposed, got %v", exposures)
+	}
+
+	t.Run("Reports the affected version itself", func(t *testing.T) {
+		b := exposures[0]
+		if b.Node.Version != "1.0.0" || b.Depth != 0 || len(b.Path) != 1 {
+			t.Errorf("Expected B@1.0.0 to be exposed at depth 0, got %+v", b)
+		}
+	})
+
+	t.Run("Reports the path and the time window of transitive exposures", func(t *testing.T) {
+		c := exposures[1]
+		if c.Node.Name != "C" || c.Vulnerable.Name != "B" || c.Depth != 1 || len(c.Path) != 2 {
+			t.Errorf("Expected C@1.0.0 to be exposed through B@1.0.0, got %+v", c)
+		}
+		if !c.Begin.Equal(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected the exposure to begin when C@1.0.0 was published, got %v", c.Begin)
+		}
+		if !c.End.Equal(time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected the exposure to end when B@2.0.0 was published, got %v", c.End)
+		}
+	})
+}
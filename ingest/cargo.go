@@ -0,0 +1,294 @@
+package ingest
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+// cargoDependency is a dependency in a line of the crates.io index. Name is the name the dependency has in the
+// Cargo.toml of the crate, Package the name of the crate when the dependency was renamed.
+type cargoDependency struct {
+	Name            string   `json:"name"`
+	Req             string   `json:"req"`
+	Features        []string `json:"features"`
+	Optional        bool     `json:"optional"`
+	DefaultFeatures bool     `json:"default_features"`
+	Target          string   `json:"target"`
+	Kind            string   `json:"kind"`
+	Package         string   `json:"package"`
+}
+
+// crate is the name of the crate the dependency is on
+func (d cargoDependency) crate() string {
+	if d.Package != "" {
+		return d.Package
+	}
+	return d.Name
+}
+
+// cargoVersion is a line of the crates.io index, a version of a crate. Features2 holds the features that use the
+// dep: and ?/ syntax, which older Cargo versions can't read.
+type cargoVersion struct {
+	Name      string              `json:"name"`
+	Vers      string              `json:"vers"`
+	Deps      []cargoDependency   `json:"deps"`
+	Features  map[string][]string `json:"features"`
+	Features2 map[string][]string `json:"features2"`
+	Yanked    bool                `json:"yanked"`
+	PubTime   string              `json:"pubtime"`
+}
+
+// defaultDependencies returns the names of the optional dependencies the default feature enables, directly or
+// through other features. A dependency is enabled by its name, by dep:name and by name/feature, but not by the weak
+// name?/feature.
+func (v cargoVersion) defaultDependencies() map[string]bool {
+	features := make(map[string][]string, len(v.Features)+len(v.Features2))
+	for name, enables := range v.Features {
+		features[name] = enables
+	}
+	for name, enables := range v.Features2 {
+		features[name] = append(features[name], enables...)
+	}
+	enabled := make(map[string]bool)
+	visited := make(map[string]bool)
+	queue := []string{"default"}
+	for len(queue) > 0 {
+		feature := queue[0]
+		queue = queue[1:]
+		if visited[feature] {
+			continue
+		}
+		visited[feature] = true
+		for _, enable := range features[feature] {
+			switch {
+			case strings.HasPrefix(enable, "dep:"):
+				enabled[enable[4:]] = true
+			case strings.Contains(enable, "?/"):
+				continue
+			case strings.Contains(enable, "/"):
+				enabled[enable[:strings.Index(enable, "/")]] = true
+			default:
+				// Without dep: an optional dependency is also a feature with its name
+				enabled[enable] = true
+				queue = append(queue, enable)
+			}
+		}
+	}
+	return enabled
+}
+
+// cargoKindRanks orders the kinds a crate can depend on another crate with. When a crate lists a dependency more than
+// once, for example for different targets, the dependency with the highest rank is kept.
+var cargoKindRanks = map[string]int{g.KindDev: 0, g.KindBuild: 1, g.KindOptional: 2, g.KindRuntime: 3}
+
+// versionInfo converts a line of the index. Dev and build dependencies keep their kind. Optional dependencies are
+// optional unless the default features enable them, dependencies for a specific target are optional as well because
+// they are only compiled on some platforms, like the platform-specific optional dependencies of npm.
+func (v cargoVersion) versionInfo(timestamp string) g.VersionInfo {
+	if v.PubTime != "" {
+		timestamp = v.PubTime
+	}
+	result := g.VersionInfo{Timestamp: timestamp, Dependencies: make(map[string]string, len(v.Deps))}
+	result.Yanked = v.Yanked
+	kinds := make(map[string]string, len(v.Deps))
+	defaults := v.defaultDependencies()
+	for _, dependency := range v.Deps {
+		kind := g.KindRuntime
+		switch {
+		case dependency.Kind == "dev":
+			kind = g.KindDev
+		case dependency.Kind == "build":
+			kind = g.KindBuild
+		case dependency.Optional && !defaults[dependency.Name], dependency.Target != "":
+			kind = g.KindOptional
+		}
+		crate := dependency.crate()
+		if current, ok := kinds[crate]; ok && cargoKindRanks[current] >= cargoKindRanks[kind] {
+			continue
+		}
+		kinds[crate] = kind
+		result.Dependencies[crate] = cargoConstraint(dependency.Req)
+	}
+	for crate, kind := range kinds {
+		if kind == g.KindRuntime {
+			continue
+		}
+		if result.DependencyKinds == nil {
+			result.DependencyKinds = make(map[string]string)
+		}
+		result.DependencyKinds[crate] = kind
+	}
+	return result
+}
+
+// cargoConstraint translates a Cargo version requirement to a constraint of the semver library. A bare version is a
+// caret requirement in Cargo, and caret and tilde requirements are written out as ranges because Cargo treats 0.x
+// versions as incompatible with each other, which the semver library doesn't.
+func cargoConstraint(requirement string) string {
+	var parts []string
+	for _, clause := range strings.Split(requirement, ",") {
+		clause = strings.TrimSpace(clause)
+		switch {
+		case clause == "":
+			continue
+		case strings.HasPrefix(clause, "^"):
+			parts = append(parts, caretRange(strings.TrimSpace(clause[1:])))
+		case strings.HasPrefix(clause, "~"):
+			parts = append(parts, tildeRange(strings.TrimSpace(clause[1:])))
+		case clause[0] >= '0' && clause[0] <= '9' && !strings.ContainsAny(clause, "*xX"):
+			parts = append(parts, caretRange(clause))
+		default:
+			parts = append(parts, clause)
+		}
+	}
+	if len(parts) == 0 {
+		return "*"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// versionNumbers returns the major, minor and patch numbers that are written in a version, without its pre-release
+// and build metadata
+func versionNumbers(version string) ([]int, bool) {
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	fields := strings.Split(version, ".")
+	if len(fields) > 3 {
+		return nil, false
+	}
+	numbers := make([]int, len(fields))
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, false
+		}
+		numbers[i] = n
+	}
+	return numbers, true
+}
+
+// caretRange allows the versions that don't change the leftmost number that is not zero: ^1.2 is >=1.2, <2.0.0,
+// ^0.2.3 is >=0.2.3, <0.3.0 and ^0.0.3 is >=0.0.3, <0.0.4
+func caretRange(version string) string {
+	numbers, ok := versionNumbers(version)
+	if !ok {
+		return "^" + version
+	}
+	var upper string
+	switch {
+	case numbers[0] > 0 || len(numbers) == 1:
+		upper = fmt.Sprintf("%d.0.0", numbers[0]+1)
+	case numbers[1] > 0 || len(numbers) == 2:
+		upper = fmt.Sprintf("0.%d.0", numbers[1]+1)
+	default:
+		upper = fmt.Sprintf("0.0.%d", numbers[2]+1)
+	}
+	return ">=" + version + ", <" + upper
+}
+
+// tildeRange allows patch updates, or minor updates when only the major version is given: ~1.2.3 is >=1.2.3, <1.3.0
+// and ~1 is >=1, <2.0.0
+func tildeRange(version string) string {
+	numbers, ok := versionNumbers(version)
+	if !ok {
+		return "~" + version
+	}
+	if len(numbers) == 1 {
+		return fmt.Sprintf(">=%s, <%d.0.0", version, numbers[0]+1)
+	}
+	return fmt.Sprintf(">=%s, <%d.%d.0", version, numbers[0], numbers[1]+1)
+}
+
+// LoadCargoIndex reads a local checkout of the crates.io index, or of another registry in the same format. Every file
+// holds a crate with a JSON line for each of its versions. The publish time is read from the pubtime field, lines
+// written before the index had it use the modification time of the file. Lines that can't be read are left out, the
+// second result maps their string ids, or the file and line number when even the name can't be read, to the reason.
+func LoadCargoIndex(root string) (*[]g.PackageInfo, map[string]string, error) {
+	packages := make(map[string]*g.PackageInfo)
+	skipped := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Dir(path) == root || strings.HasPrefix(entry.Name(), ".") {
+			// config.json and the README are not crates
+			return nil
+		}
+		var timestamp string
+		if info, err := entry.Info(); err == nil {
+			timestamp = info.ModTime().UTC().Format(time.RFC3339)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for n := 1; scanner.Scan(); n++ {
+			if len(strings.TrimSpace(scanner.Text())) == 0 {
+				continue
+			}
+			var version cargoVersion
+			if err := json.Unmarshal(scanner.Bytes(), &version); err != nil || version.Name == "" || version.Vers == "" {
+				if err == nil {
+					err = errors.New("no name or version")
+				}
+				key := fmt.Sprintf("%s:%d", path, n)
+				if version.Name != "" && version.Vers != "" {
+					key = g.StringID(version.Name, version.Vers)
+				}
+				skipped[key] = err.Error()
+				continue
+			}
+			if packages[version.Name] == nil {
+				packages[version.Name] = &g.PackageInfo{Name: version.Name, Versions: make(map[string]g.VersionInfo)}
+			}
+			packages[version.Name].Versions[version.Vers] = version.versionInfo(timestamp)
+		}
+		return scanner.Err()
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := make([]g.PackageInfo, 0, len(packages))
+	for _, packageInfo := range packages {
+		result = append(result, *packageInfo)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return &result, skipped, nil
+}
+
+// isCargoIndex tells whether a directory is a registry index, which has a config.json with the download URL at its
+// root
+func isCargoIndex(root string) bool {
+	data, err := os.ReadFile(filepath.Join(root, "config.json"))
+	if err != nil {
+		return false
+	}
+	var config struct {
+		DL string `json:"dl"`
+	}
+	return json.Unmarshal(data, &config) == nil && config.DL != ""
+}
@@ -0,0 +1,130 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+func TestCargoConstraint(t *testing.T) {
+	tests := map[string]string{
+		"1.2.3":           ">=1.2.3, <2.0.0",
+		"^0.2.3":          ">=0.2.3, <0.3.0",
+		"0.0.3":           ">=0.0.3, <0.0.4",
+		"^0.0":            ">=0.0, <0.1.0",
+		"0":               ">=0, <1.0.0",
+		"~1.2.3":          ">=1.2.3, <1.3.0",
+		"~1":              ">=1, <2.0.0",
+		">= 1.0, < 1.5":   ">= 1.0, < 1.5",
+		"=1.0.0-beta.1":   "=1.0.0-beta.1",
+		"1.*":             "1.*",
+		"*":               "*",
+		"":                "*",
+		"1.0.0-alpha, <2": ">=1.0.0-alpha, <2.0.0, <2",
+	}
+	for requirement, want := range tests {
+		if constraint := cargoConstraint(requirement); constraint != want {
+			t.Errorf("Expected %q to become %q, got %q", requirement, want, constraint)
+		}
+	}
+}
+
+func TestLoadCargoIndex(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"config.json": `{"dl": "https://crates.io/api/v1/crates", "api": "https://crates.io"}`,
+		"se/rd/serde": `{"name":"serde","vers":"1.0.0","deps":[],"features":{},"yanked":false,"pubtime":"2017-04-20T00:00:00Z"}
+{"name":"serde","vers":"1.0.1","deps":[],"features":{},"yanked":true,"pubtime":"2017-04-21T00:00:00Z"}
+`,
+		"3/l/log": `{"name":"log","vers":"0.4.0","deps":[],"features":{},"yanked":false}` + "\n",
+		"an/yh/anyhow": `{"name":"anyhow","vers":"1.0.0","deps":[` +
+			`{"name":"serde","req":"^1.0","features":[],"optional":true,"default_features":true,"target":null,"kind":"normal"},` +
+			`{"name":"logger","package":"log","req":"0.4","features":[],"optional":true,"default_features":true,"target":null,"kind":"normal"},` +
+			`{"name":"winapi","req":"0.3","features":[],"optional":false,"default_features":true,"target":"cfg(windows)","kind":"normal"},` +
+			`{"name":"rustversion","req":"1.0","features":[],"optional":false,"default_features":true,"target":null,"kind":"build"},` +
+			`{"name":"serde","req":"1.0.1","features":[],"optional":false,"default_features":true,"target":null,"kind":"dev"}` +
+			`],"features":{"default":["std"],"std":[]},"features2":{"logging":["dep:logger"]},"yanked":false}` + "\nnot json\n",
+		".git/HEAD": "ref: refs/heads/master\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	packages, skipped, err := LoadCargoIndex(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*packages) != 3 || (*packages)[0].Name != "anyhow" {
+		t.Fatalf("Expected anyhow, log and serde, got %+v", *packages)
+	}
+	if len(skipped) != 1 {
+		t.Errorf("Expected the line that is not JSON to be skipped, got %v", skipped)
+	}
+
+	anyhow := (*packages)[0].Versions["1.0.0"]
+	dependencies := map[string]string{
+		"serde":       ">=1.0, <2.0.0",
+		"log":         ">=0.4, <0.5.0",
+		"winapi":      ">=0.3, <0.4.0",
+		"rustversion": ">=1.0, <2.0.0",
+	}
+	kinds := map[string]string{
+		"serde":       g.KindOptional,
+		"log":         g.KindOptional,
+		"winapi":      g.KindOptional,
+		"rustversion": g.KindBuild,
+	}
+	if len(anyhow.Dependencies) != len(dependencies) {
+		t.Errorf("Expected %v, got %v", dependencies, anyhow.Dependencies)
+	}
+	for name, constraint := range dependencies {
+		if anyhow.Dependencies[name] != constraint || anyhow.DependencyKind(name) != kinds[name] {
+			t.Errorf("Expected %s %s (%s), got %s (%s)", name, constraint, kinds[name], anyhow.Dependencies[name], anyhow.DependencyKind(name))
+		}
+	}
+	if anyhow.Timestamp == "" {
+		t.Error("Expected the modification time when there is no pubtime")
+	}
+
+	t.Run("Marks yanked versions on the nodes", func(t *testing.T) {
+		if !isCargoIndex(root) {
+			t.Fatal("Expected the index to be recognized by its config.json")
+		}
+		packages, _, err := LoadPackages(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		graph, _, stringIDToNodeInfo, _, _ := g.CreateGraphFromPackages(packages, g.NPM)
+		if !stringIDToNodeInfo["serde@1.0.1"].Yanked || stringIDToNodeInfo["serde@1.0.0"].Yanked {
+			t.Errorf("Expected only serde@1.0.1 to be yanked")
+		}
+		anyhowNode, serdeNode := stringIDToNodeInfo["anyhow@1.0.0"], stringIDToNodeInfo["serde@1.0.1"]
+		if edge := graph.Edge(anyhowNode.ID(), serdeNode.ID()); edge == nil || g.EdgeKind(edge) != g.KindOptional {
+			t.Errorf("Expected an optional edge from anyhow to serde@1.0.1, got %v", edge)
+		}
+	})
+
+	t.Run("Enables optional dependencies through the default features", func(t *testing.T) {
+		version := cargoVersion{
+			Deps: []cargoDependency{
+				{Name: "a", Req: "1", Optional: true},
+				{Name: "b", Req: "1", Optional: true},
+				{Name: "c", Req: "1", Optional: true},
+			},
+			Features:  map[string][]string{"default": {"extra", "b/std"}, "extra": {"a"}},
+			Features2: map[string][]string{"extra": {"c?/std"}},
+		}
+		info := version.versionInfo("")
+		if info.DependencyKind("a") != g.KindRuntime || info.DependencyKind("b") != g.KindRuntime || info.DependencyKind("c") != g.KindOptional {
+			t.Errorf("Expected a and b to be enabled by default and c to stay optional, got %v", info.DependencyKinds)
+		}
+	})
+}
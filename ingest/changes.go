@@ -0,0 +1,84 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+// Change is an entry of a registry change feed: a package that was published, updated or deleted
+type Change struct {
+	// Seq is the position of the change in the feed, a later request can continue after it
+	Seq     string
+	Name    string
+	Deleted bool
+	// Time is the moment the document of the package was last modified, it is empty when the feed doesn't hold it
+	Time string
+	// Package holds every version of the package at the moment of the change, it is empty for deleted packages
+	Package g.PackageInfo
+}
+
+// npmChange is a line of the _changes feed of the npm registry, requested with include_docs=true. The last line of a
+// continuous feed only holds last_seq.
+type npmChange struct {
+	Seq     json.RawMessage `json:"seq"`
+	LastSeq json.RawMessage `json:"last_seq"`
+	ID      string          `json:"id"`
+	Deleted bool            `json:"deleted"`
+	Doc     *npmDocument    `json:"doc"`
+}
+
+// ReadChanges reads a change log in the NDJSON format of the npm _changes feed and calls handle for every change in
+// order. Design documents and the closing last_seq line are skipped. It stops at the first error handle returns.
+func ReadChanges(r io.Reader, handle func(Change) error) error {
+	dec := json.NewDecoder(r)
+	for n := 1; ; n++ {
+		var change npmChange
+		if err := dec.Decode(&change); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("change %d is not valid JSON: %w", n, err)
+		}
+		if change.ID == "" || strings.HasPrefix(change.ID, "_design/") {
+			continue
+		}
+		result := Change{Seq: sequence(change.Seq), Name: change.ID, Deleted: change.Deleted}
+		if change.Doc != nil {
+			_ = json.Unmarshal(change.Doc.Time["modified"], &result.Time)
+		}
+		if change.Doc != nil && !change.Deleted {
+			if change.Doc.Name == "" {
+				change.Doc.Name = change.ID
+			}
+			result.Package = convertNpmDocument(*change.Doc)
+		}
+		if err := handle(result); err != nil {
+			return err
+		}
+	}
+}
+
+// LoadChanges reads the change log in a file with ReadChanges
+func LoadChanges(path string, handle func(Change) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return ReadChanges(file, handle)
+}
+
+// sequence returns a sequence number as a string. CouchDB 1 numbers the changes, later versions use opaque strings.
+func sequence(raw json.RawMessage) string {
+	var value string
+	if json.Unmarshal(raw, &value) == nil {
+		return value
+	}
+	return string(raw)
+}
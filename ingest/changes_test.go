@@ -0,0 +1,54 @@
+package ingest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+func TestReadChanges(t *testing.T) {
+	feed := `{"seq":1,"id":"left-pad","changes":[{"rev":"1-a"}],"doc":{"_id":"left-pad","name":"left-pad","versions":{"1.0.0":{"dependencies":{"A":"^1.0.0","B":"^2.0.0"},"devDependencies":{"A":"^1.0.0","C":"*"},"optionalDependencies":{"B":"^2.0.0"},"license":{"type":"MIT"},"author":{"name":"Jane"}}},"time":{"created":"2016-03-01T00:00:00.000Z","modified":"2016-04-01T00:00:00.000Z","1.0.0":"2016-03-01T00:00:00.000Z"}}}
+{"seq":"2-g1AAAA","id":"_design/app","changes":[{"rev":"1-b"}]}
+{"seq":"3-g1AAAA","id":"removed","deleted":true,"changes":[{"rev":"2-c"}]}
+{"last_seq":"3-g1AAAA"}
+`
+	var changes []Change
+	err := ReadChanges(strings.NewReader(feed), func(change Change) error {
+		changes = append(changes, change)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Expected the design document and the last_seq line to be skipped, got %+v", changes)
+	}
+	if changes[0].Seq != "1" || changes[1].Seq != "3-g1AAAA" || !changes[1].Deleted {
+		t.Errorf("Expected the sequences 1 and 3-g1AAAA and the second change to be a deletion, got %+v", changes)
+	}
+	if changes[0].Time != "2016-04-01T00:00:00.000Z" || changes[1].Time != "" {
+		t.Errorf("Expected the modification time of the document and none for the deletion, got %+v", changes)
+	}
+	version := changes[0].Package.Versions["1.0.0"]
+	if changes[0].Package.Name != "left-pad" || version.Timestamp != "2016-03-01T00:00:00.000Z" || version.License != "MIT" || version.Author != "Jane" {
+		t.Errorf("Expected the version to be converted, got %+v", changes[0].Package)
+	}
+	if len(version.Dependencies) != 3 || version.DependencyKind("A") != g.KindRuntime || version.DependencyKind("B") != g.KindOptional || version.DependencyKind("C") != g.KindDev {
+		t.Errorf("Expected A to be a runtime, B an optional and C a development dependency, got %+v", version)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = ReadChanges(strings.NewReader(feed), func(Change) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("Expected reading to stop at the first error, got %v after %d changes", err, calls)
+	}
+	if err := ReadChanges(strings.NewReader("{not json"), func(Change) error { return nil }); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,444 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+// errNotFound is returned for packages the registry doesn't have, they are not retried
+var errNotFound = errors.New("not found")
+
+// CrawlOptions configures a Crawler. Only the registry and the ecosystem have to be set.
+type CrawlOptions struct {
+	// Registry is the base URL of the registry: the npm registry, the PyPI server that serves /pypi/<name>/json or
+	// the root of a Maven repository
+	Registry  string
+	Ecosystem g.Ecosystem
+	// Follow also crawls the dependencies of the crawled packages, until every package they reach is crawled
+	Follow bool
+	// Concurrency is the number of packages that are requested at the same time, 4 by default
+	Concurrency int
+	// Retries is the number of times a failed request is repeated. Only network errors, 429 and 5xx responses are
+	// retried. The wait starts at Backoff and doubles with every retry, unless the response says how long to wait.
+	Retries int
+	Backoff time.Duration
+	// Checkpoint is a file the progress is written to every CheckpointEvery packages, so a crawl that was interrupted
+	// continues where it stopped. An existing checkpoint is read when the crawl starts. The crawled packages are
+	// appended to an NDJSON file next to it as they come in, see CrawledPackagesPath, the checkpoint only holds the
+	// names that are still queued, missing or failed.
+	Checkpoint      string
+	CheckpointEvery int
+	Client          *http.Client
+	// Progress is called after every package with the number of crawled packages and the number still queued
+	Progress func(done, queued int)
+}
+
+// CrawlResult holds the crawled packages ordered by name, and the packages the registry doesn't have or that could
+// not be read
+type CrawlResult struct {
+	Packages []g.PackageInfo
+	Missing  []string
+	Failed   map[string]string
+}
+
+// crawlCheckpoint is the progress of a crawl as it is written to the checkpoint file. The packages are not written
+// with it, rewriting all of them at every checkpoint would take quadratic time.
+type crawlCheckpoint struct {
+	Registry  string            `json:"registry"`
+	Ecosystem g.Ecosystem       `json:"ecosystem"`
+	Packages  []g.PackageInfo   `json:"-"`
+	Missing   []string          `json:"missing"`
+	Failed    map[string]string `json:"failed"`
+	Queue     []string          `json:"queue"`
+}
+
+// CrawledPackagesPath returns the NDJSON file the crawled packages of a checkpoint are appended to: the checkpoint
+// path with its extension replaced by .packages.ndjson
+func CrawledPackagesPath(checkpoint string) string {
+	return strings.TrimSuffix(checkpoint, filepath.Ext(checkpoint)) + ".packages.ndjson"
+}
+
+// Crawler reads packages from a registry mirror over HTTP
+type Crawler struct {
+	options CrawlOptions
+}
+
+// NewCrawler validates the options and fills in the defaults
+func NewCrawler(options CrawlOptions) (*Crawler, error) {
+	if options.Registry == "" {
+		return nil, errors.New("the registry URL has to be set")
+	}
+	options.Registry = strings.TrimSuffix(options.Registry, "/")
+	switch options.Ecosystem {
+	case g.NPM, g.PyPI, g.Maven:
+	default:
+		return nil, fmt.Errorf("crawling %s registries is not supported", options.Ecosystem)
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 4
+	}
+	if options.Backoff <= 0 {
+		options.Backoff = time.Second
+	}
+	if options.CheckpointEvery <= 0 {
+		options.CheckpointEvery = 100
+	}
+	if options.Client == nil {
+		options.Client = &http.Client{Timeout: time.Minute}
+	}
+	return &Crawler{options: options}, nil
+}
+
+type crawlOutcome struct {
+	name string
+	pkg  g.PackageInfo
+	err  error
+}
+
+// Crawl requests the packages and, with Follow, their dependencies. Packages that are in the checkpoint are not
+// requested again. It only returns an error when the checkpoint can't be read or written or when the context is
+// canceled, the checkpoint then holds the progress so far.
+func (c *Crawler) Crawl(ctx context.Context, names []string) (*CrawlResult, error) {
+	state := crawlCheckpoint{Registry: c.options.Registry, Ecosystem: c.options.Ecosystem, Failed: make(map[string]string)}
+	var crawled *os.File
+	if c.options.Checkpoint != "" {
+		resume, err := c.readCheckpoint(&state)
+		if err != nil {
+			return nil, err
+		}
+		if crawled, err = openCrawledPackages(CrawledPackagesPath(c.options.Checkpoint), resume, &state.Packages); err != nil {
+			return nil, err
+		}
+		defer crawled.Close()
+	}
+	seen := make(map[string]bool)
+	for _, pkg := range state.Packages {
+		seen[pkg.Name] = true
+	}
+	for _, name := range state.Missing {
+		seen[name] = true
+	}
+	for name := range state.Failed {
+		seen[name] = true
+	}
+	var queue []string
+	for _, name := range append(state.Queue, names...) {
+		if name = c.normalize(name); name != "" && !seen[name] {
+			seen[name] = true
+			queue = append(queue, name)
+		}
+	}
+	if c.options.Follow {
+		// A package can be appended after the last checkpoint, its dependencies are not in the queue of the checkpoint
+		for _, pkg := range state.Packages {
+			for _, dependency := range dependencyNames(pkg) {
+				if !seen[dependency] {
+					seen[dependency] = true
+					queue = append(queue, dependency)
+				}
+			}
+		}
+	}
+
+	jobs := make(chan string)
+	// The workers can always hand in their last outcome, also when the crawl stops early
+	outcomes := make(chan crawlOutcome, c.options.Concurrency)
+	for i := 0; i < c.options.Concurrency; i++ {
+		go func() {
+			for name := range jobs {
+				pkg, err := c.fetch(ctx, name)
+				outcomes <- crawlOutcome{name: name, pkg: pkg, err: err}
+			}
+		}()
+	}
+	defer close(jobs)
+
+	// completed stores a crawled package and queues its dependencies
+	completed := func(pkg g.PackageInfo) error {
+		state.Packages = append(state.Packages, pkg)
+		if crawled != nil {
+			line, err := json.Marshal(pkg)
+			if err != nil {
+				return err
+			}
+			if _, err := crawled.Write(append(line, '\n')); err != nil {
+				return err
+			}
+		}
+		if c.options.Follow {
+			for _, dependency := range dependencyNames(pkg) {
+				if !seen[dependency] {
+					seen[dependency] = true
+					queue = append(queue, dependency)
+				}
+			}
+		}
+		return nil
+	}
+	inFlight := make(map[string]bool)
+	sinceCheckpoint := 0
+	for len(queue) > 0 || len(inFlight) > 0 {
+		var send chan string
+		var next string
+		if len(queue) > 0 {
+			send, next = jobs, queue[0]
+		}
+		select {
+		case send <- next:
+			queue = queue[1:]
+			inFlight[next] = true
+		case outcome := <-outcomes:
+			delete(inFlight, outcome.name)
+			switch {
+			case errors.Is(outcome.err, errNotFound):
+				state.Missing = append(state.Missing, outcome.name)
+			case outcome.err != nil && ctx.Err() != nil:
+				// The request was canceled, it is queued again in the checkpoint
+				queue = append(queue, outcome.name)
+			case outcome.err != nil:
+				state.Failed[outcome.name] = outcome.err.Error()
+			default:
+				if err := completed(outcome.pkg); err != nil {
+					return nil, err
+				}
+			}
+			if c.options.Progress != nil {
+				c.options.Progress(len(state.Packages), len(queue)+len(inFlight))
+			}
+			if sinceCheckpoint++; c.options.Checkpoint != "" && sinceCheckpoint >= c.options.CheckpointEvery {
+				sinceCheckpoint = 0
+				if err := c.writeCheckpoint(state, queue, inFlight); err != nil {
+					return nil, err
+				}
+			}
+		case <-ctx.Done():
+			// Wait for the requests in flight, the ones that were canceled are queued again in the checkpoint
+			for len(inFlight) > 0 {
+				outcome := <-outcomes
+				delete(inFlight, outcome.name)
+				if outcome.err == nil {
+					if err := completed(outcome.pkg); err != nil {
+						return nil, err
+					}
+				} else {
+					queue = append(queue, outcome.name)
+				}
+			}
+			if c.options.Checkpoint != "" {
+				if err := c.writeCheckpoint(state, queue, inFlight); err != nil {
+					return nil, err
+				}
+			}
+			return nil, ctx.Err()
+		}
+	}
+	if c.options.Checkpoint != "" {
+		if err := c.writeCheckpoint(state, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(state.Packages, func(i, j int) bool {
+		return state.Packages[i].Name < state.Packages[j].Name
+	})
+	sort.Strings(state.Missing)
+	return &CrawlResult{Packages: state.Packages, Missing: state.Missing, Failed: state.Failed}, nil
+}
+
+// normalize returns the name under which the registry and the graph know a package
+func (c *Crawler) normalize(name string) string {
+	name = strings.TrimSpace(name)
+	if c.options.Ecosystem == g.PyPI {
+		return normalizePyPIName(name)
+	}
+	return name
+}
+
+func (c *Crawler) fetch(ctx context.Context, name string) (g.PackageInfo, error) {
+	switch c.options.Ecosystem {
+	case g.PyPI:
+		return c.fetchPyPI(ctx, name)
+	case g.Maven:
+		return c.fetchMaven(ctx, name)
+	}
+	return c.fetchNpm(ctx, name)
+}
+
+// dependencyNames returns the names of the packages the versions of a package depend on, ordered by name
+func dependencyNames(pkg g.PackageInfo) []string {
+	names := make(map[string]bool)
+	for _, versionInfo := range pkg.Versions {
+		for name := range versionInfo.Dependencies {
+			names[name] = true
+		}
+	}
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// get requests a URL and retries it when the registry is unavailable
+func (c *Crawler) get(ctx context.Context, url string) ([]byte, http.Header, error) {
+	wait := c.options.Backoff
+	for attempt := 0; ; attempt++ {
+		data, header, retryAfter, err := c.getOnce(ctx, url)
+		if err == nil || errors.Is(err, errNotFound) || retryAfter < 0 || attempt >= c.options.Retries || ctx.Err() != nil {
+			return data, header, err
+		}
+		if retryAfter == 0 {
+			retryAfter = wait
+			wait *= 2
+		}
+		select {
+		case <-time.After(retryAfter):
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+// getOnce does a single request. The duration is how long to wait before it is retried: zero to use the backoff and
+// negative when it should not be retried.
+func (c *Crawler) getOnce(ctx context.Context, url string) ([]byte, http.Header, time.Duration, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, -1, err
+	}
+	request.Header.Set("Accept", "application/json, application/xml;q=0.9, */*;q=0.8")
+	response, err := c.options.Client.Do(request)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	switch {
+	case response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone:
+		return nil, nil, -1, errNotFound
+	case response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500:
+		var retryAfter time.Duration
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return nil, nil, retryAfter, fmt.Errorf("GET %s: %s", url, response.Status)
+	case response.StatusCode != http.StatusOK:
+		return nil, nil, -1, fmt.Errorf("GET %s: %s", url, response.Status)
+	case err != nil:
+		return nil, nil, 0, err
+	}
+	return data, response.Header, 0, nil
+}
+
+// readCheckpoint loads the checkpoint file when it exists and tells whether it did. A checkpoint of another registry
+// or ecosystem is an error, mixing them would silently give a wrong graph.
+func (c *Crawler) readCheckpoint(state *crawlCheckpoint) (bool, error) {
+	data, err := os.ReadFile(c.options.Checkpoint)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var checkpoint crawlCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return false, fmt.Errorf("%s is not a crawl checkpoint: %w", c.options.Checkpoint, err)
+	}
+	if checkpoint.Registry != state.Registry || checkpoint.Ecosystem != state.Ecosystem {
+		return false, fmt.Errorf("%s is a checkpoint of %s (%s), not of %s (%s)", c.options.Checkpoint, checkpoint.Registry, checkpoint.Ecosystem, state.Registry, state.Ecosystem)
+	}
+	if checkpoint.Failed == nil {
+		checkpoint.Failed = make(map[string]string)
+	}
+	*state = checkpoint
+	return true, nil
+}
+
+// openCrawledPackages opens the file the crawled packages are appended to. When the crawl resumes from a checkpoint
+// the packages in it are read first, a last line that was cut off by an interruption is dropped. Otherwise the file is
+// started over, the packages of a crawl without checkpoint don't belong to this one.
+func openCrawledPackages(path string, resume bool, packages *[]g.PackageInfo) (*os.File, error) {
+	if !resume {
+		return os.Create(path)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(file)
+	var offset int64
+	for n := 1; ; n++ {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		var pkg g.PackageInfo
+		if err := json.Unmarshal(line, &pkg); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s: package %d: %w", path, n, err)
+		}
+		*packages = append(*packages, pkg)
+		offset += int64(len(line))
+	}
+	if err := file.Truncate(offset); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// writeCheckpoint writes the progress to a temporary file first, so an interrupted write doesn't destroy the last
+// checkpoint
+func (c *Crawler) writeCheckpoint(state crawlCheckpoint, queue []string, inFlight map[string]bool) error {
+	state.Queue = append([]string{}, queue...)
+	for name := range inFlight {
+		state.Queue = append(state.Queue, name)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	temporary := c.options.Checkpoint + ".tmp"
+	if err := os.WriteFile(temporary, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(temporary, c.options.Checkpoint)
+}
+
+// WritePackages writes packages to a JSON file in the format LoadPackages reads
+func WritePackages(path string, packages []g.PackageInfo) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if packages == nil {
+		packages = []g.PackageInfo{}
+	}
+	if err := json.NewEncoder(file).Encode(packages); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,214 @@
+package ingest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+// registryStandIn serves fixed responses per path and counts the requests. A path listed in failures first fails
+// that many times with 503.
+type registryStandIn struct {
+	mu        sync.Mutex
+	responses map[string]string
+	headers   map[string]map[string]string
+	failures  map[string]int
+	requests  map[string]int
+}
+
+func (r *registryStandIn) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	path := request.URL.EscapedPath()
+	r.requests[path]++
+	if r.failures[path] > 0 {
+		r.failures[path]--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, ok := r.responses[path]
+	if !ok {
+		http.NotFound(w, request)
+		return
+	}
+	for key, value := range r.headers[path] {
+		w.Header().Set(key, value)
+	}
+	_, _ = w.Write([]byte(body))
+}
+
+func newRegistryStandIn(responses map[string]string) (*registryStandIn, *httptest.Server) {
+	registry := &registryStandIn{
+		responses: responses,
+		headers:   make(map[string]map[string]string),
+		failures:  make(map[string]int),
+		requests:  make(map[string]int),
+	}
+	return registry, httptest.NewServer(registry)
+}
+
+func TestCrawlNpm(t *testing.T) {
+	registry, server := newRegistryStandIn(map[string]string{
+		"/app": `{"name": "app", "versions": {"1.0.0": {"dependencies": {"@scope/lib": "^1.0.0", "gone": "*"}}},
+			"time": {"1.0.0": "2022-01-01T00:00:00.000Z"}}`,
+		"/@scope%2Flib": `{"name": "@scope/lib", "versions": {"1.0.0": {}, "1.1.0": {}},
+			"time": {"1.0.0": "2021-01-01T00:00:00.000Z", "1.1.0": "2021-06-01T00:00:00.000Z"}}`,
+	})
+	defer server.Close()
+	registry.failures["/app"] = 2
+	checkpoint := filepath.Join(t.TempDir(), "crawl.json")
+
+	crawler, err := NewCrawler(CrawlOptions{Registry: server.URL, Ecosystem: g.NPM, Follow: true, Retries: 2, Backoff: time.Millisecond, Checkpoint: checkpoint})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := crawler.Crawl(context.Background(), []string{"app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Packages) != 2 || result.Packages[0].Name != "@scope/lib" || len(result.Packages[0].Versions) != 2 {
+		t.Fatalf("Expected app and its dependency @scope/lib, got %+v", result.Packages)
+	}
+	if len(result.Missing) != 1 || result.Missing[0] != "gone" || len(result.Failed) != 0 {
+		t.Errorf("Expected gone to be missing, got %v and %v", result.Missing, result.Failed)
+	}
+	if registry.requests["/app"] != 3 {
+		t.Errorf("Expected app to be requested again twice, got %d requests", registry.requests["/app"])
+	}
+
+	t.Run("Keeps the packages out of the checkpoint", func(t *testing.T) {
+		data, err := os.ReadFile(checkpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "versions") {
+			t.Errorf("Expected the checkpoint to hold only names, got %s", data)
+		}
+		crawled, err := os.ReadFile(CrawledPackagesPath(checkpoint))
+		if err != nil || strings.Count(string(crawled), "\n") != 2 {
+			t.Errorf("Expected the 2 crawled packages in %s, got %q (%v)", CrawledPackagesPath(checkpoint), crawled, err)
+		}
+	})
+
+	t.Run("Continues from the checkpoint", func(t *testing.T) {
+		registry.responses["/other"] = `{"name": "other", "versions": {"1.0.0": {}}}`
+		result, err := crawler.Crawl(context.Background(), []string{"app", "other"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Packages) != 3 || registry.requests["/app"] != 3 || registry.requests["/other"] != 1 {
+			t.Errorf("Expected only other to be requested, got %+v and %v", result.Packages, registry.requests)
+		}
+	})
+
+	t.Run("Drops a package that was cut off", func(t *testing.T) {
+		file, err := os.OpenFile(CrawledPackagesPath(checkpoint), os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = file.WriteString(`{"name": "half`)
+		file.Close()
+		result, err := crawler.Crawl(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Packages) != 3 {
+			t.Errorf("Expected the 3 complete packages, got %+v", result.Packages)
+		}
+	})
+
+	t.Run("Rejects the checkpoint of another registry", func(t *testing.T) {
+		other, _ := NewCrawler(CrawlOptions{Registry: "http://localhost:1", Ecosystem: g.NPM, Checkpoint: checkpoint})
+		if _, err := other.Crawl(context.Background(), nil); err == nil {
+			t.Error("Expected an error for a checkpoint of another registry")
+		}
+	})
+
+	t.Run("Gives up after the retries", func(t *testing.T) {
+		registry.failures["/flaky"] = 5
+		crawler, _ := NewCrawler(CrawlOptions{Registry: server.URL, Ecosystem: g.NPM, Retries: 1, Backoff: time.Millisecond})
+		result, err := crawler.Crawl(context.Background(), []string{"flaky"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := result.Failed["flaky"]; !ok || registry.requests["/flaky"] != 2 {
+			t.Errorf("Expected flaky to fail after 2 requests, got %v after %d", result.Failed, registry.requests["/flaky"])
+		}
+	})
+}
+
+func TestCrawlPyPI(t *testing.T) {
+	_, server := newRegistryStandIn(map[string]string{
+		"/pypi/web/json": `{"info": {"name": "Web"}, "releases": {"1.0": [
+			{"upload_time_iso_8601": "2020-02-01T00:00:00Z"}, {"upload_time_iso_8601": "2020-01-01T00:00:00Z", "yanked": true}],
+			"0.9": [{"upload_time_iso_8601": "2019-01-01T00:00:00Z", "yanked": true}]}}`,
+		"/pypi/web/0.9/json": `{"info": {"name": "Web"}}`,
+		"/pypi/web/1.0/json": `{"info": {"name": "Web", "license": "MIT", "requires_dist": [
+			"Requests_Lib (>=2.0,<3)", "urllib3~=1.26.0", "pytest ; extra == \"test\"", "not a requirement!"]}}`,
+	})
+	defer server.Close()
+
+	crawler, _ := NewCrawler(CrawlOptions{Registry: server.URL, Ecosystem: g.PyPI})
+	result, err := crawler.Crawl(context.Background(), []string{"Web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Packages) != 1 {
+		t.Fatalf("Expected the web project, got %+v", result)
+	}
+	version := result.Packages[0].Versions["1.0"]
+	if version.Timestamp != "2020-01-01T00:00:00Z" || version.License != "MIT" {
+		t.Errorf("Expected the first upload time and the license, got %+v", version)
+	}
+	want := map[string]string{"requests-lib": ">=2.0, <3", "urllib3": ">=1.26.0, <1.27", "pytest": "*"}
+	for name, constraint := range want {
+		if version.Dependencies[name] != constraint {
+			t.Errorf("Expected %s %s, got %q", name, constraint, version.Dependencies[name])
+		}
+	}
+	if len(version.Dependencies) != 3 || version.DependencyKind("pytest") != g.KindOptional {
+		t.Errorf("Expected 3 dependencies of which pytest is optional, got %+v", version)
+	}
+	if version.Yanked || !result.Packages[0].Versions["0.9"].Yanked {
+		t.Error("Expected only 0.9 to be yanked, 1.0 still has a file that is not")
+	}
+}
+
+func TestCrawlMaven(t *testing.T) {
+	registry, server := newRegistryStandIn(map[string]string{
+		"/org/example/app/maven-metadata.xml": `<metadata><groupId>org.example</groupId><artifactId>app</artifactId>
+			<versioning><versions><version>1.0</version><version>1.1</version></versions></versioning></metadata>`,
+		"/org/example/app/1.0/app-1.0.pom": `<project><groupId>org.example</groupId><artifactId>app</artifactId>
+			<version>1.0</version><properties><junit.version>4.13</junit.version></properties><dependencies>
+			<dependency><groupId>${project.groupId}</groupId><artifactId>core</artifactId><version>${project.version}</version></dependency>
+			<dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>${junit.version}</version><scope>test</scope></dependency>
+			<dependency><groupId>org.example</groupId><artifactId>managed</artifactId></dependency>
+			</dependencies></project>`,
+	})
+	defer server.Close()
+	registry.headers["/org/example/app/1.0/app-1.0.pom"] = map[string]string{"Last-Modified": "Tue, 01 Mar 2022 10:00:00 GMT"}
+
+	crawler, _ := NewCrawler(CrawlOptions{Registry: server.URL + "/", Ecosystem: g.Maven})
+	result, err := crawler.Crawl(context.Background(), []string{"org.example:app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Packages) != 1 || len(result.Packages[0].Versions) != 1 {
+		t.Fatalf("Expected version 1.0 of app, the POM of 1.1 is missing, got %+v", result.Packages)
+	}
+	version := result.Packages[0].Versions["1.0"]
+	if version.Timestamp != "2022-03-01T10:00:00Z" {
+		t.Errorf("Expected the Last-Modified time as timestamp, got %q", version.Timestamp)
+	}
+	if len(version.Dependencies) != 2 || version.Dependencies["org.example:core"] != "1.0" || version.Dependencies["junit:junit"] != "4.13" || version.DependencyKind("junit:junit") != g.KindDev {
+		t.Errorf("Expected org.example:core 1.0 and junit:junit 4.13 as test dependency, got %+v", version)
+	}
+}
@@ -0,0 +1,173 @@
+package ingest
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+// csvColumns are the columns ParseDependenciesCSV reads, author is optional
+var csvColumns = []string{"name", "version", "upload_time", "dependency", "dependency_version"}
+
+// LoadPackages reads the packages from an input file. CSV files are read with ParseDependenciesCSV, directories with
+// LoadCargoIndex when they are a registry index, with LoadGoModuleCache when they are a Go module cache and with
+// LoadMavenRepository otherwise, everything else is expected to be JSON and is read with StreamPackages. Files can be
+// compressed with gzip or zstd. The versions of a directory that could not be read are returned with the reason, like
+// those functions do, other inputs don't skip versions.
+func LoadPackages(path string) (*[]g.PackageInfo, map[string]string, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		if isCargoIndex(path) {
+			return LoadCargoIndex(path)
+		}
+		if isGoModuleCache(path) {
+			return LoadGoModuleCache(path)
+		}
+		return LoadMavenRepository(path)
+	}
+	if inputFormat(path) == ".csv" {
+		packages, err := ParseDependenciesCSV(path)
+		return packages, nil, err
+	}
+	result := make([]g.PackageInfo, 0)
+	err := StreamPackages(path, func(packageInfo g.PackageInfo) error {
+		result = append(result, packageInfo)
+		return nil
+	})
+	return &result, nil, err
+}
+
+// ParseDependenciesCSV reads a CSV file with a row for every dependency of a version, in the format of
+// dependencies.csv: name, version, upload_time, dependency, dependency_version and optionally author. The columns are
+// found by their header. A version without dependencies has a row with an empty dependency. The file can be compressed
+// like the files OpenInput reads.
+func ParseDependenciesCSV(path string) (*[]g.PackageInfo, error) {
+	result := make([]g.PackageInfo, 0)
+	indices := make(map[string]int)
+	err := readDependenciesCSV(path, func(_ int, row dependencyRow) error {
+		index, ok := indices[row.name]
+		if !ok {
+			index = len(result)
+			indices[row.name] = index
+			result = append(result, g.PackageInfo{Name: row.name, Versions: make(map[string]g.VersionInfo)})
+		}
+		row.addTo(&result[index])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// StreamDependenciesCSV reads the same files as ParseDependenciesCSV, but calls handle for every package as soon as
+// its last row is read, so only the current package is in memory. That requires the rows of a package to be next to
+// each other, like in a file sorted by name, it returns an error when a package appears again after another one.
+func StreamDependenciesCSV(path string, handle func(g.PackageInfo) error) error {
+	var current *g.PackageInfo
+	// Only the names of the finished packages are kept, to notice the rows that are out of order
+	finished := make(map[string]bool)
+	err := readDependenciesCSV(path, func(line int, row dependencyRow) error {
+		if current != nil && current.Name != row.name {
+			finished[current.Name] = true
+			if err := handle(*current); err != nil {
+				return err
+			}
+			current = nil
+		}
+		if current == nil {
+			if finished[row.name] {
+				return fmt.Errorf("%s:%d: the rows of %s are not next to each other, the file has to be sorted by name to stream it", path, line, row.name)
+			}
+			current = &g.PackageInfo{Name: row.name, Versions: make(map[string]g.VersionInfo)}
+		}
+		row.addTo(current)
+		return nil
+	})
+	if err != nil || current == nil {
+		return err
+	}
+	return handle(*current)
+}
+
+// dependencyRow is one row of a dependencies CSV file
+type dependencyRow struct {
+	name, version, timestamp, author string
+	dependency, constraint           string
+}
+
+// addTo adds the version of the row to the package, or the dependency of the row to the version when the package
+// already has it
+func (row dependencyRow) addTo(packageInfo *g.PackageInfo) {
+	versionInfo, ok := packageInfo.Versions[row.version]
+	if !ok {
+		versionInfo = g.VersionInfo{Timestamp: row.timestamp, Dependencies: make(map[string]string), Author: row.author}
+	}
+	if row.dependency != "" {
+		versionInfo.Dependencies[row.dependency] = row.constraint
+	}
+	packageInfo.Versions[row.version] = versionInfo
+}
+
+// readDependenciesCSV calls handle for every row of a dependencies CSV file after the header, with its line number.
+// It stops at the first error handle returns.
+func readDependenciesCSV(path string, handle func(line int, row dependencyRow) error) error {
+	file, err := OpenInput(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("%s has no header: %w", path, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range csvColumns {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("%s has no %s column", path, name)
+		}
+	}
+	authorColumn, hasAuthor := columns["author"]
+	field := func(record []string, column int) string {
+		if column < len(record) {
+			return strings.TrimSpace(record[column])
+		}
+		return ""
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		row := dependencyRow{
+			name:       field(record, columns["name"]),
+			version:    field(record, columns["version"]),
+			timestamp:  field(record, columns["upload_time"]),
+			dependency: field(record, columns["dependency"]),
+			constraint: field(record, columns["dependency_version"]),
+		}
+		if hasAuthor {
+			row.author = field(record, authorColumn)
+		}
+		if row.name == "" || row.version == "" {
+			return fmt.Errorf("%s:%d has no name or version", path, line)
+		}
+		if err := handle(line, row); err != nil {
+			return err
+		}
+	}
+}
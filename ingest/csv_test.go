@@ -0,0 +1,41 @@
+package ingest
+
+import (
+	"reflect"
+	"testing"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+func TestParseDependenciesCSV(t *testing.T) {
+	path := writeTestFile(t, "dependencies.csv", `name,version,upload_time,dependency,dependency_version,author
+wscheck,1.3.2,2021-02-08T14:00:21,lxml,>=2.3,Andras Tim
+wscheck,1.3.2,2021-02-08T14:00:21,termcolor,"<1.2,>=1.1.0",Andras Tim
+odoo12-addon,12.0.1.0.2,2021-03-04T06:10:54,,,"Tecnativa, Odoo Community Association (OCA)"
+`)
+	packages, _, err := LoadPackages(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []g.PackageInfo{
+		{Name: "wscheck", Versions: map[string]g.VersionInfo{"1.3.2": {
+			Timestamp:    "2021-02-08T14:00:21",
+			Dependencies: map[string]string{"lxml": ">=2.3", "termcolor": "<1.2,>=1.1.0"},
+			Author:       "Andras Tim",
+		}}},
+		{Name: "odoo12-addon", Versions: map[string]g.VersionInfo{"12.0.1.0.2": {
+			Timestamp:    "2021-03-04T06:10:54",
+			Dependencies: map[string]string{},
+			Author:       "Tecnativa, Odoo Community Association (OCA)",
+		}}},
+	}
+	if !reflect.DeepEqual(*packages, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, *packages)
+	}
+
+	t.Run("Requires the dependency columns", func(t *testing.T) {
+		if _, err := ParseDependenciesCSV(writeTestFile(t, "broken.csv", "name,version\nA,1.0.0\n")); err == nil {
+			t.Error("Expected an error for a file without the dependency columns")
+		}
+	})
+}
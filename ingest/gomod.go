@@ -0,0 +1,330 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+// goModule is a module path with a version. The version is empty in the replacements of a whole module and in
+// replacements with a local directory.
+type goModule struct {
+	Path    string
+	Version string
+}
+
+type goReplace struct {
+	Old goModule
+	New goModule
+}
+
+// goRetract is a retracted version or the interval [Low, High] of retracted versions
+type goRetract struct {
+	Low  string
+	High string
+}
+
+// goModFile holds the directives of a go.mod file the graph needs
+type goModFile struct {
+	Module  string
+	Require []goModule
+	Exclude []goModule
+	Replace []goReplace
+	Retract []goRetract
+}
+
+// retracts tells whether the go.mod file retracts the version of its module
+func (f *goModFile) retracts(version string) bool {
+	for _, retract := range f.Retract {
+		if g.CompareVersions(g.Go, version, retract.Low) >= 0 && g.CompareVersions(g.Go, version, retract.High) <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// parseGoMod reads a go.mod file. Directives can be written on one line or as a block, the directives the graph
+// doesn't need (go, toolchain, ...) are skipped.
+func parseGoMod(data []byte) (*goModFile, error) {
+	result := &goModFile{}
+	block := ""
+	for n, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var directive string
+		switch {
+		case block != "" && fields[0] == ")":
+			block = ""
+			continue
+		case block != "":
+			directive = block
+		case len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+			continue
+		default:
+			directive, fields = fields[0], fields[1:]
+		}
+		for i, field := range fields {
+			if unquoted, err := strconv.Unquote(field); err == nil {
+				fields[i] = unquoted
+			}
+		}
+
+		switch directive {
+		case "module":
+			if len(fields) != 1 {
+				return nil, fmt.Errorf("line %d: invalid module directive", n+1)
+			}
+			result.Module = fields[0]
+		case "require", "exclude":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: invalid %s directive", n+1, directive)
+			}
+			module := goModule{Path: fields[0], Version: fields[1]}
+			if directive == "require" {
+				result.Require = append(result.Require, module)
+			} else {
+				result.Exclude = append(result.Exclude, module)
+			}
+		case "replace":
+			// old [version] => new [version]
+			arrow := -1
+			for i, field := range fields {
+				if field == "=>" {
+					arrow = i
+				}
+			}
+			if arrow < 1 || arrow > 2 || len(fields) < arrow+2 || len(fields) > arrow+3 {
+				return nil, fmt.Errorf("line %d: invalid replace directive", n+1)
+			}
+			replace := goReplace{Old: goModule{Path: fields[0]}, New: goModule{Path: fields[arrow+1]}}
+			if arrow == 2 {
+				replace.Old.Version = fields[1]
+			}
+			if len(fields) == arrow+3 {
+				replace.New.Version = fields[arrow+2]
+			}
+			result.Replace = append(result.Replace, replace)
+		case "retract":
+			// version or [low, high]
+			retracted := strings.Join(fields, "")
+			retract := goRetract{Low: retracted, High: retracted}
+			if strings.HasPrefix(retracted, "[") && strings.HasSuffix(retracted, "]") {
+				low, high, ok := strings.Cut(retracted[1:len(retracted)-1], ",")
+				if !ok {
+					return nil, fmt.Errorf("line %d: invalid retract directive", n+1)
+				}
+				retract = goRetract{Low: low, High: high}
+			}
+			if retract.Low == "" || retract.High == "" {
+				return nil, fmt.Errorf("line %d: invalid retract directive", n+1)
+			}
+			result.Retract = append(result.Retract, retract)
+		}
+	}
+	return result, nil
+}
+
+// unescapeModulePath undoes the escaping of the module cache, which writes upper case letters as ! followed by the
+// lower case letter so module paths that only differ in case don't collide on case-insensitive file systems
+func unescapeModulePath(escaped string) (string, error) {
+	var builder strings.Builder
+	bang := false
+	for _, r := range escaped {
+		switch {
+		case bang:
+			if !unicode.IsLower(r) {
+				return "", fmt.Errorf("invalid escaped module path %q", escaped)
+			}
+			builder.WriteRune(unicode.ToUpper(r))
+			bang = false
+		case r == '!':
+			bang = true
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	if bang {
+		return "", fmt.Errorf("invalid escaped module path %q", escaped)
+	}
+	return builder.String(), nil
+}
+
+// goModuleVersion is a version in the module cache with its go.mod file
+type goModuleVersion struct {
+	version   string
+	timestamp string
+	file      *goModFile
+}
+
+// LoadGoModuleCache reads the go.mod files in a Go module cache ($GOMODCACHE, its cache/download directory or a
+// directory in the GOPROXY layout). Every module/@v/version.mod file becomes a version, published at the time in its
+// .info file. The requirements become dependencies on exactly the required version: minimal version selection builds
+// with the highest required version of every module, never with a newer one that happens to exist, see
+// graph.MinimalVersionSelection. The replace and exclude directives are stored with the version, the go command only
+// applies them when the version is the main module, which MinimalVersionSelection does. Pseudo-versions and
+// +incompatible versions are versions like any other. The versions the go.mod of the highest version retracts are
+// marked as yanked, the go command doesn't pick them anymore either. Versions whose go.mod can't be read are left out,
+// the second result maps their string ids to the reason.
+func LoadGoModuleCache(root string) (*[]g.PackageInfo, map[string]string, error) {
+	if info, err := os.Stat(filepath.Join(root, "cache", "download")); err == nil && info.IsDir() {
+		root = filepath.Join(root, "cache", "download")
+	}
+	modules := make(map[string][]goModuleVersion)
+	skipped := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(path) != ".mod" || filepath.Base(filepath.Dir(path)) != "@v" {
+			return err
+		}
+		relative, err := filepath.Rel(root, filepath.Dir(filepath.Dir(path)))
+		if err != nil {
+			return err
+		}
+		modulePath, err := unescapeModulePath(filepath.ToSlash(relative))
+		if err != nil {
+			return nil
+		}
+		version, err := unescapeModulePath(strings.TrimSuffix(entry.Name(), ".mod"))
+		if err != nil {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		file, err := parseGoMod(data)
+		if err != nil {
+			skipped[g.StringID(modulePath, version)] = err.Error()
+			return nil
+		}
+		modules[modulePath] = append(modules[modulePath], goModuleVersion{
+			version:   version,
+			timestamp: goInfoTime(strings.TrimSuffix(path, ".mod")+".info", entry),
+			file:      file,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := make([]g.PackageInfo, 0, len(modules))
+	for modulePath, moduleVersions := range modules {
+		latest := moduleVersions[0]
+		for _, moduleVersion := range moduleVersions[1:] {
+			if g.CompareVersions(g.Go, moduleVersion.version, latest.version) > 0 {
+				latest = moduleVersion
+			}
+		}
+		packageInfo := g.PackageInfo{Name: modulePath, Versions: make(map[string]g.VersionInfo, len(moduleVersions))}
+		for _, moduleVersion := range moduleVersions {
+			versionInfo := g.VersionInfo{
+				Timestamp:        moduleVersion.timestamp,
+				Dependencies:     goDependencies(modulePath, moduleVersion.file),
+				ModuleDirectives: goDirectives(moduleVersion.file),
+			}
+			versionInfo.Yanked = latest.file.retracts(moduleVersion.version)
+			packageInfo.Versions[moduleVersion.version] = versionInfo
+		}
+		result = append(result, packageInfo)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return &result, skipped, nil
+}
+
+// goDependencies returns the requirements of a go.mod file as they are written. A module that requires itself is left
+// out, and of a module that is required twice the higher version.
+func goDependencies(modulePath string, file *goModFile) map[string]string {
+	result := make(map[string]string, len(file.Require))
+	for _, required := range file.Require {
+		if required.Path == modulePath {
+			continue
+		}
+		if current, ok := result[required.Path]; !ok || g.CompareVersions(g.Go, required.Version, current) > 0 {
+			result[required.Path] = required.Version
+		}
+	}
+	return result
+}
+
+// goDirectives converts the replace and exclude directives of a go.mod file to the string ids the graph uses
+func goDirectives(file *goModFile) g.ModuleDirectives {
+	var result g.ModuleDirectives
+	for _, replace := range file.Replace {
+		if result.Replace == nil {
+			result.Replace = make(map[string]string, len(file.Replace))
+		}
+		old := replace.Old.Path
+		if replace.Old.Version != "" {
+			old = g.StringID(replace.Old.Path, replace.Old.Version)
+		}
+		// A local directory has no version
+		result.Replace[old] = ""
+		if replace.New.Version != "" {
+			result.Replace[old] = g.StringID(replace.New.Path, replace.New.Version)
+		}
+	}
+	for _, excluded := range file.Exclude {
+		result.Exclude = append(result.Exclude, g.StringID(excluded.Path, excluded.Version))
+	}
+	return result
+}
+
+// goInfoTime reads the time from the .info file of a version, or uses the modification time of the go.mod file when
+// there is none
+func goInfoTime(path string, entry fs.DirEntry) string {
+	var info struct {
+		Version string
+		Time    time.Time
+	}
+	if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &info) == nil && !info.Time.IsZero() {
+		return info.Time.UTC().Format(time.RFC3339)
+	}
+	if fileInfo, err := entry.Info(); err == nil {
+		return fileInfo.ModTime().UTC().Format(time.RFC3339)
+	}
+	return ""
+}
+
+// errLayoutFound stops the walk of isGoModuleCache
+var errLayoutFound = errors.New("layout found")
+
+// isGoModuleCache tells whether a directory is a Go module cache rather than a Maven repository, by the first @v
+// directory or POM file in it
+func isGoModuleCache(root string) bool {
+	if info, err := os.Stat(filepath.Join(root, "cache", "download")); err == nil && info.IsDir() {
+		return true
+	}
+	isGo := false
+	_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && entry.Name() == "@v" {
+			isGo = true
+			return errLayoutFound
+		}
+		if filepath.Ext(path) == ".pom" {
+			return errLayoutFound
+		}
+		return nil
+	})
+	return isGo
+}
@@ -0,0 +1,156 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+func TestParseGoMod(t *testing.T) {
+	file, err := parseGoMod([]byte(`module example.com/app // the main module
+
+go 1.18
+
+require (
+	example.com/a v1.2.0
+	"example.com/b" v0.0.0-20220101120000-abcdef123456 // indirect
+)
+
+require example.com/c v2.0.0+incompatible
+
+exclude example.com/a v1.2.0
+
+retract (
+	v0.9.0 // published by accident
+	[v0.1.0, v0.3.0]
+)
+
+replace (
+	example.com/b => example.com/fork v1.0.0
+	example.com/c v2.0.0+incompatible => ../c
+)
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Module != "example.com/app" || len(file.Require) != 3 || len(file.Exclude) != 1 || len(file.Replace) != 2 {
+		t.Fatalf("Expected the module, 3 requirements, 1 exclude and 2 replacements, got %+v", file)
+	}
+	if !file.retracts("v0.9.0") || !file.retracts("v0.2.5") || file.retracts("v0.3.1") {
+		t.Errorf("Expected v0.9.0 and the versions from v0.1.0 to v0.3.0 to be retracted, got %+v", file.Retract)
+	}
+	if file.Require[1] != (goModule{"example.com/b", "v0.0.0-20220101120000-abcdef123456"}) {
+		t.Errorf("Expected the quoted path to be unquoted, got %+v", file.Require[1])
+	}
+	want := goReplace{Old: goModule{"example.com/c", "v2.0.0+incompatible"}, New: goModule{Path: "../c"}}
+	if file.Replace[1] != want {
+		t.Errorf("Expected %+v, got %+v", want, file.Replace[1])
+	}
+
+	if _, err := parseGoMod([]byte("require example.com/a")); err == nil {
+		t.Error("Expected an error for a requirement without a version")
+	}
+}
+
+func TestLoadGoModuleCache(t *testing.T) {
+	root := t.TempDir()
+	download := filepath.Join(root, "cache", "download")
+	files := map[string]string{
+		"example.com/app/@v/v1.0.0.mod": `module example.com/app
+require (
+	example.com/a v1.1.0
+	example.com/b v0.0.0-20220101120000-abcdef123456
+	example.com/c v2.0.0+incompatible
+	example.com/local v1.0.0
+)
+exclude example.com/a v1.1.0
+replace example.com/b v0.0.0-20220101120000-abcdef123456 => github.com/BurntSushi/toml v1.2.0
+replace example.com/local => ../local
+`,
+		"example.com/app/@v/v1.0.0.info":             `{"Version":"v1.0.0","Time":"2022-03-01T10:00:00Z"}`,
+		"example.com/a/@v/v1.1.0.mod":                "module example.com/a\n",
+		"example.com/a/@v/v1.2.0.mod":                "module example.com/a\nrequire example.com/a v1.0.0\nretract v1.1.0\n",
+		"example.com/a/@v/v1.2.0.info":               `{"Version":"v1.2.0","Time":"2021-05-01T00:00:00Z"}`,
+		"example.com/c/@v/v2.0.0+incompatible.mod":   "module example.com/c\n",
+		"github.com/!burnt!sushi/toml/@v/v1.2.0.mod": "module github.com/BurntSushi/toml\n",
+		"example.com/broken/@v/v1.0.0.mod":           "require (\nexample.com/a\n)\n",
+		"example.com/a/@v/list":                      "v1.1.0\nv1.2.0\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(download, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	packages, skipped, err := LoadGoModuleCache(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*packages) != 4 {
+		t.Fatalf("Expected a, app, c and toml, got %+v", *packages)
+	}
+	if _, ok := skipped["example.com/broken@v1.0.0"]; !ok || len(skipped) != 1 {
+		t.Errorf("Expected broken to be skipped, got %v", skipped)
+	}
+	byName := make(map[string]g.PackageInfo)
+	for _, packageInfo := range *packages {
+		byName[packageInfo.Name] = packageInfo
+	}
+
+	app := byName["example.com/app"].Versions["v1.0.0"]
+	if app.Timestamp != "2022-03-01T10:00:00Z" {
+		t.Errorf("Expected the time of the .info file, got %s", app.Timestamp)
+	}
+	want := map[string]string{
+		"example.com/a":     "v1.1.0",
+		"example.com/b":     "v0.0.0-20220101120000-abcdef123456",
+		"example.com/c":     "v2.0.0+incompatible",
+		"example.com/local": "v1.0.0",
+	}
+	if len(app.Dependencies) != len(want) {
+		t.Errorf("Expected %v, got %v", want, app.Dependencies)
+	}
+	for name, version := range want {
+		if app.Dependencies[name] != version {
+			t.Errorf("Expected app to require %s %s, got %v", name, version, app.Dependencies)
+		}
+	}
+	wantReplace := map[string]string{
+		"example.com/b@v0.0.0-20220101120000-abcdef123456": "github.com/BurntSushi/toml@v1.2.0",
+		"example.com/local": "",
+	}
+	if !reflect.DeepEqual(app.Replace, wantReplace) || !reflect.DeepEqual(app.Exclude, []string{"example.com/a@v1.1.0"}) {
+		t.Errorf("Expected the directives to be kept on the version, got %+v", app.ModuleDirectives)
+	}
+	if dependencies := byName["example.com/a"].Versions["v1.2.0"].Dependencies; len(dependencies) != 0 {
+		t.Errorf("Expected the requirement of a on itself to be left out, got %v", dependencies)
+	}
+	if !byName["example.com/a"].Versions["v1.1.0"].Yanked || byName["example.com/a"].Versions["v1.2.0"].Yanked {
+		t.Error("Expected v1.1.0 of a to be retracted by the go.mod of v1.2.0")
+	}
+	if byName["example.com/a"].Versions["v1.1.0"].Timestamp == "" {
+		t.Error("Expected the modification time when there is no .info file")
+	}
+
+	t.Run("Builds a graph with edges to the required versions", func(t *testing.T) {
+		if !isGoModuleCache(root) || !isGoModuleCache(download) {
+			t.Error("Expected the module cache and its download directory to be recognized")
+		}
+		packages, _, err := LoadPackages(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		graph, _, stringIDToNodeInfo, idToNodeInfo, nameToVersions := g.CreateGraphFromPackages(packages, g.NPM)
+		buildList := g.MinimalVersionSelection(graph, idToNodeInfo, stringIDToNodeInfo, packages, nameToVersions, g.Go, stringIDToNodeInfo["example.com/app@v1.0.0"])
+		if len(buildList) != 3 || buildList[0].Version != "v1.2.0" {
+			t.Errorf("Expected a@v1.2.0, c and toml in the build list, got %v", buildList)
+		}
+	})
+}
@@ -1 +1,82 @@
+// Package ingest contains readers that turn external files into data that can be queried on the graph.
 package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+// Component is a single package version that an application uses, as listed in an SBOM or a lockfile.
+type Component struct {
+	Name    string
+	Version string
+	Purl    string
+}
+
+// ParseManifest reads an SBOM or a lockfile and returns the components it lists. Lockfiles are recognized by their
+// file name, everything else is expected to be a CycloneDX or SPDX SBOM in JSON format.
+func ParseManifest(path string) ([]Component, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filepath.Base(path) {
+	case "package-lock.json", "npm-shrinkwrap.json":
+		return parsePackageLock(data)
+	case "yarn.lock":
+		return parseYarnLock(data)
+	case "pnpm-lock.yaml":
+		return parsePnpmLock(data)
+	case "poetry.lock", "Cargo.lock":
+		return parseTOMLLock(data)
+	case "go.sum":
+		return parseGoSum(data)
+	}
+
+	// Peek at the document to find out which SBOM standard it follows
+	var header struct {
+		BomFormat   string `json:"bomFormat"`
+		SpdxVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("%s is not a known lockfile or a JSON SBOM: %w", path, err)
+	}
+	switch {
+	case header.BomFormat == "CycloneDX":
+		return parseCycloneDX(data)
+	case header.SpdxVersion != "":
+		return parseSPDX(data)
+	}
+	return nil, fmt.Errorf("%s is neither a CycloneDX nor an SPDX document", path)
+}
+
+// ResolveComponents maps the components onto nodes of the graph. It returns the string ids of the components that
+// exist in the graph and the components that could not be found.
+func ResolveComponents(components []Component, stringIDToNodeInfo map[string]g.NodeInfo) ([]string, []Component) {
+	found := make([]string, 0, len(components))
+	var missing []Component
+	for _, component := range components {
+		stringID := g.StringID(component.Name, component.Version)
+		if _, ok := stringIDToNodeInfo[stringID]; ok {
+			found = append(found, stringID)
+		} else {
+			missing = append(missing, component)
+		}
+	}
+	return found, missing
+}
+
+// nameFromPurl derives the package name the graph uses from a package URL. Namespaces are joined to the name the
+// way each ecosystem writes them (npm scopes with a slash, Maven groups with a colon).
+func nameFromPurl(purl string) (string, bool) {
+	key, err := g.ParsePurl(purl)
+	if err != nil {
+		return "", false
+	}
+	return key.PackageName(), true
+}
@@ -0,0 +1,117 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+func writeTestFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		fileName string
+		content  string
+		expected []Component
+	}{
+		{
+			fileName: "bom.json",
+			content: `{"bomFormat": "CycloneDX", "components": [
+				{"name": "core", "group": "@babel", "version": "7.0.0", "purl": "pkg:npm/%40babel/core@7.0.0"},
+				{"name": "parser", "group": "@babel", "version": "7.1.0"},
+				{"name": "@types/node", "group": "@types", "version": "18.0.0"},
+				{"name": "guava", "group": "com.google.guava", "version": "31.1", "components": [
+					{"name": "A", "version": "1.0.0"}
+				]}
+			]}`,
+			expected: []Component{
+				{Name: "@babel/core", Version: "7.0.0", Purl: "pkg:npm/%40babel/core@7.0.0"},
+				{Name: "@babel/parser", Version: "7.1.0"},
+				{Name: "@types/node", Version: "18.0.0"},
+				{Name: "com.google.guava:guava", Version: "31.1"},
+				{Name: "A", Version: "1.0.0"},
+			},
+		},
+		{
+			fileName: "sbom.spdx.json",
+			content: `{"spdxVersion": "SPDX-2.3", "packages": [
+				{"name": "requests", "versionInfo": "2.28.1", "externalRefs": [
+					{"referenceType": "purl", "referenceLocator": "pkg:pypi/requests@2.28.1"}
+				]}
+			]}`,
+			expected: []Component{{Name: "requests", Version: "2.28.1", Purl: "pkg:pypi/requests@2.28.1"}},
+		},
+		{
+			fileName: "package-lock.json",
+			content: `{"lockfileVersion": 2, "packages": {
+				"": {"name": "app", "version": "0.1.0"},
+				"node_modules/A": {"version": "1.0.0"},
+				"node_modules/B/node_modules/A": {"version": "0.9.0"},
+				"node_modules/local": {"link": true}
+			}}`,
+			expected: []Component{{Name: "A", Version: "0.9.0"}, {Name: "A", Version: "1.0.0"}},
+		},
+		{
+			fileName: "package-lock.json",
+			content: `{"lockfileVersion": 1, "dependencies": {
+				"B": {"version": "1.0.0", "dependencies": {"A": {"version": "0.9.0"}}}
+			}}`,
+			expected: []Component{{Name: "A", Version: "0.9.0"}, {Name: "B", Version: "1.0.0"}},
+		},
+		{
+			fileName: "yarn.lock",
+			content:  "# yarn lockfile v1\n\n\"@scope/a@^1.0.0\", \"@scope/a@^1.1.0\":\n  version \"1.2.0\"\n  resolved \"x\"\n\nb@*:\n  version \"2.0.0\"\n",
+			expected: []Component{{Name: "@scope/a", Version: "1.2.0"}, {Name: "b", Version: "2.0.0"}},
+		},
+		{
+			fileName: "pnpm-lock.yaml",
+			content:  "lockfileVersion: '6.0'\npackages:\n  /a@1.0.0:\n    dev: false\n  /@scope/b@2.0.0(a@1.0.0):\n    dev: true\n",
+			expected: []Component{{Name: "@scope/b", Version: "2.0.0"}, {Name: "a", Version: "1.0.0"}},
+		},
+		{
+			fileName: "Cargo.lock",
+			content:  "version = 3\n\n[[package]]\nname = \"serde\"\nversion = \"1.0.147\"\n",
+			expected: []Component{{Name: "serde", Version: "1.0.147"}},
+		},
+		{
+			fileName: "go.sum",
+			content:  "golang.org/x/text v0.3.7 h1:abc=\ngolang.org/x/text v0.3.7/go.mod h1:def=\n",
+			expected: []Component{{Name: "golang.org/x/text", Version: "v0.3.7"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.fileName, func(t *testing.T) {
+			components, err := ParseManifest(writeTestFile(t, test.fileName, test.content))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(components, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, components)
+			}
+		})
+	}
+}
+
+func TestResolveComponents(t *testing.T) {
+	stringIDToNodeInfo := map[string]g.NodeInfo{
+		g.StringID("A", "1.0.0"): *g.NewNodeInfo(0, g.NPM, "A", "1.0.0", "2021-04-01T20:15:37"),
+	}
+	found, missing := ResolveComponents([]Component{{Name: "A", Version: "1.0.0"}, {Name: "B", Version: "1.0.0"}}, stringIDToNodeInfo)
+	if len(found) != 1 || found[0] != g.StringID("A", "1.0.0") {
+		t.Errorf("Expected to find A-1.0.0, got %v", found)
+	}
+	if len(missing) != 1 || missing[0].Name != "B" {
+		t.Errorf("Expected B-1.0.0 to be missing, got %v", missing)
+	}
+}
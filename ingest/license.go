@@ -0,0 +1,23 @@
+package ingest
+
+import (
+	"fmt"
+	"os"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadLicensePolicy reads a license policy from a YAML or JSON file. The file is applied on top of the default
+// policy, so it only has to list the categories and incompatibilities it changes.
+func LoadLicensePolicy(path string) (g.LicensePolicy, error) {
+	policy := g.DefaultLicensePolicy()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return policy, err
+	}
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return policy, fmt.Errorf("%s is not a valid license policy: %w", path, err)
+	}
+	return policy, nil
+}
@@ -0,0 +1,27 @@
+package ingest
+
+import (
+	"testing"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+func TestLoadLicensePolicy(t *testing.T) {
+	path := writeTestFile(t, "policy.yaml", `
+categories:
+  LicenseRef-Internal: permissive
+incompatible:
+  permissive: [weak-copyleft, strong-copyleft, network-copyleft]
+denied: [WTFPL]
+`)
+	policy, err := LoadLicensePolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Categories["LicenseRef-Internal"] != g.Permissive || len(policy.Incompatible[g.Permissive]) != 3 {
+		t.Errorf("Expected the file to change the policy, got %+v", policy)
+	}
+	if len(policy.Incompatible[g.StrongCopyleft]) != 1 || len(policy.Denied) != 1 {
+		t.Errorf("Expected the rest of the default policy to be kept, got %+v", policy)
+	}
+}
@@ -0,0 +1,207 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+type packageLockDependency struct {
+	Version      string                           `json:"version"`
+	Dependencies map[string]packageLockDependency `json:"dependencies"`
+}
+
+type packageLockPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Link    bool   `json:"link"`
+}
+
+type packageLock struct {
+	// Lockfile version 2 and 3 list every installed package by its path in node_modules
+	Packages map[string]packageLockPackage `json:"packages"`
+	// Lockfile version 1 nests the dependencies the same way node_modules does
+	Dependencies map[string]packageLockDependency `json:"dependencies"`
+}
+
+// parsePackageLock reads the package-lock.json format of npm. Both the flat "packages" layout of lockfile version 2
+// and 3 and the nested "dependencies" layout of version 1 are supported.
+func parsePackageLock(data []byte) ([]Component, error) {
+	var lock packageLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	var result []Component
+	if len(lock.Packages) > 0 {
+		for path, p := range lock.Packages {
+			// The empty path is the project itself and links point to workspace folders
+			if path == "" || p.Link {
+				continue
+			}
+			name := p.Name
+			if name == "" {
+				name = path[strings.LastIndex(path, "node_modules/")+len("node_modules/"):]
+			}
+			result = append(result, Component{Name: name, Version: p.Version})
+		}
+		return dedupeComponents(result), nil
+	}
+
+	var collect func(dependencies map[string]packageLockDependency)
+	collect = func(dependencies map[string]packageLockDependency) {
+		for name, dependency := range dependencies {
+			result = append(result, Component{Name: name, Version: dependency.Version})
+			collect(dependency.Dependencies)
+		}
+	}
+	collect(lock.Dependencies)
+	return dedupeComponents(result), nil
+}
+
+// parseYarnLock reads yarn.lock files. The classic format uses `version "1.0.0"` while Yarn 2 and later write
+// `version: 1.0.0`, so both are accepted.
+func parseYarnLock(data []byte) ([]Component, error) {
+	var result []Component
+	name := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			// An entry header lists every requested range, e.g. "a@^1.0.0", "a@^1.1.0":
+			if trimmed == "__metadata:" {
+				name = ""
+				continue
+			}
+			spec := strings.TrimSuffix(trimmed, ":")
+			spec = strings.Trim(strings.TrimSpace(strings.Split(spec, ",")[0]), `"`)
+			name = nameFromSpec(spec)
+			continue
+		}
+		if name == "" || !strings.HasPrefix(trimmed, "version") {
+			continue
+		}
+		version := strings.TrimSpace(strings.TrimPrefix(trimmed, "version"))
+		version = strings.Trim(strings.TrimPrefix(version, ":"), ` "`)
+		result = append(result, Component{Name: name, Version: version})
+		name = ""
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return dedupeComponents(result), nil
+}
+
+// nameFromSpec strips the requested range from a yarn or pnpm specifier such as @scope/name@^1.0.0.
+func nameFromSpec(spec string) string {
+	if i := strings.LastIndex(spec, "@"); i > 0 {
+		return spec[:i]
+	}
+	return spec
+}
+
+// parsePnpmLock reads pnpm-lock.yaml files. The package keys changed between lockfile versions: version 5 uses
+// /name/1.0.0_peers, version 6 uses /name@1.0.0(peers) and version 9 drops the leading slash.
+func parsePnpmLock(data []byte) ([]Component, error) {
+	var lock struct {
+		Packages map[string]struct {
+			Name    string `yaml:"name"`
+			Version string `yaml:"version"`
+		} `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	result := make([]Component, 0, len(lock.Packages))
+	for key, p := range lock.Packages {
+		key = strings.TrimPrefix(key, "/")
+		if i := strings.Index(key, "("); i >= 0 {
+			key = key[:i]
+		}
+		var name, version string
+		if i := strings.LastIndex(key, "@"); i > 0 {
+			name, version = key[:i], key[i+1:]
+		} else if i := strings.LastIndex(key, "/"); i > 0 {
+			name, version = key[:i], key[i+1:]
+			if j := strings.Index(version, "_"); j >= 0 {
+				version = version[:j]
+			}
+		}
+		if p.Name != "" {
+			name = p.Name
+		}
+		if p.Version != "" {
+			version = p.Version
+		}
+		if name == "" || version == "" {
+			continue
+		}
+		result = append(result, Component{Name: name, Version: version})
+	}
+	return dedupeComponents(result), nil
+}
+
+// parseTOMLLock reads poetry.lock and Cargo.lock files, which both list their packages as [[package]] tables.
+func parseTOMLLock(data []byte) ([]Component, error) {
+	var lock struct {
+		Package []struct {
+			Name    string `toml:"name"`
+			Version string `toml:"version"`
+		} `toml:"package"`
+	}
+	if err := toml.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+	result := make([]Component, 0, len(lock.Package))
+	for _, p := range lock.Package {
+		result = append(result, Component{Name: p.Name, Version: p.Version})
+	}
+	return dedupeComponents(result), nil
+}
+
+// parseGoSum reads go.sum files. Every module version has a line for its go.mod file and usually one for its
+// contents, so the /go.mod suffix is stripped and the duplicates are removed.
+func parseGoSum(data []byte) ([]Component, error) {
+	var result []Component
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		result = append(result, Component{Name: fields[0], Version: strings.TrimSuffix(fields[1], "/go.mod")})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return dedupeComponents(result), nil
+}
+
+// dedupeComponents removes repeated components and sorts the rest so the output of the parsers is deterministic.
+func dedupeComponents(components []Component) []Component {
+	seen := make(map[Component]bool, len(components))
+	result := make([]Component, 0, len(components))
+	for _, component := range components {
+		if !seen[component] {
+			seen[component] = true
+			result = append(result, component)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].Version < result[j].Version
+	})
+	return result
+}
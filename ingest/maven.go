@@ -0,0 +1,97 @@
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+// mavenPath is the directory of an artifact relative to the root of a Maven repository
+func mavenPath(groupID, artifactID string) string {
+	return strings.ReplaceAll(groupID, ".", "/") + "/" + artifactID
+}
+
+// mavenPOMPath is the POM of a version relative to the root of a Maven repository
+func mavenPOMPath(groupID, artifactID, version string) string {
+	return mavenPath(groupID, artifactID) + "/" + version + "/" + artifactID + "-" + version + ".pom"
+}
+
+// LoadMavenRepository reads the POMs in a local Maven repository, like ~/.m2/repository. Every POM is turned into its
+// effective POM, with its parents, properties, imported BOMs and dependencyManagement resolved from the same
+// repository. The modification time of the POM file is used as the publish time. Versions whose parents or BOMs are
+// not in the repository are left out, the second result maps their string ids to the reason.
+func LoadMavenRepository(root string) (*[]g.PackageInfo, map[string]string, error) {
+	resolver := newPOMResolver(func(groupID, artifactID, version string) ([]byte, error) {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(mavenPOMPath(groupID, artifactID, version))))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s:%s:%s is %w in the repository", groupID, artifactID, version, errNotFound)
+		}
+		return data, err
+	})
+
+	packages := make(map[string]*g.PackageInfo)
+	skipped := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(path) != ".pom" {
+			return err
+		}
+		groupID, artifactID, version, ok := mavenCoordinates(root, path)
+		if !ok {
+			return nil
+		}
+		name := groupID + ":" + artifactID
+		project, err := resolver.resolve(groupID, artifactID, version)
+		if err != nil {
+			skipped[g.StringID(name, version)] = err.Error()
+			return nil
+		}
+		var timestamp string
+		if info, err := entry.Info(); err == nil {
+			timestamp = info.ModTime().UTC().Format(time.RFC3339)
+		}
+		if packages[name] == nil {
+			packages[name] = &g.PackageInfo{Name: name, Versions: make(map[string]g.VersionInfo)}
+		}
+		packages[name].Versions[version] = project.versionInfo(timestamp)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := make([]g.PackageInfo, 0, len(packages))
+	for _, packageInfo := range packages {
+		result = append(result, *packageInfo)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return &result, skipped, nil
+}
+
+// mavenCoordinates derives the coordinates of a POM from its path in the repository layout,
+// group/as/directories/artifact/version/artifact-version.pom. Other files, like the POMs of timestamped snapshots,
+// don't match the layout.
+func mavenCoordinates(root, path string) (string, string, string, bool) {
+	relative, err := filepath.Rel(root, path)
+	if err != nil {
+		return "", "", "", false
+	}
+	parts := strings.Split(filepath.ToSlash(relative), "/")
+	if len(parts) < 4 {
+		return "", "", "", false
+	}
+	version := parts[len(parts)-2]
+	artifactID := parts[len(parts)-3]
+	if parts[len(parts)-1] != artifactID+"-"+version+".pom" {
+		return "", "", "", false
+	}
+	return strings.Join(parts[:len(parts)-3], "."), artifactID, version, true
+}
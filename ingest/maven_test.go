@@ -0,0 +1,99 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+func TestLoadMavenRepository(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"org/example/parent/1/parent-1.pom": `<project><groupId>org.example</groupId><artifactId>parent</artifactId>
+			<version>1</version><packaging>pom</packaging>
+			<properties><guava.version>31.0</guava.version><slf4j.version>${slf4j.major}.36</slf4j.version><slf4j.major>1.7</slf4j.major></properties>
+			<dependencyManagement><dependencies>
+				<dependency><groupId>com.google.guava</groupId><artifactId>guava</artifactId><version>${guava.version}</version></dependency>
+				<dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>4.13</version><scope>test</scope></dependency>
+				<dependency><groupId>org.example</groupId><artifactId>bom</artifactId><version>2</version><type>pom</type><scope>import</scope></dependency>
+			</dependencies></dependencyManagement>
+			<dependencies><dependency><groupId>org.slf4j</groupId><artifactId>slf4j-api</artifactId><version>${slf4j.version}</version></dependency></dependencies>
+			</project>`,
+		"org/example/bom/2/bom-2.pom": `<project><groupId>org.example</groupId><artifactId>bom</artifactId><version>2</version>
+			<properties><jackson.version>2.13.0</jackson.version></properties>
+			<dependencyManagement><dependencies>
+				<dependency><groupId>com.fasterxml.jackson.core</groupId><artifactId>jackson-databind</artifactId><version>${jackson.version}</version></dependency>
+				<dependency><groupId>com.google.guava</groupId><artifactId>guava</artifactId><version>1.0</version></dependency>
+			</dependencies></dependencyManagement></project>`,
+		"org/example/app/1.0/app-1.0.pom": `<project><parent><groupId>org.example</groupId><artifactId>parent</artifactId><version>1</version></parent>
+			<artifactId>app</artifactId><properties><guava.version>32.0</guava.version></properties><dependencies>
+				<dependency><groupId>com.google.guava</groupId><artifactId>guava</artifactId></dependency>
+				<dependency><groupId>junit</groupId><artifactId>junit</artifactId></dependency>
+				<dependency><groupId>com.fasterxml.jackson.core</groupId><artifactId>jackson-databind</artifactId><optional>true</optional></dependency>
+				<dependency><groupId>${project.groupId}</groupId><artifactId>core</artifactId><version>[1.0,2.0)</version><scope>provided</scope></dependency>
+				<dependency><groupId>org.unknown</groupId><artifactId>unmanaged</artifactId></dependency>
+			</dependencies></project>`,
+		"org/example/orphan/1.0/orphan-1.0.pom": `<project><parent><groupId>org.example</groupId><artifactId>missing</artifactId><version>1</version></parent>
+			<artifactId>orphan</artifactId></project>`,
+		"org/example/app/1.1-SNAPSHOT/app-1.1-20220101.120000-1.pom": `<project/>`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	packages, skipped, err := LoadMavenRepository(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*packages) != 3 || (*packages)[0].Name != "org.example:app" {
+		t.Fatalf("Expected app, bom and parent, got %+v", *packages)
+	}
+	if _, ok := skipped["org.example:orphan@1.0"]; !ok || len(skipped) != 1 {
+		t.Errorf("Expected orphan to be skipped because its parent is missing, got %v", skipped)
+	}
+
+	app := (*packages)[0].Versions["1.0"]
+	want := map[string]string{
+		// The property of the child overrides the one of the parent
+		"com.google.guava:guava": "32.0",
+		"junit:junit":            "4.13",
+		// Imported from the BOM of the parent
+		"com.fasterxml.jackson.core:jackson-databind": "2.13.0",
+		"org.example:core":    "[1.0,2.0)",
+		"org.slf4j:slf4j-api": "1.7.36",
+	}
+	if len(app.Dependencies) != len(want) {
+		t.Errorf("Expected %d dependencies, got %v", len(want), app.Dependencies)
+	}
+	for name, version := range want {
+		if app.Dependencies[name] != version {
+			t.Errorf("Expected %s %s, got %q", name, version, app.Dependencies[name])
+		}
+	}
+	for name, kind := range map[string]string{
+		"junit:junit": g.KindDev,
+		"com.fasterxml.jackson.core:jackson-databind": g.KindOptional,
+		"org.example:core":       g.KindBuild,
+		"com.google.guava:guava": g.KindRuntime,
+	} {
+		if app.DependencyKind(name) != kind {
+			t.Errorf("Expected %s to be a %s dependency, got %s", name, kind, app.DependencyKind(name))
+		}
+	}
+	if app.Timestamp == "" {
+		t.Error("Expected the modification time of the POM as timestamp")
+	}
+
+	loaded, loadedSkipped, err := LoadPackages(root)
+	if err != nil || len(*loaded) != 3 || len(loadedSkipped) != 1 {
+		t.Errorf("Expected LoadPackages to read the repository and return the skipped version, got %v and %v", err, loadedSkipped)
+	}
+}
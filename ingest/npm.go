@@ -0,0 +1,130 @@
+package ingest
+
+import (
+	"encoding/json"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+// npmVersion is a version in an npm registry document
+type npmVersion struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	// Older versions write the license as {"type": "MIT"} and the author as {"name": "..."}
+	License json.RawMessage `json:"license"`
+	Author  json.RawMessage `json:"author"`
+	// Deprecated is the deprecation message, a few old versions write true instead
+	Deprecated json.RawMessage `json:"deprecated"`
+}
+
+// npmDocument is the document the npm registry stores for a package, with every version and the moment it was
+// published. When the whole package was unpublished the versions are gone and the time holds an unpublished object
+// with the moment and the versions it had. A single version that was unpublished is only gone from the versions, the
+// time still holds the moment it was published.
+type npmDocument struct {
+	Name     string                     `json:"name"`
+	Versions map[string]npmVersion      `json:"versions"`
+	Time     map[string]json.RawMessage `json:"time"`
+}
+
+type npmUnpublished struct {
+	Time     string   `json:"time"`
+	Versions []string `json:"versions"`
+}
+
+// published returns the moment a version was published
+func (d npmDocument) published(version string) string {
+	var timestamp string
+	_ = json.Unmarshal(d.Time[version], &timestamp)
+	return timestamp
+}
+
+// convertNpmDocument turns a registry document into a package. Dependencies that are listed under several kinds get
+// the kind npm installs them as: optional dependencies override runtime dependencies, which override peer and
+// development dependencies. The versions of an unpublished package are kept without dependencies, so the versions that
+// depended on them can't use them after they were unpublished. So are the versions that were unpublished on their own,
+// the registry doesn't record when that happened, the last modification of the document is used instead.
+func convertNpmDocument(document npmDocument) g.PackageInfo {
+	result := g.PackageInfo{Name: document.Name, Versions: make(map[string]g.VersionInfo, len(document.Versions))}
+	var unpublished npmUnpublished
+	if json.Unmarshal(document.Time["unpublished"], &unpublished) == nil {
+		for _, version := range unpublished.Versions {
+			versionInfo := g.VersionInfo{Timestamp: document.published(version), Dependencies: make(map[string]string)}
+			versionInfo.Unpublished = unpublished.Time
+			result.Versions[version] = versionInfo
+		}
+	}
+	modified := unpublished.Time
+	_ = json.Unmarshal(document.Time["modified"], &modified)
+	for version := range document.Time {
+		if _, ok := document.Versions[version]; ok || version == "created" || version == "modified" || version == "unpublished" {
+			continue
+		}
+		if _, ok := result.Versions[version]; !ok {
+			versionInfo := g.VersionInfo{Timestamp: document.published(version), Dependencies: make(map[string]string)}
+			versionInfo.Unpublished = modified
+			result.Versions[version] = versionInfo
+		}
+	}
+	for version, npm := range document.Versions {
+		versionInfo := g.VersionInfo{
+			Timestamp:    document.published(version),
+			Dependencies: make(map[string]string),
+			License:      stringOrField(npm.License, "type"),
+			Author:       stringOrField(npm.Author, "name"),
+		}
+		versionInfo.Deprecated = deprecationMessage(npm.Deprecated)
+		for _, group := range []struct {
+			dependencies map[string]string
+			kind         string
+		}{
+			{npm.DevDependencies, g.KindDev},
+			{npm.PeerDependencies, g.KindPeer},
+			{npm.Dependencies, g.KindRuntime},
+			{npm.OptionalDependencies, g.KindOptional},
+		} {
+			for name, constraint := range group.dependencies {
+				versionInfo.Dependencies[name] = constraint
+				if group.kind == g.KindRuntime {
+					delete(versionInfo.DependencyKinds, name)
+					continue
+				}
+				if versionInfo.DependencyKinds == nil {
+					versionInfo.DependencyKinds = make(map[string]string)
+				}
+				versionInfo.DependencyKinds[name] = group.kind
+			}
+		}
+		result.Versions[version] = versionInfo
+	}
+	return result
+}
+
+// deprecationMessage returns the deprecation message of a version. An empty message or false means the version is not
+// deprecated, true that it is deprecated without a message.
+func deprecationMessage(raw json.RawMessage) string {
+	var message string
+	if json.Unmarshal(raw, &message) == nil {
+		return message
+	}
+	var deprecated bool
+	if json.Unmarshal(raw, &deprecated) == nil && deprecated {
+		return "deprecated"
+	}
+	return ""
+}
+
+// stringOrField returns a JSON string, or the field of a JSON object with the given name
+func stringOrField(raw json.RawMessage, field string) string {
+	var value string
+	if json.Unmarshal(raw, &value) == nil {
+		return value
+	}
+	var object map[string]json.RawMessage
+	if json.Unmarshal(raw, &object) == nil && object[field] != nil {
+		_ = json.Unmarshal(object[field], &value)
+	}
+	return value
+}
@@ -0,0 +1,53 @@
+package ingest
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConvertNpmDocument(t *testing.T) {
+	var document npmDocument
+	err := json.Unmarshal([]byte(`{"name": "left-pad", "versions": {
+		"1.0.0": {"dependencies": {"a": "^1.0.0"}, "deprecated": "use String.prototype.padStart()"},
+		"1.1.0": {"deprecated": ""},
+		"1.2.0": {"deprecated": true}
+	}, "time": {"created": "2014-03-01T00:00:00Z", "1.0.0": "2014-03-01T00:00:00Z", "1.1.0": "2015-01-01T00:00:00Z", "1.2.0": "2016-01-01T00:00:00Z"}}`), &document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packageInfo := convertNpmDocument(document)
+	if packageInfo.Versions["1.0.0"].Deprecated != "use String.prototype.padStart()" || packageInfo.Versions["1.0.0"].Timestamp != "2014-03-01T00:00:00Z" {
+		t.Errorf("Expected 1.0.0 to be deprecated with its message, got %+v", packageInfo.Versions["1.0.0"])
+	}
+	if packageInfo.Versions["1.1.0"].Deprecated != "" || packageInfo.Versions["1.2.0"].Deprecated != "deprecated" {
+		t.Errorf("Expected 1.1.0 not to be deprecated and 1.2.0 to be, got %+v", packageInfo.Versions)
+	}
+
+	t.Run("Keeps the versions of an unpublished package", func(t *testing.T) {
+		var document npmDocument
+		err := json.Unmarshal([]byte(`{"name": "gone", "time": {"1.0.0": "2020-01-01T00:00:00Z",
+			"unpublished": {"time": "2021-01-01T00:00:00Z", "versions": ["1.0.0"]}}}`), &document)
+		if err != nil {
+			t.Fatal(err)
+		}
+		version, ok := convertNpmDocument(document).Versions["1.0.0"]
+		if !ok || version.Timestamp != "2020-01-01T00:00:00Z" || version.Unpublished != "2021-01-01T00:00:00Z" {
+			t.Errorf("Expected 1.0.0 to be unpublished in 2021, got %+v", version)
+		}
+	})
+	t.Run("Keeps the versions that were unpublished on their own", func(t *testing.T) {
+		var document npmDocument
+		err := json.Unmarshal([]byte(`{"name": "partly-gone", "versions": {"2.0.0": {}}, "time": {"created": "2020-01-01T00:00:00Z",
+			"modified": "2022-01-01T00:00:00Z", "1.0.0": "2020-01-01T00:00:00Z", "2.0.0": "2021-01-01T00:00:00Z"}}`), &document)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packageInfo := convertNpmDocument(document)
+		if len(packageInfo.Versions) != 2 || packageInfo.Versions["2.0.0"].Unpublished != "" {
+			t.Fatalf("Expected 1.0.0 and 2.0.0, only 1.0.0 unpublished, got %+v", packageInfo.Versions)
+		}
+		if version := packageInfo.Versions["1.0.0"]; version.Timestamp != "2020-01-01T00:00:00Z" || version.Unpublished != "2022-01-01T00:00:00Z" {
+			t.Errorf("Expected 1.0.0 to be unpublished at the last modification, got %+v", version)
+		}
+	})
+}
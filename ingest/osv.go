@@ -0,0 +1,143 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+type osvEvent struct {
+	Introduced   string `json:"introduced"`
+	Fixed        string `json:"fixed"`
+	LastAffected string `json:"last_affected"`
+}
+
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+		Purl      string `json:"purl"`
+	} `json:"package"`
+	Ranges []struct {
+		Type   string     `json:"type"`
+		Events []osvEvent `json:"events"`
+	} `json:"ranges"`
+	Versions []string `json:"versions"`
+}
+
+type osvAdvisory struct {
+	ID        string        `json:"id"`
+	Summary   string        `json:"summary"`
+	Aliases   []string      `json:"aliases"`
+	Published time.Time     `json:"published"`
+	Withdrawn *time.Time    `json:"withdrawn"`
+	Affected  []osvAffected `json:"affected"`
+}
+
+// LoadAdvisories reads the OSV advisories in the JSON files of a directory and its subdirectories. A file holds one
+// advisory or an array of them. Only the packages of the given ecosystem are kept, advisories that don't affect any
+// of them and withdrawn advisories are left out.
+func LoadAdvisories(dir string, ecosystem g.Ecosystem) ([]g.Advisory, error) {
+	var result []g.Advisory
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.EqualFold(filepath.Ext(path), ".json") {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var advisories []osvAdvisory
+		if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+			err = json.Unmarshal(data, &advisories)
+		} else {
+			advisories = make([]osvAdvisory, 1)
+			err = json.Unmarshal(data, &advisories[0])
+		}
+		if err != nil {
+			return fmt.Errorf("%s is not an OSV advisory: %w", path, err)
+		}
+		for _, advisory := range advisories {
+			if converted, ok := convertAdvisory(advisory, ecosystem); ok {
+				result = append(result, converted)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// osvEcosystemNames are the OSV names of the ecosystems whose name differs from their package URL type
+var osvEcosystemNames = map[g.Ecosystem]string{g.Go: "Go", g.Cargo: "crates.io"}
+
+func convertAdvisory(advisory osvAdvisory, ecosystem g.Ecosystem) (g.Advisory, bool) {
+	ecosystemName := string(ecosystem)
+	if name, ok := osvEcosystemNames[ecosystem]; ok {
+		ecosystemName = name
+	}
+	result := g.Advisory{ID: advisory.ID, Summary: advisory.Summary, Aliases: advisory.Aliases, Published: advisory.Published}
+	if advisory.Withdrawn != nil {
+		return result, false
+	}
+	for _, affected := range advisory.Affected {
+		name := affected.Package.Name
+		if purlName, ok := nameFromPurl(affected.Package.Purl); ok {
+			name = purlName
+		}
+		if !strings.EqualFold(affected.Package.Ecosystem, ecosystemName) || name == "" {
+			continue
+		}
+		converted := g.AffectedPackage{Name: name, Versions: affected.Versions}
+		for _, r := range affected.Ranges {
+			// GIT ranges hold commit hashes, which can't be mapped onto versions
+			if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+				continue
+			}
+			converted.Ranges = append(converted.Ranges, rangesFromEvents(r.Events)...)
+		}
+		result.Affected = append(result.Affected, converted)
+	}
+	return result, len(result.Affected) > 0
+}
+
+// rangesFromEvents pairs every introduced event with the fixed or last_affected event that follows it
+func rangesFromEvents(events []osvEvent) []g.VersionRange {
+	var result []g.VersionRange
+	var current *g.VersionRange
+	for _, event := range events {
+		switch {
+		case event.Introduced != "":
+			if current != nil {
+				result = append(result, *current)
+			}
+			current = &g.VersionRange{Introduced: event.Introduced}
+		case current == nil:
+			continue
+		case event.Fixed != "":
+			current.Fixed = event.Fixed
+			result = append(result, *current)
+			current = nil
+		case event.LastAffected != "":
+			current.LastAffected = event.LastAffected
+			result = append(result, *current)
+			current = nil
+		}
+	}
+	if current != nil {
+		result = append(result, *current)
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+func TestLoadAdvisories(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"GHSA-1.json": `{"id": "GHSA-1", "published": "2021-03-01T00:00:00Z", "affected": [
+			{"package": {"ecosystem": "npm", "name": "lodash"}, "ranges": [
+				{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}, {"introduced": "5.0.0"}]},
+				{"type": "GIT", "events": [{"introduced": "abc123"}]}
+			]},
+			{"package": {"ecosystem": "PyPI", "name": "requests"}, "versions": ["2.0.0"]}
+		]}`,
+		filepath.Join("nested", "more.json"): `[
+			{"id": "GHSA-2", "affected": [{"package": {"ecosystem": "npm", "purl": "pkg:npm/%40babel/core"},
+				"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "7.0.0"}, {"last_affected": "7.1.0"}]}]}]},
+			{"id": "GHSA-3", "withdrawn": "2022-01-01T00:00:00Z", "affected": [{"package": {"ecosystem": "npm", "name": "A"}}]},
+			{"id": "PYSEC-1", "affected": [{"package": {"ecosystem": "PyPI", "name": "django"}}]}
+		]`,
+		"notes.txt": "not an advisory",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	advisories, err := LoadAdvisories(dir, g.NPM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(advisories) != 2 || advisories[0].ID != "GHSA-1" || advisories[1].ID != "GHSA-2" {
+		t.Fatalf("Expected the npm advisories GHSA-1 and GHSA-2, got %+v", advisories)
+	}
+	expected := []g.AffectedPackage{{Name: "lodash", Ranges: []g.VersionRange{
+		{Introduced: "0", Fixed: "4.17.21"},
+		{Introduced: "5.0.0"},
+	}}}
+	if !reflect.DeepEqual(advisories[0].Affected, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, advisories[0].Affected)
+	}
+	expected = []g.AffectedPackage{{Name: "@babel/core", Ranges: []g.VersionRange{{Introduced: "7.0.0", LastAffected: "7.1.0"}}}}
+	if !reflect.DeepEqual(advisories[1].Affected, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, advisories[1].Affected)
+	}
+}
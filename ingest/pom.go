@@ -0,0 +1,317 @@
+package ingest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+	Type       string `xml:"type"`
+	Optional   string `xml:"optional"`
+}
+
+// name is the name of the dependency in the graph
+func (d pomDependency) name() string {
+	return d.GroupID + ":" + d.ArtifactID
+}
+
+// isImport tells whether the dependency is a BOM whose dependencyManagement is imported
+func (d pomDependency) isImport() bool {
+	return d.Scope == "import" && d.Type == "pom"
+}
+
+// pomProperties holds the free-form elements of the properties section
+type pomProperties map[string]string
+
+func (p *pomProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*p = make(pomProperties)
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			var value string
+			if err := d.DecodeElement(&value, &token); err != nil {
+				return err
+			}
+			(*p)[token.Name.Local] = strings.TrimSpace(value)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+type pomParent struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+// pom is the part of a Maven POM the graph needs
+type pom struct {
+	GroupID              string          `xml:"groupId"`
+	ArtifactID           string          `xml:"artifactId"`
+	Version              string          `xml:"version"`
+	Packaging            string          `xml:"packaging"`
+	Parent               pomParent       `xml:"parent"`
+	Properties           pomProperties   `xml:"properties"`
+	DependencyManagement []pomDependency `xml:"dependencyManagement>dependencies>dependency"`
+	Dependencies         []pomDependency `xml:"dependencies>dependency"`
+}
+
+func parsePOM(data []byte) (*pom, error) {
+	var result pom
+	if err := xml.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	// The coordinates are inherited from the parent when they are left out
+	if result.GroupID == "" {
+		result.GroupID = result.Parent.GroupID
+	}
+	if result.Version == "" {
+		result.Version = result.Parent.Version
+	}
+	if result.Properties == nil {
+		result.Properties = make(pomProperties)
+	}
+	return &result, nil
+}
+
+var propertyPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// maxPropertyDepth limits how often properties that refer to other properties are expanded
+const maxPropertyDepth = 10
+
+// interpolate replaces the ${...} references in a value with the properties of the POM. Properties can refer to other
+// properties, references that can't be resolved are left as they are.
+func (p *pom) interpolate(value string) string {
+	for i := 0; i < maxPropertyDepth && strings.Contains(value, "${"); i++ {
+		expanded := propertyPattern.ReplaceAllStringFunc(value, func(reference string) string {
+			name := reference[2 : len(reference)-1]
+			switch name {
+			case "project.version", "pom.version", "version":
+				return p.Version
+			case "project.groupId", "pom.groupId":
+				return p.GroupID
+			case "project.artifactId", "pom.artifactId":
+				return p.ArtifactID
+			case "project.parent.version":
+				return p.Parent.Version
+			case "project.parent.groupId":
+				return p.Parent.GroupID
+			}
+			if value, ok := p.Properties[name]; ok {
+				return value
+			}
+			return reference
+		})
+		if expanded == value {
+			break
+		}
+		value = expanded
+	}
+	return value
+}
+
+func (p *pom) interpolateDependency(dependency pomDependency) pomDependency {
+	return pomDependency{
+		GroupID:    p.interpolate(dependency.GroupID),
+		ArtifactID: p.interpolate(dependency.ArtifactID),
+		Version:    p.interpolate(dependency.Version),
+		Scope:      strings.TrimSpace(p.interpolate(dependency.Scope)),
+		Type:       strings.TrimSpace(p.interpolate(dependency.Type)),
+		Optional:   strings.TrimSpace(p.interpolate(dependency.Optional)),
+	}
+}
+
+// mavenDependencyKind maps the scope of a Maven dependency onto the kinds of the graph. Test dependencies are only
+// used to develop the package, provided and system dependencies are expected to be there when it is built.
+func mavenDependencyKind(dependency pomDependency) string {
+	if dependency.Optional == "true" {
+		return g.KindOptional
+	}
+	switch dependency.Scope {
+	case "test":
+		return g.KindDev
+	case "provided", "system":
+		return g.KindBuild
+	}
+	return g.KindRuntime
+}
+
+// versionInfo converts the dependencies of an effective POM. The names of the dependencies are groupId:artifactId
+// like the Maven package names in the graph, the versions are kept as Maven writes them, so ranges like [1.0,2.0)
+// stay ranges. Dependencies whose version can't be resolved are left out.
+func (p *pom) versionInfo(timestamp string) g.VersionInfo {
+	result := g.VersionInfo{Timestamp: timestamp, Dependencies: make(map[string]string)}
+	for _, dependency := range p.Dependencies {
+		if dependency.Scope == "import" || dependency.Version == "" || strings.Contains(dependency.Version, "${") {
+			continue
+		}
+		result.Dependencies[dependency.name()] = dependency.Version
+		if kind := mavenDependencyKind(dependency); kind != g.KindRuntime {
+			if result.DependencyKinds == nil {
+				result.DependencyKinds = make(map[string]string)
+			}
+			result.DependencyKinds[dependency.name()] = kind
+		}
+	}
+	return result
+}
+
+// maxParentDepth limits the length of a chain of parent POMs, so a parent that is its own ancestor is found
+const maxParentDepth = 32
+
+// pomResolver builds effective POMs the way Maven does: the parents are merged into the POM, the properties are
+// filled in, the BOMs are imported into the dependencyManagement and the managed versions and scopes are applied to
+// the dependencies. It caches the POMs it read, so parents and BOMs that many POMs share are only read once.
+type pomResolver struct {
+	// load returns the POM with the given coordinates
+	load      func(groupID, artifactID, version string) ([]byte, error)
+	merged    map[string]*pom
+	effective map[string]*pom
+}
+
+func newPOMResolver(load func(groupID, artifactID, version string) ([]byte, error)) *pomResolver {
+	return &pomResolver{load: load, merged: make(map[string]*pom), effective: make(map[string]*pom)}
+}
+
+// resolve returns the effective POM of the given coordinates
+func (r *pomResolver) resolve(groupID, artifactID, version string) (*pom, error) {
+	return r.resolveEffective(groupID, artifactID, version, 0)
+}
+
+func (r *pomResolver) resolveEffective(groupID, artifactID, version string, depth int) (*pom, error) {
+	key := groupID + ":" + artifactID + ":" + version
+	if p, ok := r.effective[key]; ok {
+		return p, nil
+	}
+	merged, err := r.resolveMerged(groupID, artifactID, version, depth)
+	if err != nil {
+		return nil, err
+	}
+	p := *merged
+
+	// Dependencies that are declared directly take precedence over imported ones, the first BOM wins among imports
+	var management []pomDependency
+	managed := make(map[string]bool)
+	var imports []pomDependency
+	for _, dependency := range merged.DependencyManagement {
+		dependency = p.interpolateDependency(dependency)
+		if dependency.isImport() {
+			imports = append(imports, dependency)
+			continue
+		}
+		if !managed[dependency.name()] {
+			managed[dependency.name()] = true
+			management = append(management, dependency)
+		}
+	}
+	for _, bom := range imports {
+		if depth >= maxParentDepth {
+			return nil, fmt.Errorf("BOM %s:%s imports too many BOMs", bom.name(), bom.Version)
+		}
+		imported, err := r.resolveEffective(bom.GroupID, bom.ArtifactID, bom.Version, depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("BOM %s:%s: %w", bom.name(), bom.Version, err)
+		}
+		for _, dependency := range imported.DependencyManagement {
+			if !managed[dependency.name()] {
+				managed[dependency.name()] = true
+				management = append(management, dependency)
+			}
+		}
+	}
+	p.DependencyManagement = management
+
+	versions := make(map[string]pomDependency, len(management))
+	for _, dependency := range management {
+		versions[dependency.name()] = dependency
+	}
+	p.Dependencies = make([]pomDependency, 0, len(merged.Dependencies))
+	for _, dependency := range merged.Dependencies {
+		dependency = p.interpolateDependency(dependency)
+		if managedDependency, ok := versions[dependency.name()]; ok {
+			if dependency.Version == "" {
+				dependency.Version = managedDependency.Version
+			}
+			if dependency.Scope == "" {
+				dependency.Scope = managedDependency.Scope
+			}
+		}
+		p.Dependencies = append(p.Dependencies, dependency)
+	}
+	r.effective[key] = &p
+	return &p, nil
+}
+
+// resolveMerged reads a POM and merges its parents into it. Properties and dependencies of the POM override the ones
+// of its parents, nothing is interpolated yet because the properties of the child apply to what it inherits.
+func (r *pomResolver) resolveMerged(groupID, artifactID, version string, depth int) (*pom, error) {
+	key := groupID + ":" + artifactID + ":" + version
+	if p, ok := r.merged[key]; ok {
+		return p, nil
+	}
+	if depth > maxParentDepth {
+		return nil, fmt.Errorf("%s has too many parents", key)
+	}
+	data, err := r.load(groupID, artifactID, version)
+	if err != nil {
+		return nil, err
+	}
+	p, err := parsePOM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	if p.Parent.ArtifactID != "" {
+		parent, err := r.resolveMerged(p.Parent.GroupID, p.Parent.ArtifactID, p.Parent.Version, depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("parent of %s: %w", key, err)
+		}
+		properties := make(pomProperties, len(parent.Properties)+len(p.Properties))
+		for name, value := range parent.Properties {
+			properties[name] = value
+		}
+		for name, value := range p.Properties {
+			properties[name] = value
+		}
+		p.Properties = properties
+		p.DependencyManagement = mergeDependencies(parent.DependencyManagement, p.DependencyManagement)
+		p.Dependencies = mergeDependencies(parent.Dependencies, p.Dependencies)
+	}
+	r.merged[key] = p
+	return p, nil
+}
+
+// mergeDependencies adds the dependencies of a child to the ones of its parent, a child that declares the same
+// dependency replaces the one of the parent
+func mergeDependencies(parent, child []pomDependency) []pomDependency {
+	declared := make(map[string]bool, len(child))
+	for _, dependency := range child {
+		declared[dependency.name()] = true
+	}
+	result := make([]pomDependency, 0, len(parent)+len(child))
+	for _, dependency := range parent {
+		if !declared[dependency.name()] {
+			result = append(result, dependency)
+		}
+	}
+	return append(result, child...)
+}
+
+// mavenMetadata is the maven-metadata.xml file that lists the versions of an artifact
+type mavenMetadata struct {
+	GroupID    string   `xml:"groupId"`
+	ArtifactID string   `xml:"artifactId"`
+	Versions   []string `xml:"versioning>versions>version"`
+}
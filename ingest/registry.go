@@ -0,0 +1,213 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+// fetchNpm reads the registry document of an npm package. Scoped names are requested with an escaped slash, like the
+// npm client does.
+func (c *Crawler) fetchNpm(ctx context.Context, name string) (g.PackageInfo, error) {
+	data, _, err := c.get(ctx, c.options.Registry+"/"+url.PathEscape(name))
+	if err != nil {
+		return g.PackageInfo{}, err
+	}
+	var document npmDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return g.PackageInfo{}, fmt.Errorf("%s is not an npm registry document: %w", name, err)
+	}
+	if document.Name == "" {
+		document.Name = name
+	}
+	return convertNpmDocument(document), nil
+}
+
+type pypiFile struct {
+	UploadTime string `json:"upload_time_iso_8601"`
+	Yanked     bool   `json:"yanked"`
+}
+
+type pypiProject struct {
+	Info struct {
+		Name         string   `json:"name"`
+		License      string   `json:"license"`
+		Author       string   `json:"author"`
+		RequiresDist []string `json:"requires_dist"`
+	} `json:"info"`
+	Releases map[string][]pypiFile `json:"releases"`
+}
+
+// fetchPyPI reads a project from the PyPI JSON API. The project document only holds the requirements of the latest
+// release, so every release is requested on its own as well. A release is published when its first file was uploaded
+// and yanked when all of its files are yanked (PEP 592).
+func (c *Crawler) fetchPyPI(ctx context.Context, name string) (g.PackageInfo, error) {
+	data, _, err := c.get(ctx, c.options.Registry+"/pypi/"+url.PathEscape(name)+"/json")
+	if err != nil {
+		return g.PackageInfo{}, err
+	}
+	var project pypiProject
+	if err := json.Unmarshal(data, &project); err != nil {
+		return g.PackageInfo{}, fmt.Errorf("%s is not a PyPI project: %w", name, err)
+	}
+	result := g.PackageInfo{Name: normalizePyPIName(name), Versions: make(map[string]g.VersionInfo, len(project.Releases))}
+	for version, files := range project.Releases {
+		data, _, err := c.get(ctx, c.options.Registry+"/pypi/"+url.PathEscape(name)+"/"+url.PathEscape(version)+"/json")
+		if errors.Is(err, errNotFound) {
+			continue
+		}
+		if err != nil {
+			return g.PackageInfo{}, err
+		}
+		var release pypiProject
+		if err := json.Unmarshal(data, &release); err != nil {
+			return g.PackageInfo{}, fmt.Errorf("%s %s is not a PyPI release: %w", name, version, err)
+		}
+		versionInfo := g.VersionInfo{
+			Dependencies: make(map[string]string),
+			License:      release.Info.License,
+			Author:       release.Info.Author,
+		}
+		versionInfo.Yanked = len(files) > 0
+		for _, file := range files {
+			if versionInfo.Timestamp == "" || file.UploadTime < versionInfo.Timestamp {
+				versionInfo.Timestamp = file.UploadTime
+			}
+			versionInfo.Yanked = versionInfo.Yanked && file.Yanked
+		}
+		for _, requirement := range release.Info.RequiresDist {
+			dependency, constraint, extra, ok := parseRequirement(requirement)
+			if !ok {
+				continue
+			}
+			versionInfo.Dependencies[dependency] = constraint
+			if extra {
+				if versionInfo.DependencyKinds == nil {
+					versionInfo.DependencyKinds = make(map[string]string)
+				}
+				versionInfo.DependencyKinds[dependency] = g.KindOptional
+			}
+		}
+		result.Versions[version] = versionInfo
+	}
+	return result, nil
+}
+
+var pypiNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// normalizePyPIName normalizes a project name the way PyPI compares them (PEP 503)
+func normalizePyPIName(name string) string {
+	return strings.ToLower(pypiNameSeparators.ReplaceAllString(name, "-"))
+}
+
+var requirementPattern = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*\(?\s*((?:[<>=!~][^;)]*)?)\)?\s*(?:;(.*))?$`)
+
+// parseRequirement reads a requirement of the requires_dist list, for example `requests (>=2.0) ; extra == "http"`.
+// It returns the normalized name, the constraint in the syntax of the semver library and whether the dependency is
+// only installed with an extra.
+func parseRequirement(requirement string) (string, string, bool, bool) {
+	match := requirementPattern.FindStringSubmatch(requirement)
+	if match == nil {
+		return "", "", false, false
+	}
+	extra := strings.Contains(strings.ReplaceAll(match[3], " ", ""), "extra==")
+	return normalizePyPIName(match[1]), pep440Constraint(match[2]), extra, true
+}
+
+// pep440Constraint translates a PEP 440 version specifier to a constraint of the semver library. The compatible
+// release operator ~= allows everything up to the next release of the second to last part.
+func pep440Constraint(specifier string) string {
+	var parts []string
+	for _, clause := range strings.Split(specifier, ",") {
+		clause = strings.ReplaceAll(strings.TrimSpace(clause), " ", "")
+		switch {
+		case clause == "":
+			continue
+		case strings.HasPrefix(clause, "~="):
+			version := clause[2:]
+			parts = append(parts, ">="+version)
+			if upper, ok := nextRelease(version); ok {
+				parts = append(parts, "<"+upper)
+			}
+		case strings.HasPrefix(clause, "==="):
+			parts = append(parts, "="+clause[3:])
+		case strings.HasPrefix(clause, "=="):
+			if strings.HasSuffix(clause, ".*") {
+				parts = append(parts, clause[2:])
+			} else {
+				parts = append(parts, "="+clause[2:])
+			}
+		default:
+			parts = append(parts, clause)
+		}
+	}
+	if len(parts) == 0 {
+		return "*"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// nextRelease drops the last part of a version and increments the part before it, 1.4.2 becomes 1.5
+func nextRelease(version string) (string, bool) {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 {
+		return "", false
+	}
+	n, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil {
+		return "", false
+	}
+	parts = parts[:len(parts)-1]
+	parts[len(parts)-1] = strconv.Itoa(n + 1)
+	return strings.Join(parts, "."), true
+}
+
+// fetchMaven reads the versions of an artifact from its maven-metadata.xml and the dependencies of every version from
+// its effective POM, with the parents and BOMs requested from the same repository. The repository layout has no
+// publish times, the Last-Modified header of the POM is used instead.
+func (c *Crawler) fetchMaven(ctx context.Context, name string) (g.PackageInfo, error) {
+	group, artifact, ok := strings.Cut(name, ":")
+	if !ok {
+		return g.PackageInfo{}, fmt.Errorf("%s is not a Maven package, expected groupId:artifactId", name)
+	}
+	data, _, err := c.get(ctx, c.options.Registry+"/"+mavenPath(group, artifact)+"/maven-metadata.xml")
+	if err != nil {
+		return g.PackageInfo{}, err
+	}
+	var metadata mavenMetadata
+	if err := xml.Unmarshal(data, &metadata); err != nil {
+		return g.PackageInfo{}, fmt.Errorf("%s has invalid maven-metadata.xml: %w", name, err)
+	}
+	modified := make(map[string]string)
+	resolver := newPOMResolver(func(groupID, artifactID, version string) ([]byte, error) {
+		data, header, err := c.get(ctx, c.options.Registry+"/"+mavenPOMPath(groupID, artifactID, version))
+		if err == nil {
+			if t, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+				modified[groupID+":"+artifactID+":"+version] = t.UTC().Format(time.RFC3339)
+			}
+		}
+		return data, err
+	})
+	result := g.PackageInfo{Name: name, Versions: make(map[string]g.VersionInfo, len(metadata.Versions))}
+	for _, version := range metadata.Versions {
+		project, err := resolver.resolve(group, artifact, version)
+		if errors.Is(err, errNotFound) {
+			continue
+		}
+		if err != nil {
+			return g.PackageInfo{}, fmt.Errorf("%s %s: %w", name, version, err)
+		}
+		result.Versions[version] = project.versionInfo(modified[name+":"+version])
+	}
+	return result, nil
+}
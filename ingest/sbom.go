@@ -0,0 +1,82 @@
+package ingest
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type cycloneDXComponent struct {
+	Group      string               `json:"group"`
+	Name       string               `json:"name"`
+	Version    string               `json:"version"`
+	Purl       string               `json:"purl"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXDocument struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type spdxPackage struct {
+	Name         string `json:"name"`
+	VersionInfo  string `json:"versionInfo"`
+	ExternalRefs []struct {
+		ReferenceType    string `json:"referenceType"`
+		ReferenceLocator string `json:"referenceLocator"`
+	} `json:"externalRefs"`
+}
+
+type spdxDocument struct {
+	Packages []spdxPackage `json:"packages"`
+}
+
+func parseCycloneDX(data []byte) ([]Component, error) {
+	var document cycloneDXDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	var result []Component
+	var collect func(components []cycloneDXComponent)
+	collect = func(components []cycloneDXComponent) {
+		for _, c := range components {
+			component := Component{Name: c.Name, Version: c.Version, Purl: c.Purl}
+			if name, ok := nameFromPurl(c.Purl); ok {
+				component.Name = name
+			} else if strings.HasPrefix(c.Group, "@") {
+				// An npm scope, some tools already put it in the name as well
+				if !strings.HasPrefix(c.Name, c.Group+"/") {
+					component.Name = c.Group + "/" + c.Name
+				}
+			} else if c.Group != "" {
+				component.Name = c.Group + ":" + c.Name
+			}
+			result = append(result, component)
+			// CycloneDX allows components to be nested inside other components
+			collect(c.Components)
+		}
+	}
+	collect(document.Components)
+	return result, nil
+}
+
+func parseSPDX(data []byte) ([]Component, error) {
+	var document spdxDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	result := make([]Component, 0, len(document.Packages))
+	for _, p := range document.Packages {
+		component := Component{Name: p.Name, Version: p.VersionInfo}
+		for _, ref := range p.ExternalRefs {
+			if ref.ReferenceType != "purl" {
+				continue
+			}
+			component.Purl = ref.ReferenceLocator
+			if name, ok := nameFromPurl(ref.ReferenceLocator); ok {
+				component.Name = name
+			}
+		}
+		result = append(result, component)
+	}
+	return result, nil
+}
@@ -0,0 +1,142 @@
+package ingest
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/klauspost/compress/zstd"
+)
+
+// inputReader is a decompressed input file. Closing it closes the decompressor and the file.
+type inputReader struct {
+	io.Reader
+	closers []func() error
+}
+
+func (r *inputReader) Close() error {
+	var err error
+	for _, closeFunc := range r.closers {
+		if closeErr := closeFunc(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// OpenInput opens an input file. Files whose name ends in .gz or .zst are decompressed while they are read.
+func OpenInput(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz":
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s is not gzipped: %w", path, err)
+		}
+		return &inputReader{Reader: zr, closers: []func() error{zr.Close, file.Close}}, nil
+	case ".zst":
+		// A single decoder goroutine with little memory, the input is decoded as fast as it is parsed anyway
+		zr, err := zstd.NewReader(file, zstd.WithDecoderConcurrency(1), zstd.WithDecoderLowmem(true))
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s is not compressed with zstd: %w", path, err)
+		}
+		return &inputReader{Reader: zr, closers: []func() error{func() error { zr.Close(); return nil }, file.Close}}, nil
+	}
+	return file, nil
+}
+
+// inputFormat returns the extension that tells the format of an input file, packages.csv.gz is a CSV file
+func inputFormat(path string) string {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".gz", ".zst":
+		return strings.ToLower(filepath.Ext(strings.TrimSuffix(path, filepath.Ext(path))))
+	default:
+		return ext
+	}
+}
+
+// StreamPackages calls handle for every package in an input, one at a time, and stops at the first error. JSON files
+// can hold an array of packages, the format ParseJSON reads, or one package after the other like NDJSON, both are
+// decoded one package at a time so only the current package is in memory. CSV files are read with
+// StreamDependenciesCSV, so their rows have to be sorted by name. Directories can't be streamed, a Maven repository
+// needs the parent POMs of every package and the other loaders aren't incremental either.
+func StreamPackages(path string, handle func(g.PackageInfo) error) error {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a directory, only JSON and CSV files can be streamed", path)
+	}
+	if inputFormat(path) == ".csv" {
+		return StreamDependenciesCSV(path, handle)
+	}
+
+	input, err := OpenInput(path)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	if err := decodePackages(input, handle); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// decodePackages decodes a JSON array of packages or a sequence of packages. The first character that is not white
+// space tells which one it is.
+func decodePackages(r io.Reader, handle func(g.PackageInfo) error) error {
+	buffered := bufio.NewReaderSize(r, 1<<20)
+	isArray := false
+	for {
+		b, err := buffered.ReadByte()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if b == ' ' || b == '\t' || b == '\r' || b == '\n' {
+			continue
+		}
+		isArray = b == '['
+		_ = buffered.UnreadByte()
+		break
+	}
+
+	decoder := json.NewDecoder(buffered)
+	if isArray {
+		// Read the opening bracket
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+	}
+	for n := 1; ; n++ {
+		if isArray && !decoder.More() {
+			break
+		}
+		var packageInfo g.PackageInfo
+		err := decoder.Decode(&packageInfo)
+		if !isArray && errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("package %d: %w", n, err)
+		}
+		if err := handle(packageInfo); err != nil {
+			return err
+		}
+	}
+	if token, err := decoder.Token(); err != nil || token != json.Delim(']') {
+		return errors.New("the array of packages is not closed")
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package ingest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestStreamPackages(t *testing.T) {
+	array := `[
+		{"name": "A", "versions": {"1.0.0": {"timestamp": "2020-01-01T00:00:00", "dependencies": {}}}},
+		{"name": "B", "versions": {"1.0.0": {"timestamp": "2021-01-01T00:00:00", "dependencies": {"A": "^1.0.0"}, "yanked": true}}}
+	]`
+	ndjson := `{"name": "A", "versions": {"1.0.0": {"timestamp": "2020-01-01T00:00:00", "dependencies": {}}}}
+{"name": "B", "versions": {"1.0.0": {"timestamp": "2021-01-01T00:00:00", "dependencies": {"A": "^1.0.0"}, "yanked": true}}}
+`
+	gzipped := func(data string) []byte {
+		var buffer bytes.Buffer
+		zw := gzip.NewWriter(&buffer)
+		_, _ = zw.Write([]byte(data))
+		_ = zw.Close()
+		return buffer.Bytes()
+	}
+	zstandard := func(data string) []byte {
+		zw, _ := zstd.NewWriter(nil)
+		defer zw.Close()
+		return zw.EncodeAll([]byte(data), nil)
+	}
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"packages.json":       []byte(array),
+		"packages.ndjson":     []byte(ndjson),
+		"packages.json.gz":    gzipped(array),
+		"packages.ndjson.zst": zstandard(ndjson),
+		"dependencies.csv.gz": gzipped("name,version,upload_time,dependency,dependency_version\nA,1.0.0,2020-01-01,,\nB,1.0.0,2021-01-01,A,^1.0.0\n"),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		var b g.PackageInfo
+		err := StreamPackages(path, func(packageInfo g.PackageInfo) error {
+			names = append(names, packageInfo.Name)
+			if packageInfo.Name == "B" {
+				b = packageInfo
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("Expected no error for %s, got %v", name, err)
+			continue
+		}
+		if len(names) != 2 || b.Versions["1.0.0"].Dependencies["A"] != "^1.0.0" {
+			t.Errorf("Expected A and B with its dependency from %s, got %v and %+v", name, names, b)
+		}
+		if inputFormat(name) != ".csv" && !b.Versions["1.0.0"].Yanked {
+			t.Errorf("Expected B to be yanked in %s", name)
+		}
+	}
+
+	t.Run("Reports invalid input", func(t *testing.T) {
+		for name, data := range map[string]string{
+			"unclosed.json":  `[{"name": "A", "versions": {}}`,
+			"invalid.ndjson": `{"name": "A"}` + "\n" + `{"name": `,
+			"broken.json.gz": "not gzipped",
+		} {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := StreamPackages(path, func(g.PackageInfo) error { return nil }); err == nil {
+				t.Errorf("Expected an error for %s", name)
+			}
+		}
+	})
+
+	t.Run("Streams only CSV files sorted by name and no directories", func(t *testing.T) {
+		path := filepath.Join(dir, "unsorted.csv")
+		data := "name,version,upload_time,dependency,dependency_version\nA,1.0.0,2020-01-01,,\nB,1.0.0,2021-01-01,A,^1.0.0\nA,2.0.0,2022-01-01,,\n"
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := StreamPackages(path, func(g.PackageInfo) error { return nil }); err == nil {
+			t.Error("Expected an error for the rows of A that are not next to each other")
+		}
+		if packages, err := ParseDependenciesCSV(path); err != nil || len((*packages)[0].Versions) != 2 {
+			t.Errorf("Expected ParseDependenciesCSV to read both versions of A, got %v (%v)", packages, err)
+		}
+		if err := StreamPackages(dir, func(g.PackageInfo) error { return nil }); err == nil {
+			t.Error("Expected an error for a directory")
+		}
+	})
+
+	t.Run("Loads the packages of compressed input", func(t *testing.T) {
+		packages, _, err := LoadPackages(filepath.Join(dir, "packages.ndjson.zst"))
+		if err != nil || len(*packages) != 2 {
+			t.Errorf("Expected A and B, got %v (%v)", packages, err)
+		}
+	})
+}
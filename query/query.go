@@ -0,0 +1,221 @@
+// Package query parses the small query language of the interactive mode, for example
+//
+//	deps lodash@4.17.21 depth 3 between 2020-01-01 and 2021-01-01 where kind=runtime | export json deps.json
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Commands lists every command of the language together with a description of its arguments
+var Commands = []struct {
+	Name  string
+	Usage string
+}{
+	{"deps", "deps <name@version|purl> [depth N] [between DATE and DATE] [where kind=KIND[,KIND]]"},
+	{"rdeps", "rdeps <name@version|purl> [depth N] [between DATE and DATE] [where kind=KIND[,KIND]]"},
+	{"why", "why <name@version|purl> <name@version|purl>"},
+	{"app", "app <SBOM or lockfile> [between DATE and DATE]"},
+	{"filter", "filter between DATE and DATE"},
+	{"rank", "rank [limit N] [between DATE and DATE]"},
+	{"find", "find <query> [limit N]"},
+	{"versions", "versions <name> [by date|semver]"},
+	{"stats", "stats [between DATE and DATE]"},
+	{"granularity", "granularity version|package"},
+	{"history", "history"},
+	{"help", "help"},
+	{"quit", "quit"},
+}
+
+// Query is a parsed line of the query language
+type Query struct {
+	Command string
+	Args    []string
+	// Depth limits the depth of the dependency tree, zero means unlimited
+	Depth int
+	// Limit limits the number of results, zero means the default of the command
+	Limit int
+	// When Interval is set the query is restricted to the time interval [BeginTime, EndTime]
+	Interval  bool
+	BeginTime time.Time
+	EndTime   time.Time
+	// Kinds restricts the kinds of dependencies that are followed, none means all of them
+	Kinds []string
+	// Order is the order in which versions are listed, date or semver. Empty means the default of the command.
+	Order string
+	// Export is set when the results are piped into export
+	Export *Export
+}
+
+// Export describes where the results of a query are exported to. An empty File means standard output.
+type Export struct {
+	Format string
+	File   string
+}
+
+// arguments is the number of positional arguments every command takes
+var arguments = map[string]int{
+	"deps":        1,
+	"rdeps":       1,
+	"why":         2,
+	"app":         1,
+	"filter":      0,
+	"rank":        0,
+	"find":        1,
+	"versions":    1,
+	"stats":       0,
+	"granularity": 1,
+	"history":     0,
+	"help":        0,
+	"quit":        0,
+}
+
+// Parse parses a single line of the query language
+func Parse(line string) (*Query, error) {
+	tokens, err := tokenize(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty query")
+	}
+
+	q := &Query{Command: strings.ToLower(tokens[0])}
+	if q.Command == "exit" {
+		q.Command = "quit"
+	}
+	count, ok := arguments[q.Command]
+	if !ok {
+		return nil, fmt.Errorf("unknown command %q, type help to see the commands", tokens[0])
+	}
+	tokens = tokens[1:]
+	for len(q.Args) < count {
+		if len(tokens) == 0 || isKeyword(tokens[0]) {
+			return nil, fmt.Errorf("%s expects %d argument(s)", q.Command, count)
+		}
+		q.Args = append(q.Args, tokens[0])
+		tokens = tokens[1:]
+	}
+
+	for len(tokens) > 0 {
+		keyword := strings.ToLower(tokens[0])
+		switch keyword {
+		case "depth", "limit":
+			if len(tokens) < 2 {
+				return nil, fmt.Errorf("%s expects a number", keyword)
+			}
+			n, err := strconv.Atoi(tokens[1])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%s expects a non-negative number, got %q", keyword, tokens[1])
+			}
+			if keyword == "depth" {
+				q.Depth = n
+			} else {
+				q.Limit = n
+			}
+			tokens = tokens[2:]
+		case "between":
+			if len(tokens) < 4 || strings.ToLower(tokens[2]) != "and" {
+				return nil, errors.New("between expects: between DATE and DATE")
+			}
+			if q.BeginTime, err = ParseDate(tokens[1]); err != nil {
+				return nil, err
+			}
+			if q.EndTime, err = ParseDate(tokens[3]); err != nil {
+				return nil, err
+			}
+			q.Interval = true
+			tokens = tokens[4:]
+		case "by":
+			if len(tokens) < 2 {
+				return nil, errors.New("by expects: by date|semver")
+			}
+			q.Order = strings.ToLower(tokens[1])
+			if q.Order != "date" && q.Order != "semver" {
+				return nil, fmt.Errorf("by expects date or semver, got %q", tokens[1])
+			}
+			tokens = tokens[2:]
+		case "where":
+			if len(tokens) < 2 || !strings.HasPrefix(strings.ToLower(tokens[1]), "kind=") {
+				return nil, errors.New("where expects: where kind=KIND[,KIND]")
+			}
+			for _, kind := range strings.Split(tokens[1][len("kind="):], ",") {
+				if kind != "" {
+					q.Kinds = append(q.Kinds, strings.ToLower(kind))
+				}
+			}
+			tokens = tokens[2:]
+		case "|":
+			if len(tokens) < 3 || strings.ToLower(tokens[1]) != "export" || len(tokens) > 4 {
+				return nil, errors.New("the results can only be piped into: export FORMAT [FILE]")
+			}
+			q.Export = &Export{Format: strings.ToLower(tokens[2])}
+			if len(tokens) == 4 {
+				q.Export.File = tokens[3]
+			}
+			tokens = nil
+		default:
+			return nil, fmt.Errorf("unexpected %q", tokens[0])
+		}
+	}
+	return q, nil
+}
+
+func isKeyword(token string) bool {
+	switch strings.ToLower(token) {
+	case "depth", "limit", "between", "where", "by", "|":
+		return true
+	}
+	return false
+}
+
+// ParseDate accepts both the ISO format and the DD-MM-YYYY format the interactive prompts use
+func ParseDate(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "02-01-2006"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date in the format YYYY-MM-DD or DD-MM-YYYY", value)
+}
+
+// tokenize splits a line on whitespace. Double quotes group words and the pipe is always a token of its own.
+func tokenize(line string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inToken, quoted := false, false
+	flush := func() {
+		if inToken {
+			tokens = append(tokens, current.String())
+			current.Reset()
+			inToken = false
+		}
+	}
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inToken = true
+		case quoted:
+			current.WriteRune(r)
+		case unicode.IsSpace(r):
+			flush()
+		case r == '|':
+			flush()
+			tokens = append(tokens, "|")
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+	flush()
+	return tokens, nil
+}
@@ -0,0 +1,67 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Parses every clause of a dependency query", func(t *testing.T) {
+		q, err := Parse("deps lodash@4.17.21 depth 3 between 2020-01-01 and 01-01-2021 where kind=runtime,dev | export json deps.json")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := &Query{
+			Command:   "deps",
+			Args:      []string{"lodash@4.17.21"},
+			Depth:     3,
+			Interval:  true,
+			BeginTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			Kinds:     []string{"runtime", "dev"},
+			Export:    &Export{Format: "json", File: "deps.json"},
+		}
+		if !reflect.DeepEqual(q, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, q)
+		}
+	})
+
+	t.Run("Parses a pipe without spaces and a quoted argument", func(t *testing.T) {
+		q, err := Parse(`find "my package"|export csv`)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if q.Args[0] != "my package" || q.Export == nil || q.Export.Format != "csv" || q.Export.File != "" {
+			t.Errorf("Expected a find for 'my package' exported as csv, got %+v", q)
+		}
+	})
+
+	t.Run("Parses the order of the versions", func(t *testing.T) {
+		q, err := Parse("versions react by SemVer")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if q.Order != "semver" {
+			t.Errorf("Expected the semver order, got %+v", q)
+		}
+	})
+
+	for _, line := range []string{
+		"",
+		"versions react by size",
+		"install lodash",
+		"deps",
+		"deps depth 3",
+		"why a@1.0.0",
+		"rank limit many",
+		"filter between 2020-01-01 2021-01-01",
+		"deps a@1.0.0 where license=MIT",
+		"deps a@1.0.0 | sort",
+		`find "unterminated`,
+	} {
+		if _, err := Parse(line); err == nil {
+			t.Errorf("Expected an error for %q", line)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/graphql-go/graphql"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// graphQLRequest is the body of a GraphQL request sent with POST
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// schema creates the GraphQL schema over the graph. Packages resolve to their name, versions to their g.NodeInfo and
+// dependencies to their g.Dependency.
+func (s *Server) schema() (graphql.Schema, error) {
+	var packageType, versionType *graphql.Object
+
+	treeArguments := graphql.FieldConfigArgument{
+		"depth": &graphql.ArgumentConfig{
+			Type:         graphql.Int,
+			DefaultValue: 1,
+			Description:  "Number of levels to return, 0 returns the whole tree",
+		},
+		"kinds": &graphql.ArgumentConfig{
+			Type:        graphql.NewList(graphql.NewNonNull(graphql.String)),
+			Description: "Kinds of dependencies to follow (runtime, dev, peer, optional, build), all of them by default",
+		},
+		"from": &graphql.ArgumentConfig{Type: graphql.String, Description: "Beginning of the time interval"},
+		"to":   &graphql.ArgumentConfig{Type: graphql.String, Description: "End of the time interval"},
+	}
+
+	dependencyType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Dependency",
+		Description: "An edge of a dependency tree",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"kind": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(g.Dependency).Kind, nil
+					},
+				},
+				"depth": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "Number of edges between the root of the tree and this version",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(g.Dependency).Depth, nil
+					},
+				},
+				"parent": &graphql.Field{
+					Type:        graphql.NewNonNull(versionType),
+					Description: "The version through which this version was reached",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(g.Dependency).Parent, nil
+					},
+				},
+				"version": &graphql.Field{
+					Type: graphql.NewNonNull(versionType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(g.Dependency).Node, nil
+					},
+				},
+			}
+		}),
+	})
+
+	versionType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Version",
+		Description: "A version of a package, a node of the graph",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "The string id of the version, as accepted by the version query",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						node := p.Source.(g.NodeInfo)
+						return g.StringID(node.Name, node.Version), nil
+					},
+				},
+				"purl": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "The package URL of the version",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(g.NodeInfo).Purl(), nil
+					},
+				},
+				"nodeId": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.Int),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return int(p.Source.(g.NodeInfo).ID()), nil
+					},
+				},
+				"name": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(g.NodeInfo).Name, nil
+					},
+				},
+				"version": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(g.NodeInfo).Version, nil
+					},
+				},
+				"timestamp": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(g.NodeInfo).Timestamp, nil
+					},
+				},
+				"package": &graphql.Field{
+					Type: graphql.NewNonNull(packageType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(g.NodeInfo).Name, nil
+					},
+				},
+				"dependencies": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(dependencyType))),
+					Description: "The dependency tree of this version in breadth first order",
+					Args:        treeArguments,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return s.resolveTree(p, g.GetDependencyTreeContext)
+					},
+				},
+				"dependents": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(dependencyType))),
+					Description: "The versions that (transitively) depend on this version in breadth first order",
+					Args:        treeArguments,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return s.resolveTree(p, g.GetDependentTreeContext)
+					},
+				},
+			}
+		}),
+	})
+
+	packageType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Package",
+		Description: "A package with all its versions",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(string), nil
+				},
+			},
+			"versions": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(versionType))),
+				Description: "The versions of the package, optionally only the ones published in an interval",
+				Args: graphql.FieldConfigArgument{
+					"from": &graphql.ArgumentConfig{Type: graphql.String},
+					"to":   &graphql.ArgumentConfig{Type: graphql.String},
+					"sort": &graphql.ArgumentConfig{
+						Type:         graphql.String,
+						DefaultValue: string(g.ByDate),
+						Description:  "Order of the versions, date (oldest first) or semver (lowest first)",
+					},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return s.resolveVersions(p)
+				},
+			},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"package": &graphql.Field{
+				Type: packageType,
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					name := p.Args["name"].(string)
+					if _, ok := s.nameToVersions[name]; !ok {
+						return nil, nil
+					}
+					return name, nil
+				},
+			},
+			"packages": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(packageType))),
+				Description: "The packages whose name starts with the prefix, ordered by name",
+				Args: graphql.FieldConfigArgument{
+					"prefix": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+					"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+					"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: s.options.DefaultLimit},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					names := s.index.Prefix(p.Args["prefix"].(string))
+					offset, limit := p.Args["offset"].(int), p.Args["limit"].(int)
+					if offset < 0 || limit <= 0 {
+						return nil, errors.New("offset has to be non-negative and limit has to be positive")
+					}
+					if limit > s.options.MaxLimit {
+						limit = s.options.MaxLimit
+					}
+					if offset > len(names) {
+						offset = len(names)
+					}
+					if offset+limit < len(names) {
+						names = names[:offset+limit]
+					}
+					return names[offset:], nil
+				},
+			},
+			"search": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(packageType))),
+				Description: "The packages that match the query best, ignoring case and allowing missing characters",
+				Args: graphql.FieldConfigArgument{
+					"query": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: s.options.DefaultLimit},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					limit := p.Args["limit"].(int)
+					if limit <= 0 {
+						return nil, errors.New("limit has to be positive")
+					}
+					if limit > s.options.MaxLimit {
+						limit = s.options.MaxLimit
+					}
+					return s.index.Search(p.Args["query"].(string), limit), nil
+				},
+			},
+			"version": &graphql.Field{
+				Type: versionType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{
+						Type:        graphql.NewNonNull(graphql.String),
+						Description: "The string id (name@version) or the package URL of the version",
+					},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					node, err := g.LookupNode(s.stringIDToNodeInfo, s.options.Ecosystem, p.Args["id"].(string))
+					if errors.Is(err, g.ErrNodeNotFound) {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, err
+					}
+					return node, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// resolveTree resolves the dependencies and dependents fields of a version with the given tree function
+func (s *Server) resolveTree(p graphql.ResolveParams, tree func(context.Context, *simple.DirectedGraph, map[int64]g.NodeInfo, map[string]g.NodeInfo, string, g.TreeOptions) (*[]g.Dependency, error)) (interface{}, error) {
+	node := p.Source.(g.NodeInfo)
+	options := g.TreeOptions{MaxDepth: p.Args["depth"].(int)}
+	if kinds, ok := p.Args["kinds"].([]interface{}); ok {
+		for _, kind := range kinds {
+			options.Kinds = append(options.Kinds, kind.(string))
+		}
+	}
+	beginTime, endTime, filter, err := graphQLInterval(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	options.Interval, options.BeginTime, options.EndTime = filter, beginTime, endTime
+	dependencies, err := tree(p.Context, s.graph, s.idToNodeInfo, s.stringIDToNodeInfo, g.StringID(node.Name, node.Version), options)
+	if err != nil {
+		return nil, err
+	}
+	return *dependencies, nil
+}
+
+// resolveVersions resolves the versions field of a package
+func (s *Server) resolveVersions(p graphql.ResolveParams) (interface{}, error) {
+	beginTime, endTime, filter, err := graphQLInterval(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	order, err := g.ParseVersionOrder(p.Args["sort"].(string))
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]g.NodeInfo, 0)
+	for _, node := range s.index.Versions(p.Source.(string), order) {
+		if filter {
+			if t, err := g.ParseTimestamp(node.Timestamp); err != nil || !g.InInterval(t, beginTime, endTime) {
+				continue
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// graphQLInterval reads the optional from and to arguments of a field
+func graphQLInterval(args map[string]interface{}) (time.Time, time.Time, bool, error) {
+	from, _ := args["from"].(string)
+	to, _ := args["to"].(string)
+	return parseInterval(from, to)
+}
+
+// graphQLHandler answers GraphQL queries sent as a JSON body with POST or as the query parameter with GET
+func (s *Server) graphQLHandler() http.Handler {
+	schema, err := s.schema()
+	if err != nil {
+		// The schema is fixed, so this can only happen when it was defined incorrectly
+		panic(err)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request graphQLRequest
+		switch r.Method {
+		case http.MethodGet:
+			request.Query = r.URL.Query().Get("query")
+			request.OperationName = r.URL.Query().Get("operationName")
+			if variables := r.URL.Query().Get("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+					writeError(w, http.StatusBadRequest, errors.New("variables is not a JSON object"))
+					return
+				}
+			}
+		case http.MethodPost:
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				writeError(w, http.StatusBadRequest, errors.New("the body is not a GraphQL request"))
+				return
+			}
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errors.New("only GET and POST are supported"))
+			return
+		}
+
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  request.Query,
+			VariableValues: request.Variables,
+			OperationName:  request.OperationName,
+			Context:        r.Context(),
+		})
+		writeJSON(w, http.StatusOK, result)
+	})
+}
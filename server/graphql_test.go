@@ -0,0 +1,100 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func postGraphQL(t *testing.T, url string, query string) map[string]interface{} {
+	t.Helper()
+	body, _ := json.Marshal(graphQLRequest{Query: query})
+	response, err := http.Post(url+"/graphql", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	var result map[string]interface{}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if result["errors"] != nil {
+		t.Fatalf("Expected no errors, got %v", result["errors"])
+	}
+	return result["data"].(map[string]interface{})
+}
+
+func TestGraphQLDependencies(t *testing.T) {
+	s := createTestServer()
+	defer s.Close()
+
+	t.Run("Returns the direct dependencies by default", func(t *testing.T) {
+		data := postGraphQL(t, s.URL, `{ version(id: "C@1.0.0") { name dependencies { kind depth version { id } } } }`)
+		dependencies := data["version"].(map[string]interface{})["dependencies"].([]interface{})
+		if len(dependencies) != 1 {
+			t.Fatalf("Expected 1 dependency, got %v", dependencies)
+		}
+		dependency := dependencies[0].(map[string]interface{})
+		if dependency["kind"] != "runtime" || dependency["version"].(map[string]interface{})["id"] != "B@1.0.0" {
+			t.Errorf("Expected a runtime dependency on B@1.0.0, got %v", dependency)
+		}
+	})
+
+	t.Run("Returns the whole tree with depth 0", func(t *testing.T) {
+		data := postGraphQL(t, s.URL, `{ version(id: "C@1.0.0") { dependencies(depth: 0) { depth parent { id } version { id } } } }`)
+		dependencies := data["version"].(map[string]interface{})["dependencies"].([]interface{})
+		if len(dependencies) != 2 {
+			t.Fatalf("Expected 2 dependencies, got %v", dependencies)
+		}
+		deepest := dependencies[1].(map[string]interface{})
+		if deepest["depth"].(float64) != 2 || deepest["parent"].(map[string]interface{})["id"] != "B@1.0.0" {
+			t.Errorf("Expected A@1.0.0 at depth 2 through B@1.0.0, got %v", deepest)
+		}
+	})
+
+	t.Run("Skips the kinds that were not asked for", func(t *testing.T) {
+		data := postGraphQL(t, s.URL, `{ version(id: "C@1.0.0") { dependencies(kinds: ["dev"]) { kind } } }`)
+		if dependencies := data["version"].(map[string]interface{})["dependencies"].([]interface{}); len(dependencies) != 0 {
+			t.Errorf("Expected no dev dependencies, got %v", dependencies)
+		}
+	})
+}
+
+func TestGraphQLPackages(t *testing.T) {
+	s := createTestServer()
+	defer s.Close()
+
+	data := postGraphQL(t, s.URL, `{ packages(prefix: "A") { name versions { version dependents(depth: 0) { version { id } } } } }`)
+	packages := data["packages"].([]interface{})
+	if len(packages) != 1 {
+		t.Fatalf("Expected 1 package, got %v", packages)
+	}
+	versions := packages[0].(map[string]interface{})["versions"].([]interface{})
+	dependents := versions[0].(map[string]interface{})["dependents"].([]interface{})
+	if len(dependents) != 2 {
+		t.Errorf("Expected A@1.0.0 to have 2 dependents, got %v", dependents)
+	}
+}
+
+func TestGraphQLSearch(t *testing.T) {
+	s := createTestServer()
+	defer s.Close()
+
+	data := postGraphQL(t, s.URL, `{ search(query: "b", limit: 5) { name versions(sort: "semver") { id } } }`)
+	packages := data["search"].([]interface{})
+	if len(packages) != 1 || packages[0].(map[string]interface{})["name"] != "B" {
+		t.Errorf("Expected only package B, got %v", packages)
+	}
+}
+
+func TestGraphQLPurl(t *testing.T) {
+	s := createTestServer()
+	defer s.Close()
+
+	data := postGraphQL(t, s.URL, `{ version(id: "pkg:npm/C@1.0.0") { id purl } }`)
+	version := data["version"].(map[string]interface{})
+	if version["id"] != "C@1.0.0" || version["purl"] != "pkg:npm/C@1.0.0" {
+		t.Errorf("Expected C@1.0.0 with its package URL, got %v", version)
+	}
+}
@@ -0,0 +1,357 @@
+// Package server exposes the queries on a loaded graph over HTTP, so several tools can share one in-memory graph.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/AJMBrands/SoftwareThatMatters/export"
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Options configures the pagination and timeouts of the server
+type Options struct {
+	// DefaultLimit is the page size when a request doesn't specify one, MaxLimit is the largest page size allowed
+	DefaultLimit int
+	MaxLimit     int
+	// Timeout is the time a single request may take before it is answered with 503 Service Unavailable
+	Timeout time.Duration
+	// UI enables the web front-end on /
+	UI bool
+	// Ecosystem is the ecosystem of the packages in the graph, package URLs of other ecosystems are not found
+	Ecosystem g.Ecosystem
+}
+
+// DefaultOptions are the options used by the serve command unless they are overridden with flags
+var DefaultOptions = Options{
+	DefaultLimit: 100,
+	MaxLimit:     1000,
+	Timeout:      30 * time.Second,
+	UI:           true,
+	Ecosystem:    g.NPM,
+}
+
+// Server answers queries on a graph that is loaded once. The graph is only read, so requests can run concurrently.
+type Server struct {
+	graph              *simple.DirectedGraph
+	stringIDToNodeInfo map[string]g.NodeInfo
+	idToNodeInfo       map[int64]g.NodeInfo
+	nameToVersions     map[string][]string
+	options            Options
+
+	// index answers the package searches, its names are sorted so package listings are stable between requests
+	index *g.SearchIndex
+
+	// The ranking only depends on the graph, so it is computed on the first request and reused afterwards
+	rankOnce sync.Once
+	ranking  []g.RankedNode
+}
+
+// page is the envelope of every paginated response
+type page struct {
+	Total  int         `json:"total"`
+	Offset int         `json:"offset"`
+	Limit  int         `json:"limit"`
+	Items  interface{} `json:"items"`
+}
+
+// NewServer creates a server for the graph and the maps g.CreateGraph returned alongside it
+func NewServer(graph *simple.DirectedGraph, stringIDToNodeInfo map[string]g.NodeInfo, idToNodeInfo map[int64]g.NodeInfo, nameToVersions map[string][]string, options Options) *Server {
+	return &Server{
+		graph:              graph,
+		stringIDToNodeInfo: stringIDToNodeInfo,
+		idToNodeInfo:       idToNodeInfo,
+		nameToVersions:     nameToVersions,
+		options:            options,
+		index:              g.NewSearchIndex(stringIDToNodeInfo, nameToVersions),
+	}
+}
+
+// Handler returns the HTTP handler with every endpoint of the API. Requests that take longer than the configured
+// timeout are answered with an error, and the traversals they started stop since the context of the request is
+// cancelled.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/packages", s.handlePackages)
+	mux.HandleFunc("/api/search", s.handleSearch)
+	mux.HandleFunc("/api/versions", s.handleVersions)
+	mux.HandleFunc("/api/node", s.handleNode)
+	mux.HandleFunc("/api/dependencies", s.handleDependencies)
+	mux.HandleFunc("/api/dependents", s.handleDependents)
+	mux.HandleFunc("/api/filter", s.handleFilter)
+	mux.HandleFunc("/api/path", s.handlePath)
+	mux.HandleFunc("/api/rank", s.handleRank)
+	mux.HandleFunc("/api/stats", s.handleStats)
+	mux.Handle("/graphql", s.graphQLHandler())
+	if s.options.UI {
+		mux.Handle("/", uiHandler())
+	}
+	return http.TimeoutHandler(mux, s.options.Timeout, `{"error":"the request timed out"}`)
+}
+
+// ListenAndServe serves the API on the given address until the server fails
+func (s *Server) ListenAndServe(addr string) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		// The write timeout has to leave the timeout handler the time to send its response
+		WriteTimeout: s.options.Timeout + 5*time.Second,
+	}
+	return httpServer.ListenAndServe()
+}
+
+// handlePackages lists the package names, optionally only the ones that start with the query parameter q
+func (s *Server) handlePackages(w http.ResponseWriter, r *http.Request) {
+	offset, limit, err := s.pagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	names := s.index.Prefix(r.URL.Query().Get("q"))
+	writePage(w, len(names), offset, limit, func(i int) interface{} { return names[i] })
+}
+
+// handleSearch returns the package names that match the query parameter q best, best match first. The limit
+// parameter is the number of names returned.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	_, limit, err := s.pagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	names := s.index.Search(r.URL.Query().Get("q"), limit)
+	writePage(w, len(names), 0, limit, func(i int) interface{} { return names[i] })
+}
+
+// handleVersions lists every version of the package given by the name parameter. The sort parameter selects the
+// order, date (oldest first, the default) or semver.
+func (s *Server) handleVersions(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	order, err := g.ParseVersionOrder(r.URL.Query().Get("sort"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	nodes := s.index.Versions(name, order)
+	if nodes == nil {
+		writeError(w, http.StatusNotFound, errors.New("package "+name+" was not found"))
+		return
+	}
+	s.writeNodes(w, r, nodes)
+}
+
+// handleNode returns the node with the string id given by the id parameter
+func (s *Server) handleNode(w http.ResponseWriter, r *http.Request) {
+	node, ok := s.node(w, r, "id")
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, export.NodeRecord(node))
+}
+
+// handleDependencies returns the transitive dependencies of the node given by the id parameter. When from and to
+// are given, only the dependencies that could have been used in that interval are returned.
+func (s *Server) handleDependencies(w http.ResponseWriter, r *http.Request) {
+	node, ok := s.node(w, r, "id")
+	if !ok {
+		return
+	}
+	beginTime, endTime, filter, err := interval(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	stringID := g.StringID(node.Name, node.Version)
+	var nodes *[]g.NodeInfo
+	if filter {
+		nodes, err = g.GetTransitiveDependenciesNodeInIntervalContext(r.Context(), s.graph, s.idToNodeInfo, s.stringIDToNodeInfo, stringID, beginTime, endTime)
+	} else {
+		nodes, err = g.GetTransitiveDependenciesNodeContext(r.Context(), s.graph, s.idToNodeInfo, s.stringIDToNodeInfo, stringID)
+	}
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	g.SortNodes(*nodes)
+	s.writeNodes(w, r, *nodes)
+}
+
+// handleDependents returns the transitive dependents of the node given by the id parameter. When from and to are
+// given, only the dependents published in that interval are returned.
+func (s *Server) handleDependents(w http.ResponseWriter, r *http.Request) {
+	node, ok := s.node(w, r, "id")
+	if !ok {
+		return
+	}
+	beginTime, endTime, filter, err := interval(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	stringID := g.StringID(node.Name, node.Version)
+	var nodes *[]g.NodeInfo
+	if filter {
+		nodes, err = g.GetTransitiveDependentsNodeInIntervalContext(r.Context(), s.graph, s.idToNodeInfo, s.stringIDToNodeInfo, stringID, beginTime, endTime)
+	} else {
+		nodes, err = g.GetTransitiveDependentsNodeContext(r.Context(), s.graph, s.idToNodeInfo, s.stringIDToNodeInfo, stringID)
+	}
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	g.SortNodes(*nodes)
+	s.writeNodes(w, r, *nodes)
+}
+
+// handleFilter returns the nodes published between the from and to parameters
+func (s *Server) handleFilter(w http.ResponseWriter, r *http.Request) {
+	beginTime, endTime, filter, err := interval(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !filter {
+		writeError(w, http.StatusBadRequest, errors.New("both from and to have to be set"))
+		return
+	}
+	nodes := *g.GetNodesInInterval(s.idToNodeInfo, beginTime, endTime)
+	g.SortNodes(nodes)
+	s.writeNodes(w, r, nodes)
+}
+
+// handlePath returns the shortest chain of dependencies from the source parameter to the target parameter
+func (s *Server) handlePath(w http.ResponseWriter, r *http.Request) {
+	source, ok := s.node(w, r, "source")
+	if !ok {
+		return
+	}
+	target, ok := s.node(w, r, "target")
+	if !ok {
+		return
+	}
+	nodes, err := g.GetDependencyPathContext(r.Context(), s.graph, s.idToNodeInfo, s.stringIDToNodeInfo, g.StringID(source.Name, source.Version), g.StringID(target.Name, target.Version))
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, export.PathRecords(*nodes))
+}
+
+// handleRank returns the nodes ordered by their PageRank
+func (s *Server) handleRank(w http.ResponseWriter, r *http.Request) {
+	offset, limit, err := s.pagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.rankOnce.Do(func() {
+		s.ranking = *g.GetMostUsedNodes(s.graph, s.idToNodeInfo, 0)
+	})
+	records := export.RankedNodeRecords(s.ranking)
+	writePage(w, len(records), offset, limit, func(i int) interface{} { return records[i] })
+}
+
+// handleStats returns the statistics of the graph
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, export.StatsRecord(g.ComputeStats(s.graph, s.nameToVersions)))
+}
+
+// node looks up the node whose string id or package URL is in the given query parameter. When it doesn't exist, an
+// error response is written and false is returned.
+func (s *Server) node(w http.ResponseWriter, r *http.Request, parameter string) (g.NodeInfo, bool) {
+	reference := r.URL.Query().Get(parameter)
+	if reference == "" {
+		writeError(w, http.StatusBadRequest, errors.New("the "+parameter+" parameter is required"))
+		return g.NodeInfo{}, false
+	}
+	node, err := g.LookupNode(s.stringIDToNodeInfo, s.options.Ecosystem, reference)
+	if errors.Is(err, g.ErrNodeNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return g.NodeInfo{}, false
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return g.NodeInfo{}, false
+	}
+	return node, true
+}
+
+// writeNodes writes a page of nodes as records
+func (s *Server) writeNodes(w http.ResponseWriter, r *http.Request, nodes []g.NodeInfo) {
+	offset, limit, err := s.pagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writePage(w, len(nodes), offset, limit, func(i int) interface{} { return export.NodeRecord(nodes[i]) })
+}
+
+// pagination reads the offset and limit parameters and applies the defaults and the maximum page size
+func (s *Server) pagination(r *http.Request) (int, int, error) {
+	offset, limit := 0, s.options.DefaultLimit
+	var err error
+	if value := r.URL.Query().Get("offset"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			return 0, 0, errors.New("offset has to be a non-negative number")
+		}
+	}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+			return 0, 0, errors.New("limit has to be a positive number")
+		}
+	}
+	if limit > s.options.MaxLimit {
+		limit = s.options.MaxLimit
+	}
+	return offset, limit, nil
+}
+
+// interval reads the from and to parameters. The boolean is false when neither was given.
+func interval(r *http.Request) (time.Time, time.Time, bool, error) {
+	return parseInterval(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+}
+
+// parseInterval parses the beginning and end of a time interval. The boolean is false when neither was given.
+func parseInterval(from, to string) (time.Time, time.Time, bool, error) {
+	if from == "" && to == "" {
+		return time.Time{}, time.Time{}, false, nil
+	}
+	if from == "" || to == "" {
+		return time.Time{}, time.Time{}, false, errors.New("both from and to have to be set")
+	}
+	beginTime, err := g.ParseTimestamp(from)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, errors.New("from is not a valid date")
+	}
+	endTime, err := g.ParseTimestamp(to)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, errors.New("to is not a valid date")
+	}
+	return beginTime, endTime, true, nil
+}
+
+// writePage writes the items in [offset, offset+limit) of a result with total items. The item function converts
+// the item at an index to what is written, so only the items on the page are converted.
+func writePage(w http.ResponseWriter, total int, offset int, limit int, item func(i int) interface{}) {
+	items := make([]interface{}, 0, limit)
+	for i := offset; i < total && i < offset+limit; i++ {
+		items = append(items, item(i))
+	}
+	writeJSON(w, http.StatusOK, page{Total: total, Offset: offset, Limit: limit, Items: items})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
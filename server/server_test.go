@@ -0,0 +1,166 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+func createTestServer() *httptest.Server {
+	packagesInfo := []g.PackageInfo{
+		{
+			Name: "C",
+			Versions: map[string]g.VersionInfo{
+				"1.0.0": {Timestamp: "2022-01-01T00:00:00", Dependencies: map[string]string{"B": "1.0.0"}},
+			},
+		},
+		{
+			Name: "B",
+			Versions: map[string]g.VersionInfo{
+				"1.0.0": {Timestamp: "2021-01-01T00:00:00", Dependencies: map[string]string{"A": "1.0.0"}},
+			},
+		},
+		{
+			Name: "A",
+			Versions: map[string]g.VersionInfo{
+				"1.0.0": {Timestamp: "2020-01-01T00:00:00", Dependencies: map[string]string{}},
+			},
+		},
+	}
+	graph, _, stringIDToNodeInfo, idToNodeInfo, nameToVersions := g.CreateGraphFromPackages(&packagesInfo, g.NPM)
+	options := DefaultOptions
+	options.MaxLimit = 2
+	return httptest.NewServer(NewServer(graph, stringIDToNodeInfo, idToNodeInfo, nameToVersions, options).Handler())
+}
+
+func getJSON(t *testing.T, url string, expectedStatus int, value interface{}) {
+	t.Helper()
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != expectedStatus {
+		t.Fatalf("Expected status %d for %s, got %d", expectedStatus, url, response.StatusCode)
+	}
+	if err := json.NewDecoder(response.Body).Decode(value); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type testPage struct {
+	Total int                      `json:"total"`
+	Limit int                      `json:"limit"`
+	Items []map[string]interface{} `json:"items"`
+}
+
+func TestDependencies(t *testing.T) {
+	s := createTestServer()
+	defer s.Close()
+
+	t.Run("Returns the node and all its dependencies", func(t *testing.T) {
+		var result testPage
+		getJSON(t, s.URL+"/api/dependencies?id=C@1.0.0", http.StatusOK, &result)
+		if result.Total != 3 {
+			t.Errorf("Expected 3 nodes, got %d", result.Total)
+		}
+	})
+
+	t.Run("Limits the page size", func(t *testing.T) {
+		var result testPage
+		getJSON(t, s.URL+"/api/dependencies?id=C@1.0.0&limit=10", http.StatusOK, &result)
+		if result.Limit != 2 || len(result.Items) != 2 || result.Items[0]["name"] != "A" {
+			t.Errorf("Expected the first two nodes starting with A, got %+v", result)
+		}
+	})
+
+	t.Run("Only returns the dependencies that could be used in the interval", func(t *testing.T) {
+		var result testPage
+		getJSON(t, s.URL+"/api/dependencies?id=B@1.0.0&from=2020-06-01&to=2022-06-01", http.StatusOK, &result)
+		if result.Total != 1 {
+			t.Errorf("Expected only B@1.0.0 itself, got %+v", result)
+		}
+	})
+
+	t.Run("Answers unknown packages with 404", func(t *testing.T) {
+		var result map[string]string
+		getJSON(t, s.URL+"/api/dependencies?id=D@1.0.0", http.StatusNotFound, &result)
+		if result["error"] == "" {
+			t.Error("Expected an error message")
+		}
+	})
+}
+
+func TestDependents(t *testing.T) {
+	s := createTestServer()
+	defer s.Close()
+
+	var result testPage
+	getJSON(t, s.URL+"/api/dependents?id=A@1.0.0", http.StatusOK, &result)
+	if result.Total != 3 {
+		t.Errorf("Expected 3 nodes, got %d", result.Total)
+	}
+}
+
+func TestPath(t *testing.T) {
+	s := createTestServer()
+	defer s.Close()
+
+	var result []map[string]interface{}
+	getJSON(t, s.URL+"/api/path?source=C@1.0.0&target=A@1.0.0", http.StatusOK, &result)
+	if len(result) != 3 || result[0]["name"] != "C" || result[2]["name"] != "A" {
+		t.Errorf("Expected the path C -> B -> A, got %v", result)
+	}
+}
+
+func TestPackages(t *testing.T) {
+	s := createTestServer()
+	defer s.Close()
+
+	var result struct {
+		Total int      `json:"total"`
+		Items []string `json:"items"`
+	}
+	getJSON(t, s.URL+"/api/packages?q=B", http.StatusOK, &result)
+	if result.Total != 1 || result.Items[0] != "B" {
+		t.Errorf("Expected only package B, got %+v", result)
+	}
+
+	getJSON(t, s.URL+"/api/search?q=b", http.StatusOK, &result)
+	if result.Total != 1 || result.Items[0] != "B" {
+		t.Errorf("Expected the search to ignore case and find B, got %+v", result)
+	}
+	getJSON(t, s.URL+"/api/versions?name=B&sort=alphabetical", http.StatusBadRequest, &map[string]string{})
+}
+
+func TestUI(t *testing.T) {
+	s := createTestServer()
+	defer s.Close()
+
+	for _, path := range []string{"/", "/app.js", "/style.css"} {
+		response, err := http.Get(s.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("Expected %s to be served, got status %d", path, response.StatusCode)
+		}
+	}
+}
+
+func TestNodeByPurl(t *testing.T) {
+	s := createTestServer()
+	defer s.Close()
+
+	var result map[string]interface{}
+	getJSON(t, s.URL+"/api/node?id=pkg:npm/B@1.0.0", http.StatusOK, &result)
+	if result["name"] != "B" || result["version"] != "1.0.0" {
+		t.Errorf("Expected B@1.0.0, got %v", result)
+	}
+	getJSON(t, s.URL+"/api/node?id=pkg:npm/B@9.0.0", http.StatusNotFound, &result)
+	getJSON(t, s.URL+"/api/node?id=pkg:pypi/B@1.0.0", http.StatusBadRequest, &result)
+}
@@ -0,0 +1,22 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// The web front-end is a static page that talks to the GraphQL endpoint, so it needs no server side code of its own
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// uiHandler serves the embedded web front-end
+func uiHandler() http.Handler {
+	files, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		// The directory is embedded at compile time, so it always exists
+		panic(err)
+	}
+	return http.FileServer(http.FS(files))
+}
@@ -0,0 +1,295 @@
+"use strict";
+
+// The slider positions are months since the first month below
+const firstMonth = new Date(Date.UTC(1995, 0, 1));
+const monthCount = (new Date().getUTCFullYear() - 1995) * 12 + new Date().getUTCMonth() + 1;
+
+const state = {
+  selected: null,
+  direction: "dependencies",
+};
+
+const $ = (id) => document.getElementById(id);
+
+function monthToDate(month) {
+  const date = new Date(firstMonth);
+  date.setUTCMonth(date.getUTCMonth() + Number(month));
+  return date.toISOString().slice(0, 10);
+}
+
+// interval returns the GraphQL arguments of the time slider, or an empty string when it is not used
+function interval() {
+  if (!$("use-interval").checked) {
+    return "";
+  }
+  return `, from: ${JSON.stringify(monthToDate($("from").value))}, to: ${JSON.stringify(monthToDate($("to").value))}`;
+}
+
+async function graphql(query, variables) {
+  const response = await fetch("/graphql", {
+    method: "POST",
+    headers: {"Content-Type": "application/json"},
+    body: JSON.stringify({query, variables}),
+  });
+  const result = await response.json();
+  if (result.errors) {
+    throw new Error(result.errors.map((e) => e.message).join(", "));
+  }
+  return result.data;
+}
+
+function clear(element) {
+  while (element.firstChild) {
+    element.removeChild(element.firstChild);
+  }
+}
+
+function listItem(text, onClick) {
+  const li = document.createElement("li");
+  li.textContent = text;
+  li.addEventListener("click", () => {
+    for (const sibling of li.parentElement.children) {
+      sibling.classList.remove("active");
+    }
+    li.classList.add("active");
+    onClick();
+  });
+  return li;
+}
+
+// Package search
+
+let searchTimer = null;
+
+function search() {
+  clearTimeout(searchTimer);
+  searchTimer = setTimeout(async () => {
+    const query = $("query").value.trim();
+    const list = $("packages");
+    clear(list);
+    if (query === "") {
+      return;
+    }
+    const data = await graphql("query($query: String!) { search(query: $query, limit: 50) { name } }", {query});
+    for (const p of data.search) {
+      list.appendChild(listItem(p.name, () => showVersions(p.name)));
+    }
+  }, 200);
+}
+
+let shownPackage = null;
+
+async function showVersions(name) {
+  shownPackage = name;
+  const list = $("versions");
+  clear(list);
+  const data = await graphql(
+    "query($name: String!, $sort: String) { package(name: $name) { versions(sort: $sort) { id version timestamp } } }",
+    {name, sort: $("version-order").value});
+  for (const v of data.package.versions) {
+    list.appendChild(listItem(`${v.version} (${v.timestamp.slice(0, 10)})`, () => select(v.id)));
+  }
+}
+
+// Dependency and dependent tree
+
+function select(id) {
+  state.selected = id;
+  $("selected").textContent = id;
+  const tree = $("tree");
+  clear(tree);
+  tree.appendChild(treeNode({id, label: id}, true));
+  drawNeighborhood();
+}
+
+async function children(id) {
+  const field = state.direction;
+  const data = await graphql(
+    `query($id: String!) { version(id: $id) { ${field}(depth: 1${interval()}) { kind version { id } } } }`, {id});
+  return data.version[field].map((d) => ({id: d.version.id, label: d.version.id, kind: d.kind}));
+}
+
+function treeNode(item, expand) {
+  const li = document.createElement("li");
+  const toggle = document.createElement("span");
+  toggle.className = "toggle";
+  toggle.textContent = "+";
+  li.appendChild(toggle);
+  li.appendChild(document.createTextNode(item.label));
+  if (item.kind) {
+    const kind = document.createElement("span");
+    kind.className = "kind";
+    kind.textContent = item.kind;
+    li.appendChild(kind);
+  }
+
+  let sublist = null;
+  const open = async () => {
+    if (sublist) {
+      sublist.remove();
+      sublist = null;
+      toggle.textContent = "+";
+      return;
+    }
+    sublist = document.createElement("ul");
+    li.appendChild(sublist);
+    toggle.textContent = "-";
+    const items = await children(item.id);
+    if (items.length === 0) {
+      toggle.textContent = " ";
+    }
+    for (const child of items) {
+      sublist.appendChild(treeNode(child, false));
+    }
+  };
+  toggle.addEventListener("click", open);
+  if (expand) {
+    open();
+  }
+  return li;
+}
+
+function setDirection(direction) {
+  state.direction = direction;
+  $("show-dependencies").classList.toggle("active", direction === "dependencies");
+  $("show-dependents").classList.toggle("active", direction === "dependents");
+  if (state.selected) {
+    select(state.selected);
+  }
+}
+
+// Force-directed neighborhood view
+
+let animation = null;
+
+async function drawNeighborhood() {
+  if (!state.selected) {
+    return;
+  }
+  const depth = Math.max(1, Math.min(3, Number($("depth").value)));
+  const data = await graphql(
+    `query($id: String!) { version(id: $id) {
+      dependencies(depth: ${depth}${interval()}) { parent { id } version { id } }
+      dependents(depth: ${depth}${interval()}) { parent { id } version { id } }
+    } }`, {id: state.selected});
+
+  const canvas = $("neighborhood");
+  const nodes = new Map();
+  const addNode = (id) => {
+    if (!nodes.has(id)) {
+      nodes.set(id, {id, x: canvas.width / 2 + Math.random() * 100 - 50, y: canvas.height / 2 + Math.random() * 100 - 50, vx: 0, vy: 0});
+    }
+    return nodes.get(id);
+  };
+  addNode(state.selected).root = true;
+  const edges = [];
+  for (const d of data.version.dependencies) {
+    edges.push({from: addNode(d.parent.id), to: addNode(d.version.id)});
+  }
+  // In the dependent tree the version depends on its parent
+  for (const d of data.version.dependents) {
+    edges.push({from: addNode(d.version.id), to: addNode(d.parent.id)});
+  }
+  simulate(canvas, [...nodes.values()], edges);
+}
+
+function simulate(canvas, nodes, edges) {
+  cancelAnimationFrame(animation);
+  const context = canvas.getContext("2d");
+  let steps = 0;
+
+  const step = () => {
+    // Every pair of nodes repels, every edge pulls its endpoints together and everything drifts to the center
+    for (const a of nodes) {
+      for (const b of nodes) {
+        if (a === b) {
+          continue;
+        }
+        const dx = a.x - b.x;
+        const dy = a.y - b.y;
+        const distance = Math.max(Math.sqrt(dx * dx + dy * dy), 1);
+        const force = 800 / (distance * distance);
+        a.vx += force * dx / distance;
+        a.vy += force * dy / distance;
+      }
+      a.vx += (canvas.width / 2 - a.x) * 0.002;
+      a.vy += (canvas.height / 2 - a.y) * 0.002;
+    }
+    for (const e of edges) {
+      const dx = e.to.x - e.from.x;
+      const dy = e.to.y - e.from.y;
+      e.from.vx += dx * 0.01;
+      e.from.vy += dy * 0.01;
+      e.to.vx -= dx * 0.01;
+      e.to.vy -= dy * 0.01;
+    }
+    for (const n of nodes) {
+      n.vx *= 0.8;
+      n.vy *= 0.8;
+      n.x = Math.max(10, Math.min(canvas.width - 10, n.x + n.vx));
+      n.y = Math.max(10, Math.min(canvas.height - 10, n.y + n.vy));
+    }
+    draw(context, canvas, nodes, edges);
+    if (++steps < 300) {
+      animation = requestAnimationFrame(step);
+    }
+  };
+  step();
+}
+
+function draw(context, canvas, nodes, edges) {
+  context.clearRect(0, 0, canvas.width, canvas.height);
+  context.strokeStyle = "#999";
+  for (const e of edges) {
+    const angle = Math.atan2(e.to.y - e.from.y, e.to.x - e.from.x);
+    const x = e.to.x - 6 * Math.cos(angle);
+    const y = e.to.y - 6 * Math.sin(angle);
+    context.beginPath();
+    context.moveTo(e.from.x, e.from.y);
+    context.lineTo(x, y);
+    context.lineTo(x - 6 * Math.cos(angle - 0.4), y - 6 * Math.sin(angle - 0.4));
+    context.moveTo(x, y);
+    context.lineTo(x - 6 * Math.cos(angle + 0.4), y - 6 * Math.sin(angle + 0.4));
+    context.stroke();
+  }
+  context.font = "11px sans-serif";
+  for (const n of nodes) {
+    context.fillStyle = n.root ? "#c0392b" : "#2d3e50";
+    context.beginPath();
+    context.arc(n.x, n.y, 5, 0, 2 * Math.PI);
+    context.fill();
+    context.fillText(n.id, n.x + 7, n.y + 4);
+  }
+}
+
+// Time slider
+
+function updateInterval() {
+  if (Number($("from").value) > Number($("to").value)) {
+    $("to").value = $("from").value;
+  }
+  $("from-label").textContent = monthToDate($("from").value);
+  $("to-label").textContent = monthToDate($("to").value);
+}
+
+function init() {
+  for (const id of ["from", "to"]) {
+    $(id).max = monthCount;
+  }
+  $("from").value = 0;
+  $("to").value = monthCount;
+  updateInterval();
+
+  $("query").addEventListener("input", search);
+  $("version-order").addEventListener("change", () => shownPackage && showVersions(shownPackage));
+  $("show-dependencies").addEventListener("click", () => setDirection("dependencies"));
+  $("show-dependents").addEventListener("click", () => setDirection("dependents"));
+  $("depth").addEventListener("change", drawNeighborhood);
+  for (const id of ["from", "to"]) {
+    $(id).addEventListener("input", updateInterval);
+    $(id).addEventListener("change", () => state.selected && select(state.selected));
+  }
+  $("use-interval").addEventListener("change", () => state.selected && select(state.selected));
+}
+
+init();